@@ -3,9 +3,11 @@ package cliui
 import (
 	"context"
 	"io"
+	"os"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mattn/go-isatty"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/codersdk"
@@ -18,6 +20,12 @@ type AgentOptions struct {
 	Fetch         func(ctx context.Context, agentID uuid.UUID) (codersdk.WorkspaceAgent, error)
 	FetchLogs     func(ctx context.Context, agentID uuid.UUID, after int64, follow bool) (<-chan []codersdk.WorkspaceAgentLog, io.Closer, error)
 	Wait          bool // If true, wait for the agent to be ready (startup script).
+	// ShowElapsed displays how long we've been waiting for the agent to
+	// connect. On a TTY the elapsed time is shown on the waiting line,
+	// otherwise a line is printed every second.
+	ShowElapsed bool
+	// Now returns the current time, defaulting to time.Now.
+	Now func() time.Time
 }
 
 // Agent displays a spinning indicator that waits for a workspace agent to connect.
@@ -28,6 +36,9 @@ func Agent(ctx context.Context, writer io.Writer, agentID uuid.UUID, opts AgentO
 	if opts.FetchInterval == 0 {
 		opts.FetchInterval = 500 * time.Millisecond
 	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
 	if opts.FetchLogs == nil {
 		opts.FetchLogs = func(_ context.Context, _ uuid.UUID, _ int64, _ bool) (<-chan []codersdk.WorkspaceAgentLog, io.Closer, error) {
 			c := make(chan []codersdk.WorkspaceAgentLog)
@@ -81,7 +92,8 @@ func Agent(ctx context.Context, writer io.Writer, agentID uuid.UUID, opts AgentO
 		return xerrors.Errorf("fetch: %w", err)
 	}
 
-	sw := &stageWriter{w: writer}
+	file, ok := writer.(*os.File)
+	sw := &stageWriter{w: writer, tty: ok && isatty.IsTerminal(file.Fd())}
 
 	showStartupLogs := false
 	for {
@@ -99,7 +111,12 @@ func Agent(ctx context.Context, writer io.Writer, agentID uuid.UUID, opts AgentO
 
 			stage := "Waiting for the workspace agent to connect"
 			sw.Start(stage)
+			var elapsed *elapsedWriter
+			if opts.ShowElapsed {
+				elapsed = &elapsedWriter{sw: sw, stage: stage, now: opts.Now, start: opts.Now()}
+			}
 			for agent.Status == codersdk.WorkspaceAgentConnecting {
+				elapsed.Update()
 				if agent, err = fetch(); err != nil {
 					return xerrors.Errorf("fetch: %w", err)
 				}
@@ -110,6 +127,7 @@ func Agent(ctx context.Context, writer io.Writer, agentID uuid.UUID, opts AgentO
 				sw.Log(now, codersdk.LogLevelInfo, "The workspace agent is having trouble connecting, wait for it to connect or restart your workspace.")
 				sw.Log(now, codersdk.LogLevelInfo, troubleshootingMessage(agent, "https://coder.com/docs/v2/latest/templates#agent-connection-issues"))
 				for agent.Status == codersdk.WorkspaceAgentTimeout {
+					elapsed.Update()
 					if agent, err = fetch(); err != nil {
 						return xerrors.Errorf("fetch: %w", err)
 					}
@@ -173,6 +191,12 @@ func Agent(ctx context.Context, writer io.Writer, agentID uuid.UUID, opts AgentO
 							return nil
 						}
 						for _, log := range logs {
+							if log == codersdk.WorkspaceAgentLogGap {
+								// Use zero time (omitted) since the notice is
+								// not a log of the agent.
+								sw.Log(time.Time{}, log.Level, log.Output)
+								continue
+							}
 							sw.Log(log.CreatedAt, log.Level, log.Output)
 							lastLog = log
 						}
@@ -248,3 +272,26 @@ type closeFunc func() error
 func (c closeFunc) Close() error {
 	return c()
 }
+
+// elapsedWriter displays how long we've been waiting since start. Update is
+// a no-op on a nil elapsedWriter so callers don't have to check ShowElapsed.
+type elapsedWriter struct {
+	sw    *stageWriter
+	stage string
+	now   func() time.Time
+	start time.Time
+	last  time.Duration
+}
+
+// Update refreshes the elapsed time if at least another second has passed.
+func (e *elapsedWriter) Update() {
+	if e == nil {
+		return
+	}
+	elapsed := e.now().Sub(e.start).Truncate(time.Second)
+	if elapsed <= e.last {
+		return
+	}
+	e.last = elapsed
+	e.sw.Elapsed(e.stage, elapsed)
+}
@@ -0,0 +1,45 @@
+package cliui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageWriterElapsed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TTY", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		sw := &stageWriter{w: &buf, tty: true}
+		sw.Start("Waiting")
+		sw.Elapsed("Waiting", time.Second)
+		sw.Elapsed("Waiting", 2*time.Second)
+		// The stage line is rewritten in place.
+		require.Equal(t, "==> ⧗ Waiting\n"+
+			"\033[1A\r\033[2K==> ⧗ Waiting (1s)\n"+
+			"\033[1A\r\033[2K==> ⧗ Waiting (2s)\n", buf.String())
+
+		// Once something else is written, the stage line is printed again.
+		buf.Reset()
+		sw.QueuePosition(1, 2)
+		sw.Elapsed("Waiting", 3*time.Second)
+		require.Contains(t, buf.String(), "Queued (1 of 2)\n==> ⧗ Waiting (3s)\n")
+		require.NotContains(t, buf.String(), "\033[1A")
+	})
+
+	t.Run("NoTTY", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		sw := &stageWriter{w: &buf}
+		sw.Start("Waiting")
+		sw.Elapsed("Waiting", time.Second)
+		require.Contains(t, buf.String(), "==> ⧗ Waiting\n")
+		require.Contains(t, buf.String(), "Still waiting (1s)\n")
+	})
+}
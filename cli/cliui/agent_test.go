@@ -158,6 +158,50 @@ func TestAgent(t *testing.T) {
 				"✔ Running workspace agent startup script",
 			},
 		},
+		{
+			name: "Startup script logs with gap",
+			opts: cliui.AgentOptions{
+				FetchInterval: time.Millisecond,
+				Wait:          true,
+			},
+			iter: []func(context.Context, *codersdk.WorkspaceAgent, chan []codersdk.WorkspaceAgentLog) error{
+				func(_ context.Context, agent *codersdk.WorkspaceAgent, logs chan []codersdk.WorkspaceAgentLog) error {
+					agent.Status = codersdk.WorkspaceAgentConnected
+					agent.FirstConnectedAt = ptr.Ref(time.Now())
+					agent.LifecycleState = codersdk.WorkspaceAgentLifecycleStarting
+					agent.StartedAt = ptr.Ref(time.Now())
+					logs <- []codersdk.WorkspaceAgentLog{
+						{
+							ID:        1,
+							CreatedAt: time.Now(),
+							Output:    "Hello world",
+						},
+					}
+					return nil
+				},
+				func(_ context.Context, agent *codersdk.WorkspaceAgent, logs chan []codersdk.WorkspaceAgentLog) error {
+					agent.LifecycleState = codersdk.WorkspaceAgentLifecycleReady
+					agent.ReadyAt = ptr.Ref(time.Now())
+					// The logs after the cursor were truncated.
+					logs <- []codersdk.WorkspaceAgentLog{
+						codersdk.WorkspaceAgentLogGap,
+						{
+							ID:        5,
+							CreatedAt: time.Now(),
+							Output:    "Bye now",
+						},
+					}
+					return nil
+				},
+			},
+			want: []string{
+				"⧗ Running workspace agent startup script",
+				"Hello world",
+				"(earlier logs unavailable)",
+				"Bye now",
+				"✔ Running workspace agent startup script",
+			},
+		},
 		{
 			name: "Startup script exited with error",
 			opts: cliui.AgentOptions{
@@ -385,3 +429,75 @@ func TestAgent(t *testing.T) {
 		require.NoError(t, cmd.Invoke().Run())
 	})
 }
+
+func TestAgentShowElapsed(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	// Every call to Now advances the clock by a second, and the nth fetch
+	// waits for the clock to be read n times, so each fetch step is
+	// observed exactly once.
+	start := time.Now()
+	var ticks atomic.Int64
+	ticked := make(chan struct{}, 16)
+	now := func() time.Time {
+		tick := ticks.Add(1)
+		ticked <- struct{}{}
+		return start.Add(time.Duration(tick) * time.Second)
+	}
+
+	var fetches int
+	fetch := func(ctx context.Context, _ uuid.UUID) (codersdk.WorkspaceAgent, error) {
+		fetches++
+		agent := codersdk.WorkspaceAgent{
+			Status:         codersdk.WorkspaceAgentConnecting,
+			CreatedAt:      start,
+			LifecycleState: codersdk.WorkspaceAgentLifecycleReady,
+		}
+		for fetches > 1 && ticks.Load() < int64(fetches) {
+			select {
+			case <-ctx.Done():
+				return agent, ctx.Err()
+			case <-ticked:
+			}
+		}
+		if fetches == 4 {
+			agent.Status = codersdk.WorkspaceAgentConnected
+			agent.FirstConnectedAt = ptr.Ref(start)
+			agent.StartedAt = ptr.Ref(start)
+			agent.ReadyAt = ptr.Ref(start)
+		}
+		return agent, nil
+	}
+
+	var buf bytes.Buffer
+	err := cliui.Agent(ctx, &buf, uuid.Nil, cliui.AgentOptions{
+		FetchInterval: time.Millisecond,
+		Fetch:         fetch,
+		ShowElapsed:   true,
+		Now:           now,
+	})
+	require.NoError(t, err)
+
+	want := []string{
+		"⧗ Waiting for the workspace agent to connect",
+		"Still waiting (1s)",
+		"Still waiting (2s)",
+		"Still waiting (3s)",
+		"✔ Waiting for the workspace agent to connect",
+	}
+	s := bufio.NewScanner(&buf)
+	for s.Scan() {
+		line := s.Text()
+		t.Log(line)
+		if len(want) == 0 {
+			break
+		}
+		require.Contains(t, line, want[0])
+		want = want[1:]
+	}
+	require.NoError(t, s.Err())
+	require.Empty(t, want)
+}
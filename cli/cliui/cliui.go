@@ -1,10 +1,13 @@
 package cliui
 
 import (
+	"io"
 	"os"
 
+	"github.com/AlecAivazis/survey/v2/core"
 	"github.com/charmbracelet/charm/ui/common"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
 	"github.com/muesli/termenv"
 	"golang.org/x/xerrors"
 )
@@ -66,6 +69,30 @@ func init() {
 	}
 }
 
+// ColorEnabled returns whether output written to w should be rendered with
+// color. Color is disabled if w is not a terminal or NO_COLOR is set.
+func ColorEnabled(w io.Writer) bool {
+	if termenv.NewOutput(w).EnvNoColor() {
+		return false
+	}
+	file, ok := w.(*os.File)
+	return ok && isatty.IsTerminal(file.Fd())
+}
+
+// SetColor enables or disables escape codes in everything rendered by cliui,
+// including DefaultStyles and Select. Passing true forces color on even if
+// ColorEnabled would return false.
+func SetColor(enabled bool) {
+	core.DisableColor = !enabled
+	if !enabled {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		return
+	}
+	if lipgloss.ColorProfile() == termenv.Ascii {
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	}
+}
+
 // ValidateNotEmpty is a helper function to disallow empty inputs!
 func ValidateNotEmpty(s string) error {
 	if s == "" {
@@ -0,0 +1,65 @@
+package cliui_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/pty/ptytest"
+)
+
+// This cannot be ran in parallel because it changes the environment and the
+// global color profile.
+//
+//nolint:paralleltest
+func TestColor(t *testing.T) {
+	ogColorProfile := lipgloss.ColorProfile()
+	ogDisableColor := core.DisableColor
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(ogColorProfile)
+		core.DisableColor = ogDisableColor
+	})
+
+	t.Run("NoColor", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			// The Windows pseudo console emits its own escape codes.
+			t.Skip("escape codes are not reliable on Windows")
+		}
+		t.Setenv("NO_COLOR", "1")
+
+		ptty := ptytest.New(t)
+		// The pty is a terminal, but NO_COLOR takes precedence.
+		require.False(t, cliui.ColorEnabled(ptty.Output().Writer))
+		cliui.SetColor(cliui.ColorEnabled(ptty.Output().Writer))
+
+		require.NotContains(t, cliui.DefaultStyles.Bold.Render("bold"), "\x1b[")
+		require.NotContains(t, cliui.DefaultStyles.Error.Render("error"), "\x1b[")
+
+		msgChan := make(chan string)
+		go func() {
+			resp, err := newPrompt(ptty, cliui.PromptOptions{
+				Text:      "Colorless?",
+				IsConfirm: true,
+			}, nil)
+			assert.NoError(t, err)
+			msgChan <- resp
+		}()
+		out := ptty.ExpectMatchContext(context.Background(), "Colorless?")
+		assert.NotContains(t, out, "\x1b[")
+		ptty.WriteLine("yes")
+		require.Equal(t, "yes", <-msgChan)
+	})
+
+	t.Run("Force", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+
+		cliui.SetColor(true)
+		require.Contains(t, cliui.DefaultStyles.Error.Render("error"), "\x1b[")
+	})
+}
@@ -6,8 +6,6 @@ import (
 	"io"
 	"time"
 
-	"github.com/briandowns/spinner"
-
 	"github.com/coder/coder/codersdk"
 )
 
@@ -25,11 +23,8 @@ func GitAuth(ctx context.Context, writer io.Writer, opts GitAuthOptions) error {
 		return err
 	}
 
-	spin := spinner.New(spinner.CharSets[78], 100*time.Millisecond, spinner.WithColor("fgHiGreen"))
-	spin.Writer = writer
-	spin.ForceOutput = true
-	spin.Suffix = " Waiting for Git authentication..."
-	defer spin.Stop()
+	spin := NewSpinner(writer)
+	defer spin.Stop("")
 
 	ticker := time.NewTicker(opts.FetchInterval)
 	defer ticker.Stop()
@@ -41,7 +36,7 @@ func GitAuth(ctx context.Context, writer io.Writer, opts GitAuthOptions) error {
 		_, _ = fmt.Fprintf(writer, "You must authenticate with %s to create a workspace with this template. Visit:\n\n\t%s\n\n", auth.Type.Pretty(), auth.AuthenticateURL)
 
 		ticker.Reset(opts.FetchInterval)
-		spin.Start()
+		spin.Start("Waiting for Git authentication...")
 		for {
 			select {
 			case <-ctx.Done():
@@ -65,8 +60,7 @@ func GitAuth(ctx context.Context, writer io.Writer, opts GitAuthOptions) error {
 				break
 			}
 		}
-		spin.Stop()
-		_, _ = fmt.Fprintf(writer, "Successfully authenticated with %s!\n\n", auth.Type.Pretty())
+		spin.Stop(fmt.Sprintf("Successfully authenticated with %s!\n", auth.Type.Pretty()))
 	}
 	return nil
 }
@@ -47,6 +47,22 @@ func Warnf(wtr io.Writer, fmtStr string, args ...interface{}) {
 	Warn(wtr, fmt.Sprintf(fmtStr, args...))
 }
 
+// Deprecated writes a warning to the writer provided that the feature is
+// deprecated and will be removed. If replacement is set, users are pointed
+// to it instead.
+func Deprecated(wtr io.Writer, feature, replacement string) {
+	var lines []string
+	if replacement != "" {
+		lines = append(lines, fmt.Sprintf("Use %s instead.", replacement))
+	}
+	_, _ = fmt.Fprint(wtr, cliMessage{
+		Style:  DefaultStyles.Warn.Copy(),
+		Prefix: "DEPRECATED: ",
+		Header: fmt.Sprintf("%s is deprecated and will be removed in a future release.", feature),
+		Lines:  lines,
+	}.String())
+}
+
 // Info writes a log to the writer provided.
 func Info(wtr io.Writer, header string, lines ...string) {
 	_, _ = fmt.Fprint(wtr, cliMessage{
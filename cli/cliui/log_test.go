@@ -0,0 +1,35 @@
+package cliui_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/cliui"
+)
+
+func TestDeprecated(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Replacement", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		cliui.Deprecated(&buf, "coder parameters", "coder templates edit")
+		out := buf.String()
+		require.Contains(t, out, "DEPRECATED: ")
+		require.Contains(t, out, "coder parameters is deprecated")
+		require.Contains(t, out, "Use coder templates edit instead.")
+	})
+
+	t.Run("NoReplacement", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		cliui.Deprecated(&buf, "coder parameters", "")
+		out := buf.String()
+		require.Contains(t, out, "coder parameters is deprecated")
+		require.NotContains(t, out, "instead")
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
@@ -22,7 +23,15 @@ type PromptOptions struct {
 	Default   string
 	Secret    bool
 	IsConfirm bool
+	// Multiline reads input until a line containing only
+	// MultilineSentinel or EOF (Ctrl-D), returning the lines joined by
+	// newlines.
+	Multiline bool
 	Validate  func(string) error
+	// ValidateAsync is run after Validate succeeds for validations that may
+	// take a while, such as network calls. A spinner is shown while it runs
+	// and the value is not accepted until it returns.
+	ValidateAsync func(string) error
 }
 
 const skipPromptFlag = "yes"
@@ -44,6 +53,9 @@ const (
 	ConfirmNo  = "no"
 )
 
+// MultilineSentinel ends input for multiline prompts.
+const MultilineSentinel = "."
+
 // Prompt asks the user for input.
 func Prompt(inv *clibase.Invocation, opts PromptOptions) (string, error) {
 	// If the cmd has a "yes" flag for skipping confirm prompts, honor it.
@@ -71,6 +83,9 @@ func Prompt(inv *clibase.Invocation, opts PromptOptions) (string, error) {
 	} else if opts.Default != "" {
 		_, _ = fmt.Fprint(inv.Stdout, DefaultStyles.Placeholder.Render("("+opts.Default+") "))
 	}
+	if opts.Multiline {
+		_, _ = fmt.Fprintln(inv.Stdout, DefaultStyles.Placeholder.Render(fmt.Sprintf("(end with a line containing only %q or Ctrl-D)", MultilineSentinel)))
+	}
 	interrupt := make(chan os.Signal, 1)
 
 	if inv.Stdin == nil {
@@ -93,13 +108,17 @@ func Prompt(inv *clibase.Invocation, opts PromptOptions) (string, error) {
 			defer signal.Stop(interrupt)
 
 			reader := bufio.NewReader(inv.Stdin)
-			line, err = reader.ReadString('\n')
-
-			// Check if the first line beings with JSON object or array chars.
-			// This enables multiline JSON to be pasted into an input, and have
-			// it parse properly.
-			if err == nil && (strings.HasPrefix(line, "{") || strings.HasPrefix(line, "[")) {
-				line, err = promptJSON(reader, line)
+			if opts.Multiline {
+				line, err = promptMultiline(reader)
+			} else {
+				line, err = reader.ReadString('\n')
+
+				// Check if the first line beings with JSON object or array chars.
+				// This enables multiline JSON to be pasted into an input, and have
+				// it parse properly.
+				if err == nil && (strings.HasPrefix(line, "{") || strings.HasPrefix(line, "[")) {
+					line, err = promptJSON(reader, line)
+				}
 			}
 		}
 		if err != nil {
@@ -130,6 +149,16 @@ func Prompt(inv *clibase.Invocation, opts PromptOptions) (string, error) {
 				return Prompt(inv, opts)
 			}
 		}
+		if opts.ValidateAsync != nil {
+			err := validateAsync(inv, opts.ValidateAsync, line)
+			if ctxErr := inv.Context().Err(); ctxErr != nil {
+				return "", ctxErr
+			}
+			if err != nil {
+				_, _ = fmt.Fprintln(inv.Stdout, DefaultStyles.Error.Render(err.Error()))
+				return Prompt(inv, opts)
+			}
+		}
 		return line, nil
 	case <-inv.Context().Done():
 		return "", inv.Context().Err()
@@ -140,6 +169,48 @@ func Prompt(inv *clibase.Invocation, opts PromptOptions) (string, error) {
 	}
 }
 
+// validateAsync runs validate with a spinner until it returns or the
+// invocation is canceled.
+func validateAsync(inv *clibase.Invocation, validate func(string) error, line string) error {
+	spin := NewSpinner(inv.Stdout)
+	spin.Start(DefaultStyles.Placeholder.Render("Checking..."))
+	defer spin.Stop("")
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- validate(line)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-inv.Context().Done():
+		return inv.Context().Err()
+	}
+}
+
+// promptMultiline reads lines until one contains only MultilineSentinel or
+// the reader hits EOF.
+func promptMultiline(reader *bufio.Reader) (string, error) {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if err != nil && !xerrors.Is(err, io.EOF) {
+			return "", err
+		}
+		if line == MultilineSentinel {
+			break
+		}
+		if line != "" || err == nil {
+			lines = append(lines, line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func promptJSON(reader *bufio.Reader, line string) (string, error) {
 	var data bytes.Buffer
 	for {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
@@ -110,6 +111,25 @@ func TestPrompt(t *testing.T) {
 		require.Equal(t, "{}", <-doneChan)
 	})
 
+	t.Run("Multiline", func(t *testing.T) {
+		t.Parallel()
+		ptty := ptytest.New(t)
+		doneChan := make(chan string)
+		go func() {
+			resp, err := newPrompt(ptty, cliui.PromptOptions{
+				Text:      "Example",
+				Multiline: true,
+			}, nil)
+			assert.NoError(t, err)
+			doneChan <- resp
+		}()
+		ptty.ExpectMatch("Example")
+		ptty.WriteLine("first line")
+		ptty.WriteLine("second line")
+		ptty.WriteLine(cliui.MultilineSentinel)
+		require.Equal(t, "first line\nsecond line", <-doneChan)
+	})
+
 	t.Run("BadJSON", func(t *testing.T) {
 		t.Parallel()
 		ptty := ptytest.New(t)
@@ -143,6 +163,42 @@ func TestPrompt(t *testing.T) {
 }`)
 		require.Equal(t, `{"test":"wow"}`, <-doneChan)
 	})
+
+	t.Run("ValidateAsync", func(t *testing.T) {
+		t.Parallel()
+		ptty := ptytest.New(t)
+		release := make(chan struct{})
+		doneChan := make(chan string)
+		go func() {
+			resp, err := newPrompt(ptty, cliui.PromptOptions{
+				Text: "Name",
+				ValidateAsync: func(s string) error {
+					<-release
+					if s == "taken" {
+						return xerrors.New("name is already taken")
+					}
+					return nil
+				},
+			}, nil)
+			assert.NoError(t, err)
+			doneChan <- resp
+		}()
+		ptty.ExpectMatch("Name")
+		ptty.WriteLine("taken")
+		ptty.ExpectMatch("Checking...")
+		release <- struct{}{}
+		ptty.ExpectMatch("name is already taken")
+		ptty.ExpectMatch("Name")
+		ptty.WriteLine("available")
+		ptty.ExpectMatch("Checking...")
+		select {
+		case <-doneChan:
+			t.Fatal("prompt returned before async validation finished")
+		default:
+		}
+		release <- struct{}{}
+		require.Equal(t, "available", <-doneChan)
+	})
 }
 
 func newPrompt(ptty *ptytest.PTY, opts cliui.PromptOptions, invOpt func(inv *clibase.Invocation)) (string, error) {
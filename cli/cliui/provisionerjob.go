@@ -23,8 +23,8 @@ func WorkspaceBuild(ctx context.Context, writer io.Writer, client *codersdk.Clie
 			build, err := client.WorkspaceBuild(ctx, build)
 			return build.Job, err
 		},
-		Logs: func() (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
-			return client.WorkspaceBuildLogsAfter(ctx, build, 0)
+		Logs: func(after int64) (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
+			return client.WorkspaceBuildLogsAfter(ctx, build, after)
 		},
 	})
 }
@@ -32,8 +32,17 @@ func WorkspaceBuild(ctx context.Context, writer io.Writer, client *codersdk.Clie
 type ProvisionerJobOptions struct {
 	Fetch  func() (codersdk.ProvisionerJob, error)
 	Cancel func() error
-	Logs   func() (<-chan codersdk.ProvisionerJobLog, io.Closer, error)
-
+	// Logs streams the job logs after the given log ID.
+	Logs func(after int64) (<-chan codersdk.ProvisionerJobLog, io.Closer, error)
+	// QueuePosition optionally returns the position of the job in the queue
+	// and the size of the queue. It is polled while the job is pending so the
+	// position can be shown to the user. Errors are ignored since the
+	// position is informational and the job may still complete.
+	QueuePosition func() (position int64, size int64, err error)
+
+	// SinceLogID resumes watching a job after the log with this ID, so logs
+	// that were already printed are not printed again. Zero prints all logs.
+	SinceLogID    int64
 	FetchInterval time.Duration
 	// Verbose determines whether debug and trace logs will be shown.
 	Verbose bool
@@ -68,6 +77,8 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 		errChan  = make(chan error, 1)
 		job      codersdk.ProvisionerJob
 		jobMutex sync.Mutex
+
+		queuePosition, queueSize int64
 	)
 
 	sw := &stageWriter{w: writer, verbose: opts.Verbose, silentLogs: opts.Silent}
@@ -103,6 +114,19 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 			return
 		}
 		if job.StartedAt == nil {
+			if opts.QueuePosition == nil {
+				return
+			}
+			position, size, err := opts.QueuePosition()
+			if err != nil {
+				// The position is best-effort, so keep waiting on the job
+				// and try again on the next fetch.
+				return
+			}
+			if position > 0 && (position != queuePosition || size != queueSize) {
+				sw.QueuePosition(position, size)
+			}
+			queuePosition, queueSize = position, size
 			return
 		}
 		if currentStage != "Queued" {
@@ -141,7 +165,7 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 	printStage()
 	updateJob()
 
-	logs, closer, err := opts.Logs()
+	logs, closer, err := opts.Logs(opts.SinceLogID)
 	if err != nil {
 		return xerrors.Errorf("begin streaming logs: %w", err)
 	}
@@ -184,6 +208,11 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 				return err
 			}
 
+			if opts.SinceLogID > 0 && log.ID <= opts.SinceLogID {
+				// The log was already printed before resuming.
+				continue
+			}
+
 			jobMutex.Lock()
 			if log.Stage != currentStage && log.Stage != "" {
 				updateStage(log.Stage, log.CreatedAt)
@@ -198,13 +227,39 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 
 type stageWriter struct {
 	w          io.Writer
+	tty        bool
 	verbose    bool
 	silentLogs bool
 	logBuf     bytes.Buffer
+	// started is the stage whose line was written last, so a TTY can
+	// update it in place.
+	started string
 }
 
 func (s *stageWriter) Start(stage string) {
 	_, _ = fmt.Fprintf(s.w, "==> ⧗ %s\n", stage)
+	s.started = stage
+}
+
+// Elapsed shows how long a started stage has been running. On a TTY the
+// stage line is rewritten in place if nothing was written after it,
+// otherwise a line is printed.
+func (s *stageWriter) Elapsed(stage string, elapsed time.Duration) {
+	if !s.tty {
+		_, _ = fmt.Fprintf(s.w, "%s\n", DefaultStyles.Placeholder.Render(fmt.Sprintf("Still waiting (%s)", elapsed)))
+		return
+	}
+	if s.started == stage {
+		// Move up to the stage line and clear it.
+		_, _ = fmt.Fprint(s.w, "\033[1A\r\033[2K")
+	}
+	_, _ = fmt.Fprintf(s.w, "==> ⧗ %s (%s)\n", stage, elapsed)
+	s.started = stage
+}
+
+func (s *stageWriter) QueuePosition(position, size int64) {
+	_, _ = fmt.Fprintf(s.w, "%s\n", DefaultStyles.Placeholder.Render(fmt.Sprintf("Queued (%d of %d)", position, size)))
+	s.started = ""
 }
 
 func (s *stageWriter) Complete(stage string, duration time.Duration) {
@@ -219,6 +274,7 @@ func (s *stageWriter) Fail(stage string, duration time.Duration) {
 //nolint:revive
 func (s *stageWriter) end(stage string, duration time.Duration, ok bool) {
 	s.logBuf.Reset()
+	s.started = ""
 
 	mark := "✔"
 	if !ok {
@@ -257,11 +313,15 @@ func (s *stageWriter) Log(createdAt time.Time, level codersdk.LogLevel, line str
 	case codersdk.LogLevelInfo:
 	}
 	_, _ = fmt.Fprintf(w, "%s\n", render(lines...))
+	if !s.silentLogs {
+		s.started = ""
+	}
 }
 
 func (s *stageWriter) flushLogs() {
 	if s.silentLogs {
 		_, _ = io.Copy(s.w, &s.logBuf)
+		s.started = ""
 	}
 	s.logBuf.Reset()
 }
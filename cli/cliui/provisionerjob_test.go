@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
@@ -79,6 +81,131 @@ func TestProvisionerJob(t *testing.T) {
 		test.PTY.ExpectMatch("Something")
 	})
 
+	t.Run("SinceLogID", func(t *testing.T) {
+		t.Parallel()
+
+		test := newProvisionerJobWithOptions(t, cliui.ProvisionerJobOptions{
+			SinceLogID: 2,
+		})
+		go func() {
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobRunning
+			now := database.Now()
+			test.Job.StartedAt = &now
+			test.JobMutex.Unlock()
+			for _, log := range []codersdk.ProvisionerJobLog{
+				{ID: 1, Output: "already printed one"},
+				{ID: 2, Output: "already printed two"},
+				{ID: 3, Output: "resumed log"},
+			} {
+				log.CreatedAt = database.Now()
+				log.Level = codersdk.LogLevelInfo
+				test.Logs <- log
+			}
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobSucceeded
+			now = database.Now()
+			test.Job.CompletedAt = &now
+			close(test.Logs)
+			test.JobMutex.Unlock()
+		}()
+		test.PTY.ExpectMatch("Queued")
+		test.Next <- struct{}{}
+		// Logs are printed in order, so the first log after the cursor
+		// being printed means the earlier ones were skipped.
+		out := test.PTY.ExpectMatch("resumed log")
+		assert.NotContains(t, out, "already printed")
+		test.Next <- struct{}{}
+	})
+
+	t.Run("QueuePosition", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			positionMutex sync.Mutex
+			position      int64 = 3
+			calls         int
+		)
+		test := newProvisionerJobWithOptions(t, cliui.ProvisionerJobOptions{
+			QueuePosition: func() (int64, int64, error) {
+				positionMutex.Lock()
+				defer positionMutex.Unlock()
+				calls++
+				return position, 7, nil
+			},
+		})
+		queuePositionCalls := func() int {
+			positionMutex.Lock()
+			defer positionMutex.Unlock()
+			return calls
+		}
+		go func() {
+			<-test.Next
+			positionMutex.Lock()
+			position = 1
+			positionMutex.Unlock()
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobRunning
+			now := database.Now()
+			test.Job.StartedAt = &now
+			test.JobMutex.Unlock()
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobSucceeded
+			now = database.Now()
+			test.Job.CompletedAt = &now
+			close(test.Logs)
+			test.JobMutex.Unlock()
+		}()
+		test.PTY.ExpectMatch("Queued (3 of 7)")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("Queued (1 of 7)")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("==> ⧗ Running")
+		// Fetches are serialized, so once the running stage is shown no
+		// queue position request can still be in flight.
+		callsWhenStarted := queuePositionCalls()
+		require.Positive(t, callsWhenStarted)
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("=== ✔ Running")
+		// The position is no longer polled once the job has started.
+		require.Equal(t, callsWhenStarted, queuePositionCalls())
+	})
+
+	t.Run("QueuePositionError", func(t *testing.T) {
+		t.Parallel()
+
+		test := newProvisionerJobWithOptions(t, cliui.ProvisionerJobOptions{
+			QueuePosition: func() (int64, int64, error) {
+				return 0, 0, xerrors.New("queue position unavailable")
+			},
+		})
+		go func() {
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobRunning
+			now := database.Now()
+			test.Job.StartedAt = &now
+			test.JobMutex.Unlock()
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobSucceeded
+			now = database.Now()
+			test.Job.CompletedAt = &now
+			close(test.Logs)
+			test.JobMutex.Unlock()
+		}()
+		// The job keeps being watched despite the queue position failing.
+		test.PTY.ExpectMatch("Queued")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("==> ⧗ Running")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("=== ✔ Running")
+	})
+
 	// This cannot be ran in parallel because it uses a signal.
 	// nolint:paralleltest
 	t.Run("Cancel", func(t *testing.T) {
@@ -121,6 +248,10 @@ type provisionerJobTest struct {
 }
 
 func newProvisionerJob(t *testing.T) provisionerJobTest {
+	return newProvisionerJobWithOptions(t, cliui.ProvisionerJobOptions{})
+}
+
+func newProvisionerJobWithOptions(t *testing.T, opts cliui.ProvisionerJobOptions) provisionerJobTest {
 	job := &codersdk.ProvisionerJob{
 		Status:    codersdk.ProvisionerJobPending,
 		CreatedAt: database.Now(),
@@ -129,22 +260,22 @@ func newProvisionerJob(t *testing.T) provisionerJobTest {
 	logs := make(chan codersdk.ProvisionerJobLog, 1)
 	cmd := &clibase.Cmd{
 		Handler: func(inv *clibase.Invocation) error {
-			return cliui.ProvisionerJob(inv.Context(), inv.Stdout, cliui.ProvisionerJobOptions{
-				FetchInterval: time.Millisecond,
-				Fetch: func() (codersdk.ProvisionerJob, error) {
-					jobLock.Lock()
-					defer jobLock.Unlock()
-					return *job, nil
-				},
-				Cancel: func() error {
+			opts.FetchInterval = time.Millisecond
+			opts.Fetch = func() (codersdk.ProvisionerJob, error) {
+				jobLock.Lock()
+				defer jobLock.Unlock()
+				return *job, nil
+			}
+			opts.Cancel = func() error {
+				return nil
+			}
+			opts.Logs = func(after int64) (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
+				assert.Equal(t, opts.SinceLogID, after)
+				return logs, closeFunc(func() error {
 					return nil
-				},
-				Logs: func() (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
-					return logs, closeFunc(func() error {
-						return nil
-					}), nil
-				},
-			})
+				}), nil
+			}
+			return cliui.ProvisionerJob(inv.Context(), inv.Stdout, opts)
 		},
 	}
 	inv := cmd.Invoke()
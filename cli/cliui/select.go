@@ -3,8 +3,8 @@ package cliui
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"io"
-	"os"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/AlecAivazis/survey/v2/terminal"
@@ -54,10 +54,24 @@ func init() {
 
 type SelectOptions struct {
 	Options []string
+	// Groups are rendered after Options, each under a dimmed header that
+	// cannot be selected. The selected value is still a plain option.
+	// Grouped lists are not paged or searchable.
+	Groups []SelectGroup
 	// Default will be highlighted first if it's a valid option.
 	Default    string
 	Size       int
 	HideSearch bool
+	// ConfirmSelection asks the user to confirm the selected option before
+	// returning it. Declining returns Canceled. This should be used when the
+	// selection drives a destructive action.
+	ConfirmSelection bool
+}
+
+// SelectGroup is a labelled section of options in a Select list.
+type SelectGroup struct {
+	Label   string
+	Options []string
 }
 
 type RichSelectOptions struct {
@@ -103,26 +117,62 @@ func RichSelect(inv *clibase.Invocation, richOptions RichSelectOptions) (*coders
 
 // Select displays a list of user options.
 func Select(inv *clibase.Invocation, opts SelectOptions) (string, error) {
+	value, err := selectOption(inv, opts)
+	if err != nil || !opts.ConfirmSelection {
+		return value, err
+	}
+
+	_, err = Prompt(inv, PromptOptions{
+		Text:      fmt.Sprintf("You selected %s — proceed?", DefaultStyles.Keyword.Render(value)),
+		Default:   ConfirmNo,
+		IsConfirm: true,
+	})
+	if errors.Is(err, Canceled) {
+		return "", Canceled
+	}
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func selectOption(inv *clibase.Invocation, opts SelectOptions) (string, error) {
 	// The survey library used *always* fails when testing on Windows,
 	// as it requires a live TTY (can't be a conpty). We should fork
 	// this library to add a dummy fallback, that simply reads/writes
 	// to the IO provided. See:
 	// https://github.com/AlecAivazis/survey/blob/master/terminal/runereader_windows.go#L94
 	if flag.Lookup("test.v") != nil {
-		return opts.Options[0], nil
+		if len(opts.Options) > 0 {
+			return opts.Options[0], nil
+		}
+		for _, group := range opts.Groups {
+			if len(group.Options) > 0 {
+				return group.Options[0], nil
+			}
+		}
+		return "", xerrors.New("no options to select from")
 	}
+	return askSelect(inv, opts)
+}
 
+func askSelect(inv *clibase.Invocation, opts SelectOptions) (string, error) {
 	var defaultOption interface{}
 	if opts.Default != "" {
 		defaultOption = opts.Default
 	}
 
-	var value string
-	err := survey.AskOne(&survey.Select{
+	var prompt survey.Prompt = &survey.Select{
 		Options:  opts.Options,
 		Default:  defaultOption,
 		PageSize: opts.Size,
-	}, &value, survey.WithIcons(func(is *survey.IconSet) {
+	}
+	if len(opts.Groups) > 0 {
+		prompt = newGroupedSelect(opts)
+	}
+
+	var value string
+	err := survey.AskOne(prompt, &value, survey.WithIcons(func(is *survey.IconSet) {
 		is.Help.Text = "Type to search"
 		if opts.HideSearch {
 			is.Help.Text = ""
@@ -138,6 +188,114 @@ func Select(inv *clibase.Invocation, opts SelectOptions) (string, error) {
 	return value, err
 }
 
+const groupedSelectTemplate = `
+{{- if not .ShowAnswer }}
+{{- "\n" }}
+{{- range $ix, $option := .Options }}
+  {{- with index $.Headers $ix }}{{ color "black+h" }}{{ . }}{{ color "reset" }}{{ "\n" }}{{ end }}
+  {{- "  " }}{{- if eq $.SelectedIndex $ix }}{{ color "green" }}{{ $.Config.Icons.SelectFocus.Text }} {{ else }}{{ color "default" }}  {{ end }}
+  {{- $option }}
+  {{- color "reset" }}{{ "\n" }}
+{{- end }}
+{{- end }}`
+
+type groupedSelectTemplateData struct {
+	Options       []string
+	Headers       map[int]string
+	SelectedIndex int
+	ShowAnswer    bool
+	Config        *survey.PromptConfig
+}
+
+// groupedSelect is a survey prompt that renders options under group
+// headers. Only options are held in the list, so arrow navigation never
+// lands on a header.
+type groupedSelect struct {
+	survey.Renderer
+	options  []string
+	headers  map[int]string
+	selected int
+}
+
+func newGroupedSelect(opts SelectOptions) *groupedSelect {
+	s := &groupedSelect{
+		options: append([]string{}, opts.Options...),
+		headers: map[int]string{},
+	}
+	for _, group := range opts.Groups {
+		if len(group.Options) == 0 {
+			continue
+		}
+		s.headers[len(s.options)] = group.Label
+		s.options = append(s.options, group.Options...)
+	}
+	for i, option := range s.options {
+		if option == opts.Default {
+			s.selected = i
+			break
+		}
+	}
+	return s
+}
+
+func (s *groupedSelect) render(config *survey.PromptConfig, showAnswer bool) error {
+	return s.Render(groupedSelectTemplate, groupedSelectTemplateData{
+		Options:       s.options,
+		Headers:       s.headers,
+		SelectedIndex: s.selected,
+		ShowAnswer:    showAnswer,
+		Config:        config,
+	})
+}
+
+func (s *groupedSelect) Prompt(config *survey.PromptConfig) (interface{}, error) {
+	if len(s.options) == 0 {
+		return "", xerrors.New("no options to select from")
+	}
+
+	cursor := s.NewCursor()
+	cursor.Hide()
+	defer cursor.Show()
+
+	err := s.render(config, false)
+	if err != nil {
+		return "", err
+	}
+
+	rr := s.NewRuneReader()
+	_ = rr.SetTermMode()
+	defer func() {
+		_ = rr.RestoreTermMode()
+	}()
+
+	for {
+		r, _, err := rr.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case terminal.KeyInterrupt:
+			return "", terminal.InterruptErr
+		case terminal.KeyEnter, '\n', terminal.KeyEndTransmission:
+			return s.options[s.selected], nil
+		case terminal.KeyArrowUp:
+			s.selected = (s.selected - 1 + len(s.options)) % len(s.options)
+		case terminal.KeyArrowDown:
+			s.selected = (s.selected + 1) % len(s.options)
+		default:
+			continue
+		}
+		err = s.render(config, false)
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (s *groupedSelect) Cleanup(config *survey.PromptConfig, _ interface{}) error {
+	return s.render(config, true)
+}
+
 func MultiSelect(inv *clibase.Invocation, items []string) ([]string, error) {
 	// Similar hack is applied to Select()
 	if flag.Lookup("test.v") != nil {
@@ -166,11 +324,15 @@ type fileReadWriter struct {
 	io.Writer
 }
 
+type fder interface {
+	Fd() uintptr
+}
+
 func (f fileReadWriter) Fd() uintptr {
-	if file, ok := f.Reader.(*os.File); ok {
+	if file, ok := f.Reader.(fder); ok {
 		return file.Fd()
 	}
-	if file, ok := f.Writer.(*os.File); ok {
+	if file, ok := f.Writer.(fder); ok {
 		return file.Fd()
 	}
 	return 0
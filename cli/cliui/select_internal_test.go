@@ -0,0 +1,56 @@
+package cliui
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/pty/ptytest"
+)
+
+func TestSelectGroups(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("survey requires a live TTY, which conpty does not provide")
+	}
+
+	ptty := ptytest.New(t)
+	msgChan := make(chan string)
+	go func() {
+		var value string
+		cmd := &clibase.Cmd{
+			Handler: func(inv *clibase.Invocation) error {
+				var err error
+				// Call askSelect directly, Select short-circuits under go test.
+				value, err = askSelect(inv, SelectOptions{
+					Groups: []SelectGroup{
+						{Label: "Empty"},
+						{Label: "coder", Options: []string{"docker", "kubernetes"}},
+						{Label: "acme", Options: []string{"aws"}},
+					},
+				})
+				return err
+			},
+		}
+		inv := cmd.Invoke()
+		ptty.Attach(inv)
+		assert.NoError(t, inv.Run())
+		msgChan <- value
+	}()
+
+	ptty.ExpectMatch("coder")
+	ptty.ExpectMatch("docker")
+	ptty.ExpectMatch("kubernetes")
+	ptty.ExpectMatch("acme")
+	ptty.ExpectMatch("aws")
+	// Moving down twice passes the "acme" header without stopping on it.
+	for i := 0; i < 2; i++ {
+		_, err := ptty.Input().Write([]byte("\x1b[B"))
+		require.NoError(t, err)
+	}
+	ptty.Write('\r')
+	require.Equal(t, "aws", <-msgChan)
+}
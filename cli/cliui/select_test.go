@@ -27,6 +27,40 @@ func TestSelect(t *testing.T) {
 		}()
 		require.Equal(t, "First", <-msgChan)
 	})
+
+	t.Run("ConfirmSelectionDeclined", func(t *testing.T) {
+		t.Parallel()
+		ptty := ptytest.New(t)
+		errChan := make(chan error)
+		go func() {
+			_, err := newSelect(ptty, cliui.SelectOptions{
+				Options:          []string{"First", "Second"},
+				ConfirmSelection: true,
+			})
+			errChan <- err
+		}()
+		ptty.ExpectMatch("You selected")
+		ptty.ExpectMatch("First")
+		ptty.WriteLine("no")
+		require.ErrorIs(t, <-errChan, cliui.Canceled)
+	})
+
+	t.Run("ConfirmSelectionAccepted", func(t *testing.T) {
+		t.Parallel()
+		ptty := ptytest.New(t)
+		msgChan := make(chan string)
+		go func() {
+			resp, err := newSelect(ptty, cliui.SelectOptions{
+				Options:          []string{"First", "Second"},
+				ConfirmSelection: true,
+			})
+			assert.NoError(t, err)
+			msgChan <- resp
+		}()
+		ptty.ExpectMatch("You selected")
+		ptty.WriteLine("yes")
+		require.Equal(t, "First", <-msgChan)
+	})
 }
 
 func newSelect(ptty *ptytest.PTY, opts cliui.SelectOptions) (string, error) {
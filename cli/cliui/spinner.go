@@ -0,0 +1,103 @@
+package cliui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/mattn/go-isatty"
+)
+
+// Spinner displays an animated indicator next to a message while a long
+// running operation is in progress. If the writer is not a terminal, messages
+// are printed as plain lines instead of being animated.
+type Spinner struct {
+	// ForceTTY animates the spinner even if the writer is not a terminal.
+	// It must be set before Start is called.
+	ForceTTY bool
+
+	w       io.Writer
+	mu      sync.Mutex
+	spin    *spinner.Spinner
+	started bool
+}
+
+// NewSpinner creates a spinner that writes to w.
+func NewSpinner(w io.Writer) *Spinner {
+	return &Spinner{w: w}
+}
+
+func (s *Spinner) tty() bool {
+	if s.ForceTTY {
+		return true
+	}
+	file, ok := s.w.(*os.File)
+	return ok && isatty.IsTerminal(file.Fd())
+}
+
+// Start starts the spinner with the given message. Calling Start on a spinner
+// that is already running only updates the message.
+func (s *Spinner) Start(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		s.updateLocked(msg)
+		return
+	}
+	s.started = true
+
+	if !s.tty() {
+		_, _ = fmt.Fprintln(s.w, msg)
+		return
+	}
+	s.spin = spinner.New(spinner.CharSets[78], 100*time.Millisecond, spinner.WithColor("fgHiGreen"))
+	s.spin.Writer = s.w
+	s.spin.ForceOutput = true
+	s.spin.Suffix = " " + msg
+	s.spin.Start()
+}
+
+// Update replaces the message displayed next to the spinner.
+func (s *Spinner) Update(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		return
+	}
+	s.updateLocked(msg)
+}
+
+func (s *Spinner) updateLocked(msg string) {
+	if s.spin == nil {
+		_, _ = fmt.Fprintln(s.w, msg)
+		return
+	}
+	s.spin.Lock()
+	s.spin.Suffix = " " + msg
+	s.spin.Unlock()
+}
+
+// Stop stops the spinner and prints finalMsg on its own line if it is not
+// empty. It is safe to call Stop multiple times.
+func (s *Spinner) Stop(finalMsg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		return
+	}
+	s.started = false
+
+	if s.spin != nil {
+		s.spin.Stop()
+		s.spin = nil
+	}
+	if finalMsg != "" {
+		_, _ = fmt.Fprintln(s.w, finalMsg)
+	}
+}
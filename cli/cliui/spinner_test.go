@@ -0,0 +1,51 @@
+package cliui_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/cliui"
+)
+
+func TestSpinner(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TTY", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		spin := cliui.NewSpinner(&buf)
+		spin.ForceTTY = true
+		spin.Start("Uploading")
+		// Give the spinner time to render at least one frame.
+		time.Sleep(250 * time.Millisecond)
+		spin.Update("Processing")
+		time.Sleep(250 * time.Millisecond)
+		spin.Stop("Done!")
+
+		out := buf.String()
+		require.Contains(t, out, "Uploading")
+		require.Contains(t, out, "Processing")
+		// Animated frames are redrawn in place with carriage returns.
+		require.Contains(t, out, "\r")
+		require.True(t, strings.HasSuffix(out, "Done!\n"), "output %q should end with the final message", out)
+	})
+
+	t.Run("NoTTY", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		spin := cliui.NewSpinner(&buf)
+		spin.Start("Uploading")
+		spin.Update("Processing")
+		spin.Stop("Done!")
+		// Stopping twice is a no-op.
+		spin.Stop("Done again!")
+
+		require.Equal(t, "Uploading\nProcessing\nDone!\n", buf.String())
+	})
+}
@@ -612,8 +612,8 @@ func (r *RootCmd) scaletestCreateWorkspaces() *clibase.Cmd {
 					Cancel: func() error {
 						return client.CancelTemplateVersionDryRun(inv.Context(), templateVersion.ID, dryRun.ID)
 					},
-					Logs: func() (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
-						return client.TemplateVersionDryRunLogsAfter(inv.Context(), templateVersion.ID, dryRun.ID, 0)
+					Logs: func(after int64) (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
+						return client.TemplateVersionDryRunLogsAfter(inv.Context(), templateVersion.ID, dryRun.ID, after)
 					},
 					// Don't show log output for the dry-run unless there's an error.
 					Silent: true,
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"os/signal"
-	"time"
 
 	"golang.org/x/xerrors"
 
@@ -13,7 +12,7 @@ import (
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/coderd/gitauth"
 	"github.com/coder/coder/codersdk"
-	"github.com/coder/retry"
+	"github.com/coder/coder/codersdk/agentsdk"
 )
 
 // gitAskpass is used by the Coder agent to automatically authenticate
@@ -56,14 +55,11 @@ func (r *RootCmd) gitAskpass() *clibase.Cmd {
 					cliui.Infof(inv.Stderr, "Open the following URL to authenticate with Git:\n\n%s\n", token.URL)
 				}
 
-				for r := retry.New(250*time.Millisecond, 10*time.Second); r.Wait(ctx); {
-					token, err = client.GitAuth(ctx, host, true)
-					if err != nil {
-						continue
-					}
-					cliui.Infof(inv.Stderr, "You've been authenticated with Git!\n")
-					break
+				token, err = client.WaitForGitAuth(ctx, host, agentsdk.GitAuthWaitTimeout)
+				if err != nil {
+					return xerrors.Errorf("wait for git auth: %w", err)
 				}
+				cliui.Infof(inv.Stderr, "You've been authenticated with Git!\n")
 			}
 
 			if token.Password != "" {
@@ -59,6 +59,7 @@ const (
 	varNoVersionCheck   = "no-version-warning"
 	varNoFeatureWarning = "no-feature-warning"
 	varForceTty         = "force-tty"
+	varForceColor       = "force-color"
 	varVerbose          = "verbose"
 	varDisableDirect    = "disable-direct-connections"
 	notLoggedInMessage  = "You are not logged in. Try logging in using 'coder login <url>'."
@@ -273,6 +274,11 @@ func (r *RootCmd) Command(subcommands []*clibase.Cmd) (*clibase.Cmd, error) {
 			return
 		}
 		cmd.Handler = func(i *clibase.Invocation) error {
+			// Tests run invocations in parallel against the same global
+			// styles, so only the real binary configures color.
+			if !isTest() {
+				r.setColor(i)
+			}
 			if !debugOptions {
 				return h(i)
 			}
@@ -372,6 +378,13 @@ func (r *RootCmd) Command(subcommands []*clibase.Cmd) (*clibase.Cmd, error) {
 			Value:       clibase.BoolOf(&r.forceTTY),
 			Group:       globalGroup,
 		},
+		{
+			Flag:        varForceColor,
+			Env:         "CODER_FORCE_COLOR",
+			Description: "Render output with color even if stdout is not a terminal or NO_COLOR is set.",
+			Value:       clibase.BoolOf(&r.forceColor),
+			Group:       globalGroup,
+		},
 		{
 			Flag:          varVerbose,
 			FlagShorthand: "v",
@@ -431,6 +444,12 @@ func isTest() bool {
 	return flag.Lookup("test.v") != nil
 }
 
+// setColor enables color in cliui output if the invocation writes to a
+// terminal, or if --force-color is set.
+func (r *RootCmd) setColor(inv *clibase.Invocation) {
+	cliui.SetColor(r.forceColor || cliui.ColorEnabled(inv.Stdout))
+}
+
 // RootCmd contains parameters and helpers useful to all commands.
 type RootCmd struct {
 	clientURL     *url.URL
@@ -440,6 +459,7 @@ type RootCmd struct {
 	agentToken    string
 	agentURL      *url.URL
 	forceTTY      bool
+	forceColor    bool
 	noOpen        bool
 	verbose       bool
 	disableDirect bool
@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+
+	"github.com/coder/coder/cli/clibase"
 )
 
 func Test_formatExamples(t *testing.T) {
@@ -66,6 +72,32 @@ func Test_formatExamples(t *testing.T) {
 	}
 }
 
+// This cannot be ran in parallel because it changes the global color profile.
+//
+//nolint:paralleltest
+func TestRootSetColor(t *testing.T) {
+	ogColorProfile := lipgloss.ColorProfile()
+	ogDisableColor := core.DisableColor
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(ogColorProfile)
+		core.DisableColor = ogDisableColor
+	})
+
+	inv := (&clibase.Cmd{}).Invoke()
+	inv.Stdout = &bytes.Buffer{}
+
+	// Color is decided by the invocation's stdout, which is not a terminal.
+	r := &RootCmd{}
+	r.setColor(inv)
+	require.True(t, core.DisableColor)
+	require.Equal(t, termenv.Ascii, lipgloss.ColorProfile())
+
+	r.forceColor = true
+	r.setColor(inv)
+	require.False(t, core.DisableColor)
+	require.NotEqual(t, termenv.Ascii, lipgloss.ColorProfile())
+}
+
 func TestMain(m *testing.M) {
 	goleak.VerifyTestMain(m,
 		// The lumberjack library is used by by agent and seems to leave
@@ -967,6 +967,7 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			defer autobuildTicker.Stop()
 			autobuildExecutor := autobuild.NewExecutor(ctx, options.Database, coderAPI.TemplateScheduleStore, logger, autobuildTicker.C)
 			autobuildExecutor.Run()
+			defer autobuildExecutor.Close()
 
 			hangDetectorTicker := time.NewTicker(cfg.JobHangDetectorInterval.Value())
 			defer hangDetectorTicker.Stop()
@@ -1317,9 +1318,10 @@ func newProvisionerDaemon(
 				ServeOptions: &provisionersdk.ServeOptions{
 					Listener: terraformServer,
 				},
-				CachePath: tfDir,
-				Logger:    logger,
-				Tracer:    tracer,
+				CachePath:        tfDir,
+				Logger:           logger,
+				Tracer:           tracer,
+				ReadOnlyLockfile: cfg.Provisioner.TerraformReadOnlyLockfile.Value(),
 			})
 			if err != nil && !xerrors.Is(err, context.Canceled) {
 				select {
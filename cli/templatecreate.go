@@ -270,8 +270,8 @@ func createValidTemplateVersion(inv *clibase.Invocation, args createValidTemplat
 		Cancel: func() error {
 			return client.CancelTemplateVersion(inv.Context(), version.ID)
 		},
-		Logs: func() (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
-			return client.TemplateVersionLogsAfter(inv.Context(), version.ID, 0)
+		Logs: func(after int64) (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
+			return client.TemplateVersionLogsAfter(inv.Context(), version.ID, after)
 		},
 	})
 	if err != nil {
@@ -8,7 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/briandowns/spinner"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/cli/clibase"
@@ -84,11 +83,9 @@ func (pf *templateUploadFlags) upload(inv *clibase.Invocation, client *codersdk.
 		content = pipeReader
 	}
 
-	spin := spinner.New(spinner.CharSets[5], 100*time.Millisecond)
-	spin.Writer = inv.Stdout
-	spin.Suffix = cliui.DefaultStyles.Keyword.Render(" Uploading directory...")
-	spin.Start()
-	defer spin.Stop()
+	spin := cliui.NewSpinner(inv.Stdout)
+	spin.Start(cliui.DefaultStyles.Keyword.Render("Uploading directory..."))
+	defer spin.Stop("")
 
 	resp, err := client.Upload(inv.Context(), codersdk.ContentTypeTar, bufio.NewReader(content))
 	if err != nil {
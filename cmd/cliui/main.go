@@ -105,7 +105,7 @@ func main() {
 				Fetch: func() (codersdk.ProvisionerJob, error) {
 					return job, nil
 				},
-				Logs: func() (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
+				Logs: func(_ int64) (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
 					logs := make(chan codersdk.ProvisionerJobLog)
 					go func() {
 						defer close(logs)
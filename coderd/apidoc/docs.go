@@ -402,6 +402,34 @@ const docTemplate = `{
                 }
             }
         },
+        "/debug/gitauth": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debug"
+                ],
+                "summary": "Debug Info Git Auth Health",
+                "operationId": "debug-info-git-auth-health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.GitAuthHealth"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/debug/health": {
             "get": {
                 "security": [
@@ -2820,11 +2848,20 @@ const docTemplate = `{
                         "name": "templateversion",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Redirect to the rich parameters endpoint",
+                        "name": "redirect",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "308": {
+                        "description": "Permanent Redirect"
                     }
                 }
             }
@@ -2925,11 +2962,20 @@ const docTemplate = `{
                         "name": "templateversion",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Redirect to the rich parameters endpoint",
+                        "name": "redirect",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "308": {
+                        "description": "Permanent Redirect"
                     }
                 }
             }
@@ -5429,6 +5475,14 @@ const docTemplate = `{
                 ],
                 "summary": "Get workspace proxies",
                 "operationId": "get-workspace-proxies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by display name",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -5752,7 +5806,7 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search query in the format ` + "`" + `key:value` + "`" + `. Available keys are: owner, template, name, status, has-agent, deleting_by.",
+                        "description": "Search query in the format ` + "`" + `key:value` + "`" + `. Available keys are: owner, template, name, status, has-agent, deleting_by, sort_by.",
                         "name": "q",
                         "in": "query"
                     },
@@ -8192,6 +8246,23 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.GitAuthHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reachable": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.GitAuthUser": {
             "type": "object",
             "properties": {
@@ -8764,6 +8835,9 @@ const docTemplate = `{
                 },
                 "force_cancel_interval": {
                     "type": "integer"
+                },
+                "terraform_readonly_lockfile": {
+                    "type": "boolean"
                 }
             }
         },
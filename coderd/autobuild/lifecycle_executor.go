@@ -28,6 +28,101 @@ type Executor struct {
 	log                   slog.Logger
 	tick                  <-chan time.Time
 	statsCh               chan<- Stats
+	// clock, if set, overrides the time of each tick and is used whenever
+	// the current time is needed.
+	clock Clock
+
+	// closed is closed by Close to stop Run after the current tick.
+	closed    chan struct{}
+	closeOnce sync.Once
+	// running tracks the goroutine started by Run.
+	running sync.WaitGroup
+
+	// autostartFailures tracks consecutive autostart build failures per
+	// workspace so that retries can be backed off.
+	autostartFailuresMu sync.Mutex
+	autostartFailures   map[uuid.UUID]autostartFailure
+}
+
+// autostartFailure records consecutive failed autostart attempts for a
+// workspace.
+type autostartFailure struct {
+	count       int
+	lastAttempt time.Time
+}
+
+const (
+	autostartBackoffBase = time.Minute
+	autostartBackoffMax  = time.Hour
+)
+
+// autostartBackoff returns how long to wait before retrying autostart after
+// the given number of consecutive failures.
+func autostartBackoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	backoff := autostartBackoffBase
+	for i := 1; i < failures; i++ {
+		backoff *= 2
+		if backoff >= autostartBackoffMax {
+			return autostartBackoffMax
+		}
+	}
+	return backoff
+}
+
+// shouldBackoffAutostart returns true if autostart for the workspace should
+// be skipped at tick because of previous consecutive failures.
+func (e *Executor) shouldBackoffAutostart(workspaceID uuid.UUID, tick time.Time) bool {
+	e.autostartFailuresMu.Lock()
+	defer e.autostartFailuresMu.Unlock()
+	failure, ok := e.autostartFailures[workspaceID]
+	if !ok {
+		return false
+	}
+	return tick.Before(failure.lastAttempt.Add(autostartBackoff(failure.count)))
+}
+
+// pruneAutostartFailures forgets the failures of workspaces that are no
+// longer eligible for transition, e.g. because they were deleted or had
+// autostart disabled, so the map does not grow without bound.
+func (e *Executor) pruneAutostartFailures(eligible []database.Workspace) {
+	e.autostartFailuresMu.Lock()
+	defer e.autostartFailuresMu.Unlock()
+	if len(e.autostartFailures) == 0 {
+		return
+	}
+	keep := make(map[uuid.UUID]struct{}, len(eligible))
+	for _, ws := range eligible {
+		keep[ws.ID] = struct{}{}
+	}
+	for id := range e.autostartFailures {
+		if _, ok := keep[id]; !ok {
+			delete(e.autostartFailures, id)
+		}
+	}
+}
+
+// recordAutostartResult updates the consecutive failure count for the
+// workspace after an autostart attempt at tick.
+func (e *Executor) recordAutostartResult(workspaceID uuid.UUID, tick time.Time, failed bool) {
+	e.autostartFailuresMu.Lock()
+	defer e.autostartFailuresMu.Unlock()
+	if !failed {
+		delete(e.autostartFailures, workspaceID)
+		return
+	}
+	failure := e.autostartFailures[workspaceID]
+	failure.count++
+	failure.lastAttempt = tick
+	e.autostartFailures[workspaceID] = failure
+}
+
+// Clock provides the current time to the Executor. It allows tests to
+// control time deterministically.
+type Clock interface {
+	Now() time.Time
 }
 
 // Stats contains information about one run of Executor.
@@ -46,6 +141,8 @@ func NewExecutor(ctx context.Context, db database.Store, tss *atomic.Pointer[sch
 		templateScheduleStore: tss,
 		tick:                  tick,
 		log:                   log.Named("autobuild"),
+		closed:                make(chan struct{}),
+		autostartFailures:     make(map[uuid.UUID]autostartFailure),
 	}
 	return le
 }
@@ -57,15 +154,34 @@ func (e *Executor) WithStatsChannel(ch chan<- Stats) *Executor {
 	return e
 }
 
+// WithClock will cause Executor to evaluate transitions against the time
+// reported by c rather than the time of each tick.
+func (e *Executor) WithClock(c Clock) *Executor {
+	e.clock = c
+	return e
+}
+
+// timeNow returns the current time, using the clock if one is set.
+func (e *Executor) timeNow() time.Time {
+	if e.clock != nil {
+		return database.Time(e.clock.Now())
+	}
+	return database.Now()
+}
+
 // Run will cause executor to start or stop workspaces on every
-// tick from its channel. It will stop when its context is Done, or when
-// its channel is closed.
+// tick from its channel. It will stop when its context is Done, when
+// its channel is closed, or when Close is called.
 func (e *Executor) Run() {
+	e.running.Add(1)
 	go func() {
+		defer e.running.Done()
 		for {
 			select {
 			case <-e.ctx.Done():
 				return
+			case <-e.closed:
+				return
 			case t, ok := <-e.tick:
 				if !ok {
 					return
@@ -78,6 +194,8 @@ func (e *Executor) Run() {
 					select {
 					case <-e.ctx.Done():
 						return
+					case <-e.closed:
+						return
 					case e.statsCh <- stats:
 					}
 				}
@@ -87,17 +205,32 @@ func (e *Executor) Run() {
 	}()
 }
 
-func (e *Executor) runOnce(t time.Time) Stats {
+// Close stops the executor from processing further ticks and blocks until
+// the tick in progress, if any, has finished. Unlike canceling the context,
+// it doesn't interrupt in-flight transitions.
+func (e *Executor) Close() {
+	e.closeOnce.Do(func() {
+		close(e.closed)
+	})
+	e.running.Wait()
+}
+
+func (e *Executor) runOnce(tick time.Time) Stats {
 	var err error
 	stats := Stats{
 		Transitions: make(map[uuid.UUID]database.WorkspaceTransition),
 	}
 	// we build the map of transitions concurrently, so need a mutex to serialize writes to the map
 	statsMu := sync.Mutex{}
+	start := e.timeNow()
 	defer func() {
-		stats.Elapsed = time.Since(t)
+		stats.Elapsed = e.timeNow().Sub(start)
 		stats.Error = err
 	}()
+	t := tick
+	if e.clock != nil {
+		t = e.clock.Now()
+	}
 	currentTick := t.Truncate(time.Minute)
 
 	// TTL is set at the workspace level, and deadline at the workspace build level.
@@ -115,6 +248,7 @@ func (e *Executor) runOnce(t time.Time) Stats {
 		e.log.Error(e.ctx, "get workspaces for autostart or autostop", slog.Error(err))
 		return stats
 	}
+	e.pruneAutostartFailures(workspaces)
 
 	// We only use errgroup here for convenience of API, not for early
 	// cancellation. This means we only return nil errors in th eg.Go.
@@ -160,13 +294,23 @@ func (e *Executor) runOnce(t time.Time) Stats {
 					return nil
 				}
 
+				isAutostart := reason == database.BuildReasonAutostart
+				if isAutostart && e.shouldBackoffAutostart(ws.ID, currentTick) {
+					log.Debug(e.ctx, "skipping workspace, backing off after failed autostart")
+					return nil
+				}
+
 				if nextTransition != "" {
 					builder := wsbuilder.New(ws, nextTransition).
 						SetLastWorkspaceBuildInTx(&latestBuild).
 						SetLastWorkspaceBuildJobInTx(&latestJob).
 						Reason(reason)
 
-					if _, _, err := builder.Build(e.ctx, tx, nil); err != nil {
+					_, _, err := builder.Build(e.ctx, tx, nil)
+					if isAutostart {
+						e.recordAutostartResult(ws.ID, currentTick, err != nil)
+					}
+					if err != nil {
 						log.Error(e.ctx, "unable to transition workspace",
 							slog.F("transition", nextTransition),
 							slog.Error(err),
@@ -181,7 +325,7 @@ func (e *Executor) runOnce(t time.Time) Stats {
 					ws, err = tx.UpdateWorkspaceLockedDeletingAt(e.ctx, database.UpdateWorkspaceLockedDeletingAtParams{
 						ID: ws.ID,
 						LockedAt: sql.NullTime{
-							Time:  database.Now(),
+							Time:  e.timeNow(),
 							Valid: true,
 						},
 					})
@@ -196,7 +340,7 @@ func (e *Executor) runOnce(t time.Time) Stats {
 					log.Info(e.ctx, "locked workspace",
 						slog.F("last_used_at", ws.LastUsedAt),
 						slog.F("inactivity_ttl", templateSchedule.InactivityTTL),
-						slog.F("since_last_used_at", time.Since(ws.LastUsedAt)),
+						slog.F("since_last_used_at", e.timeNow().Sub(ws.LastUsedAt)),
 					)
 				}
 
@@ -259,6 +403,8 @@ func getNextTransition(
 	error,
 ) {
 	switch {
+	case templateSchedule.AutobuildPaused:
+		return "", "", xerrors.New("autobuild is paused for the template")
 	case isEligibleForAutostop(ws, latestBuild, latestJob, currentTick):
 		return database.WorkspaceTransitionStop, database.BuildReasonAutostop, nil
 	case isEligibleForAutostart(ws, latestBuild, latestJob, templateSchedule, currentTick):
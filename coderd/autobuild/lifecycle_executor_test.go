@@ -2,7 +2,10 @@ package autobuild_test
 
 import (
 	"context"
+	"database/sql"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -10,17 +13,23 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+	"golang.org/x/xerrors"
 
 	"cdr.dev/slog/sloggers/slogtest"
 
 	"github.com/coder/coder/coderd/autobuild"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/pubsub"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
 )
 
 func TestExecutorAutostartOK(t *testing.T) {
@@ -109,6 +118,66 @@ func TestExecutorAutostartTemplateUpdated(t *testing.T) {
 	assert.Equal(t, workspace.LatestBuild.TemplateVersionID, ws.LatestBuild.TemplateVersionID, "expected workspace build to be using the old template version")
 }
 
+func TestExecutorAutostartTemplatePaused(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sched          = mustSchedule(t, "CRON_TZ=UTC 0 * * * *")
+		ctx            = context.Background()
+		tickCh         = make(chan time.Time)
+		statsCh        = make(chan autobuild.Stats)
+		client, _, api = coderdtest.NewWithAPI(t, &coderdtest.Options{
+			AutobuildTicker:          tickCh,
+			IncludeProvisionerDaemon: true,
+			AutobuildStats:           statsCh,
+		})
+		user = coderdtest.CreateFirstUser(t, client)
+	)
+
+	// Given: two stopped workspaces with autostart enabled on different templates
+	provision := func() codersdk.Workspace {
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		ws := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = ptr.Ref(sched.String())
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		return coderdtest.MustTransitionWorkspace(t, client, ws.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+	}
+	pausedWorkspace := provision()
+	unpausedWorkspace := provision()
+
+	// Given: autobuild is paused for one of the templates
+	ownerCtx := dbauthz.As(ctx, rbac.Subject{
+		ID:     user.UserID.String(),
+		Roles:  rbac.RoleNames{rbac.RoleOwner()},
+		Groups: []string{},
+		Scope:  rbac.ScopeAll,
+	})
+	store := *api.TemplateScheduleStore.Load()
+	tpl, err := api.Database.GetTemplateByID(ownerCtx, pausedWorkspace.TemplateID)
+	require.NoError(t, err)
+	opts, err := store.Get(ownerCtx, api.Database, tpl.ID)
+	require.NoError(t, err)
+	opts.AutobuildPaused = true
+	_, err = store.Set(ownerCtx, api.Database, tpl, opts)
+	require.NoError(t, err)
+
+	// When: the autobuild executor ticks after the scheduled time
+	go func() {
+		tickCh <- sched.Next(unpausedWorkspace.LatestBuild.CreatedAt)
+		close(tickCh)
+	}()
+
+	// Then: only the workspace of the unpaused template is started
+	stats := <-statsCh
+	assert.NoError(t, stats.Error)
+	assert.Len(t, stats.Transitions, 1)
+	assert.NotContains(t, stats.Transitions, pausedWorkspace.ID)
+	assert.Equal(t, database.WorkspaceTransitionStart, stats.Transitions[unpausedWorkspace.ID])
+}
+
 func TestExecutorAutostartAlreadyRunning(t *testing.T) {
 	t.Parallel()
 
@@ -212,6 +281,51 @@ func TestExecutorAutostopOK(t *testing.T) {
 	assert.Equal(t, codersdk.BuildReasonAutostop, workspace.LatestBuild.Reason)
 }
 
+func TestExecutorAutostopClock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock   = &fakeClock{}
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan autobuild.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:          tickCh,
+			IncludeProvisionerDaemon: true,
+			AutobuildStats:           statsCh,
+			AutobuildClock:           clock,
+		})
+		// Given: we have a user with a workspace
+		workspace = mustProvisionWorkspace(t, client)
+	)
+	// Given: workspace is running
+	require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+	require.NotZero(t, workspace.LatestBuild.Deadline)
+	deadline := workspace.LatestBuild.Deadline.Time.Truncate(time.Minute).Add(time.Minute)
+
+	// When: the clock is one minute before the deadline
+	clock.Set(deadline.Add(-time.Minute))
+	tickCh <- time.Time{}
+
+	// Then: the workspace should not be stopped
+	stats := <-statsCh
+	assert.NoError(t, stats.Error)
+	assert.Len(t, stats.Transitions, 0)
+
+	// When: the clock is advanced to the deadline
+	clock.Advance(time.Minute)
+	tickCh <- time.Time{}
+	close(tickCh)
+
+	// Then: the workspace should be stopped
+	stats = <-statsCh
+	assert.NoError(t, stats.Error)
+	assert.Len(t, stats.Transitions, 1)
+	assert.Equal(t, database.WorkspaceTransitionStop, stats.Transitions[workspace.ID])
+
+	workspace = coderdtest.MustWorkspace(t, client, workspace.ID)
+	assert.Equal(t, codersdk.BuildReasonAutostop, workspace.LatestBuild.Reason)
+}
+
 func TestExecutorAutostopExtend(t *testing.T) {
 	t.Parallel()
 
@@ -790,6 +904,208 @@ func mustProvisionWorkspaceWithParameters(t *testing.T, client *codersdk.Client,
 	return coderdtest.MustWorkspace(t, client, ws.ID)
 }
 
+func TestExecutorCloseWaitsForTick(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		tickCh      = make(chan time.Time)
+		db          = &blockingStore{
+			Store:   dbfake.New(),
+			started: make(chan struct{}),
+			release: make(chan struct{}),
+		}
+		tss = &atomic.Pointer[schedule.TemplateScheduleStore]{}
+	)
+	defer cancel()
+	store := schedule.NewAGPLTemplateScheduleStore()
+	tss.Store(&store)
+
+	executor := autobuild.NewExecutor(ctx, db, tss, slogtest.Make(t, nil), tickCh)
+	executor.Run()
+
+	// Given: a tick is being processed
+	tickCh <- time.Now()
+	<-db.started
+
+	// When: the executor is closed
+	closed := make(chan struct{})
+	go func() {
+		executor.Close()
+		close(closed)
+	}()
+
+	// Then: Close blocks until the tick completes
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the in-progress tick completed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(db.release)
+	select {
+	case <-closed:
+	case <-time.After(testutil.WaitShort):
+		t.Fatal("Close did not return after the tick completed")
+	}
+}
+
+// blockingStore blocks fetching workspaces for transition until released.
+type blockingStore struct {
+	database.Store
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
+	close(s.started)
+	<-s.release
+	return s.Store.GetWorkspacesEligibleForTransition(ctx, now)
+}
+
+func TestExecutorAutostartFailureBackoff(t *testing.T) {
+	t.Parallel()
+
+	_, _, db, tick := setupFailingAutostart(t)
+
+	// When: the autobuild executor ticks every minute after the scheduled time
+	for minute := 0; minute < 8; minute++ {
+		tick(minute)
+	}
+
+	// Then: retries back off exponentially, attempting at minutes 0, 1, 3
+	// and 7 only.
+	assert.EqualValues(t, 4, db.state.attempts.Load())
+}
+
+func TestExecutorAutostartFailureBackoffPruned(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, db, tick := setupFailingAutostart(t)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	// When: autostart fails at minutes 0, 1 and 3, backing off until 7
+	for minute := 0; minute < 4; minute++ {
+		tick(minute)
+	}
+	require.EqualValues(t, 3, db.state.attempts.Load())
+
+	// When: autostart is disabled, the workspace is no longer eligible and
+	// its failures are forgotten.
+	err := client.UpdateWorkspaceAutostart(ctx, workspace.ID, codersdk.UpdateWorkspaceAutostartRequest{})
+	require.NoError(t, err)
+	tick(4)
+
+	// Then: re-enabling autostart retries immediately rather than waiting
+	// out the previous backoff.
+	err = client.UpdateWorkspaceAutostart(ctx, workspace.ID, codersdk.UpdateWorkspaceAutostartRequest{
+		Schedule: ptr.Ref(autostartHourly),
+	})
+	require.NoError(t, err)
+	tick(5)
+	assert.EqualValues(t, 4, db.state.attempts.Load())
+}
+
+const autostartHourly = "CRON_TZ=UTC 0 * * * *"
+
+// setupFailingAutostart provisions a stopped workspace with an hourly autostart
+// schedule whose autostart builds always fail. The returned tick runs the
+// executor the given number of minutes after the next scheduled autostart and
+// waits for it to finish.
+func setupFailingAutostart(t *testing.T) (*codersdk.Client, codersdk.Workspace, *failingJobStore, func(minute int)) {
+	t.Helper()
+
+	var (
+		sched   = mustSchedule(t, autostartHourly)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan autobuild.Stats)
+		db      = &failingJobStore{Store: dbfake.New(), state: &failingJobState{}}
+		client  = coderdtest.New(t, &coderdtest.Options{
+			Database:                 db,
+			Pubsub:                   pubsub.NewInMemory(),
+			IncludeProvisionerDaemon: true,
+		})
+		// Given: we have a user with a workspace that has autostart enabled
+		workspace = mustProvisionWorkspace(t, client, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = ptr.Ref(sched.String())
+		})
+	)
+	// Given: workspace is stopped
+	workspace = coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+
+	// Given: every autostart build fails
+	db.state.fail.Store(true)
+
+	// The executor logs failed builds as errors, so run one that tolerates
+	// them.
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	tss := &atomic.Pointer[schedule.TemplateScheduleStore]{}
+	store := schedule.NewAGPLTemplateScheduleStore()
+	tss.Store(&store)
+	executor := autobuild.NewExecutor(ctx, db, tss, slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), tickCh).
+		WithStatsChannel(statsCh)
+	executor.Run()
+	t.Cleanup(executor.Close)
+
+	next := sched.Next(workspace.LatestBuild.CreatedAt)
+	tick := func(minute int) {
+		tickCh <- next.Add(time.Duration(minute) * time.Minute)
+		stats := <-statsCh
+		assert.NoError(t, stats.Error)
+		assert.Empty(t, stats.Transitions)
+	}
+	return client, workspace, db, tick
+}
+
+// failingJobStore fails to insert provisioner jobs when fail is set.
+type failingJobStore struct {
+	database.Store
+	state *failingJobState
+}
+
+type failingJobState struct {
+	fail     atomic.Bool
+	attempts atomic.Int64
+}
+
+func (s *failingJobStore) InTx(fn func(database.Store) error, opts *sql.TxOptions) error {
+	return s.Store.InTx(func(tx database.Store) error {
+		return fn(&failingJobStore{Store: tx, state: s.state})
+	}, opts)
+}
+
+func (s *failingJobStore) InsertProvisionerJob(ctx context.Context, arg database.InsertProvisionerJobParams) (database.ProvisionerJob, error) {
+	if s.state.fail.Load() {
+		s.state.attempts.Add(1)
+		return database.ProvisionerJob{}, xerrors.New("insert provisioner job failed")
+	}
+	return s.Store.InsertProvisionerJob(ctx, arg)
+}
+
+// fakeClock is an autobuild.Clock that only moves when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
 func mustSchedule(t *testing.T, s string) *schedule.Schedule {
 	t.Helper()
 	sched, err := schedule.Weekly(s)
@@ -54,7 +54,7 @@ func TestBatchStats(t *testing.T) {
 	t.Logf("flush 1 completed")
 
 	// Then: it should report no stats.
-	stats, err := store.GetWorkspaceAgentStats(ctx, t1)
+	stats, err := store.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{CreatedAt: t1})
 	require.NoError(t, err, "should not error getting stats")
 	require.Empty(t, stats, "should have no stats for workspace")
 
@@ -71,7 +71,7 @@ func TestBatchStats(t *testing.T) {
 	t.Logf("flush 2 completed")
 
 	// Then: it should report a single stat.
-	stats, err = store.GetWorkspaceAgentStats(ctx, t2)
+	stats, err = store.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{CreatedAt: t2})
 	require.NoError(t, err, "should not error getting stats")
 	require.Len(t, stats, 1, "should have stats for workspace")
 
@@ -100,7 +100,7 @@ func TestBatchStats(t *testing.T) {
 	// And we should finish inserting the stats
 	<-done
 
-	stats, err = store.GetWorkspaceAgentStats(ctx, t3)
+	stats, err = store.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{CreatedAt: t3})
 	require.NoError(t, err, "should not error getting stats")
 	require.Len(t, stats, 2, "should have stats for both workspaces")
 
@@ -119,7 +119,7 @@ func TestBatchStats(t *testing.T) {
 	require.Zero(t, f, "expected zero stats to have been flushed")
 	t.Logf("flush 5 completed")
 
-	stats, err = store.GetWorkspaceAgentStats(ctx, t5)
+	stats, err = store.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{CreatedAt: t5})
 	require.NoError(t, err, "should not error getting stats")
 	require.Len(t, stats, 0, "should have no stats for workspace")
 
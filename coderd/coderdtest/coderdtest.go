@@ -106,6 +106,7 @@ type Options struct {
 	SSHKeygenAlgorithm    gitsshkey.Algorithm
 	AutobuildTicker       <-chan time.Time
 	AutobuildStats        chan<- autobuild.Stats
+	AutobuildClock        autobuild.Clock
 	Auditor               audit.Auditor
 	TLSCertificates       []tls.Certificate
 	GitAuthConfigs        []*gitauth.Config
@@ -270,8 +271,9 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 		&templateScheduleStore,
 		slogtest.Make(t, nil).Named("autobuild.executor").Leveled(slog.LevelDebug),
 		options.AutobuildTicker,
-	).WithStatsChannel(options.AutobuildStats)
+	).WithStatsChannel(options.AutobuildStats).WithClock(options.AutobuildClock)
 	lifecycleExecutor.Run()
+	t.Cleanup(lifecycleExecutor.Close)
 
 	hangDetectorTicker := time.NewTicker(options.DeploymentValues.JobHangDetectorInterval.Value())
 	defer hangDetectorTicker.Stop()
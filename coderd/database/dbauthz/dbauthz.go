@@ -647,6 +647,13 @@ func (q *querier) AcquireProvisionerJob(ctx context.Context, arg database.Acquir
 	return q.db.AcquireProvisionerJob(ctx, arg)
 }
 
+func (q *querier) CancelPendingProvisionerJobs(ctx context.Context, canceledAt time.Time) (int64, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return 0, err
+	}
+	return q.db.CancelPendingProvisionerJobs(ctx, canceledAt)
+}
+
 func (q *querier) CleanTailnetCoordinators(ctx context.Context) error {
 	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceTailnetCoordinator); err != nil {
 		return err
@@ -654,6 +661,13 @@ func (q *querier) CleanTailnetCoordinators(ctx context.Context) error {
 	return q.db.CleanTailnetCoordinators(ctx)
 }
 
+func (q *querier) CountRunningProvisionerJobs(ctx context.Context) (int64, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return 0, err
+	}
+	return q.db.CountRunningProvisionerJobs(ctx)
+}
+
 func (q *querier) DeleteAPIKeyByID(ctx context.Context, id string) error {
 	return deleteQ(q.log, q.auth, q.db.GetAPIKeyByID, q.db.DeleteAPIKeyByID)(ctx, id)
 }
@@ -668,6 +682,15 @@ func (q *querier) DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUID) e
 	return q.db.DeleteAPIKeysByUserID(ctx, userID)
 }
 
+func (q *querier) DeleteAllGroupMembersByUserID(ctx context.Context, userID uuid.UUID) error {
+	// This removes the user from groups in every organization, so the caller
+	// must be able to update any group in the deployment.
+	fetch := func(context.Context, uuid.UUID) (rbac.Objecter, error) {
+		return rbac.ResourceGroup, nil
+	}
+	return update(q.log, q.auth, fetch, q.db.DeleteAllGroupMembersByUserID)(ctx, userID)
+}
+
 func (q *querier) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error {
 	// TODO: This is not 100% correct because it omits apikey IDs.
 	err := q.authorizeContext(ctx, rbac.ActionDelete,
@@ -784,6 +807,13 @@ func (q *querier) GetActiveUserCount(ctx context.Context) (int64, error) {
 	return q.db.GetActiveUserCount(ctx)
 }
 
+func (q *querier) GetActiveUserCountSince(ctx context.Context, since time.Time) (int64, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return 0, err
+	}
+	return q.db.GetActiveUserCountSince(ctx, since)
+}
+
 func (q *querier) GetAllTailnetAgents(ctx context.Context) ([]database.TailnetAgent, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceTailnetCoordinator); err != nil {
 		return []database.TailnetAgent{}, err
@@ -820,6 +850,13 @@ func (q *querier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUI
 	return q.db.GetAuthorizationUserRoles(ctx, userID)
 }
 
+func (q *querier) GetAuthorizationUserRolesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetAuthorizationUserRolesByIDsRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetAuthorizationUserRolesByIDs(ctx, ids)
+}
+
 func (q *querier) GetDERPMeshKey(ctx context.Context) (string, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
 		return "", err
@@ -832,6 +869,13 @@ func (q *querier) GetDefaultProxyConfig(ctx context.Context) (database.GetDefaul
 	return q.db.GetDefaultProxyConfig(ctx)
 }
 
+func (q *querier) GetDeletedTemplates(ctx context.Context) ([]database.Template, error) {
+	fetch := func(ctx context.Context, _ interface{}) ([]database.Template, error) {
+		return q.db.GetDeletedTemplates(ctx)
+	}
+	return fetchWithPostFilter(q.auth, fetch)(ctx, nil)
+}
+
 // Only used by metrics cache.
 func (q *querier) GetDeploymentDAUs(ctx context.Context, tzOffset int32) ([]database.GetDeploymentDAUsRow, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
@@ -849,8 +893,8 @@ func (q *querier) GetDeploymentWorkspaceAgentStats(ctx context.Context, createdA
 	return q.db.GetDeploymentWorkspaceAgentStats(ctx, createdAfter)
 }
 
-func (q *querier) GetDeploymentWorkspaceStats(ctx context.Context) (database.GetDeploymentWorkspaceStatsRow, error) {
-	return q.db.GetDeploymentWorkspaceStats(ctx)
+func (q *querier) GetDeploymentWorkspaceStats(ctx context.Context, buildingWindowSeconds int32) (database.GetDeploymentWorkspaceStatsRow, error) {
+	return q.db.GetDeploymentWorkspaceStats(ctx, buildingWindowSeconds)
 }
 
 func (q *querier) GetFileByHashAndCreator(ctx context.Context, arg database.GetFileByHashAndCreatorParams) (database.File, error) {
@@ -915,8 +959,8 @@ func (q *querier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]dat
 	return q.db.GetGroupMembers(ctx, groupID)
 }
 
-func (q *querier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]database.Group, error) {
-	return fetchWithPostFilter(q.auth, q.db.GetGroupsByOrganizationID)(ctx, organizationID)
+func (q *querier) GetGroupsByOrganizationID(ctx context.Context, arg database.GetGroupsByOrganizationIDParams) ([]database.Group, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetGroupsByOrganizationID)(ctx, arg)
 }
 
 // TODO: We need to create a ProvisionerJob resource type
@@ -1086,6 +1130,15 @@ func (q *querier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (data
 	return job, nil
 }
 
+func (q *querier) GetProvisionerJobWithLogTail(ctx context.Context, arg database.GetProvisionerJobWithLogTailParams) (database.ProvisionerJobWithLogTail, error) {
+	// Authorized read on job lets the actor also read the logs.
+	_, err := q.GetProvisionerJobByID(ctx, arg.ID)
+	if err != nil {
+		return database.ProvisionerJobWithLogTail{}, err
+	}
+	return q.db.GetProvisionerJobWithLogTail(ctx, arg)
+}
+
 // TODO: we need to add a provisioner job resource
 func (q *querier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	// if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
@@ -1100,11 +1153,11 @@ func (q *querier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Context,
 }
 
 // TODO: We need to create a ProvisionerJob resource type
-func (q *querier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]database.ProvisionerJob, error) {
+func (q *querier) GetProvisionerJobsCreatedAfter(ctx context.Context, arg database.GetProvisionerJobsCreatedAfterParams) ([]database.ProvisionerJob, error) {
 	// if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
 	// return nil, err
 	// }
-	return q.db.GetProvisionerJobsCreatedAfter(ctx, createdAt)
+	return q.db.GetProvisionerJobsCreatedAfter(ctx, arg)
 }
 
 func (q *querier) GetProvisionerLogsAfterID(ctx context.Context, arg database.GetProvisionerLogsAfterIDParams) ([]database.ProvisionerJobLog, error) {
@@ -1124,12 +1177,20 @@ func (q *querier) GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID
 	return q.db.GetQuotaAllowanceForUser(ctx, userID)
 }
 
-func (q *querier) GetQuotaConsumedForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
-	err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserObject(userID))
+func (q *querier) GetQuotaConsumedForUser(ctx context.Context, arg database.GetQuotaConsumedForUserParams) (int64, error) {
+	err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserObject(arg.OwnerID))
 	if err != nil {
 		return -1, err
 	}
-	return q.db.GetQuotaConsumedForUser(ctx, userID)
+	return q.db.GetQuotaConsumedForUser(ctx, arg)
+}
+
+func (q *querier) GetQuotaConsumedForUserIncludingPending(ctx context.Context, arg database.GetQuotaConsumedForUserIncludingPendingParams) (database.GetQuotaConsumedForUserIncludingPendingRow, error) {
+	err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserObject(arg.OwnerID))
+	if err != nil {
+		return database.GetQuotaConsumedForUserIncludingPendingRow{}, err
+	}
+	return q.db.GetQuotaConsumedForUserIncludingPending(ctx, arg)
 }
 
 func (q *querier) GetReplicaByID(ctx context.Context, id uuid.UUID) (database.Replica, error) {
@@ -1165,6 +1226,13 @@ func (q *querier) GetTailnetClientsForAgent(ctx context.Context, agentID uuid.UU
 	return q.db.GetTailnetClientsForAgent(ctx, agentID)
 }
 
+func (q *querier) GetTemplateAndDeploymentDAUs(ctx context.Context, arg database.GetTemplateAndDeploymentDAUsParams) ([]database.GetTemplateAndDeploymentDAUsRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateAndDeploymentDAUs(ctx, arg)
+}
+
 // Only used by metrics cache.
 func (q *querier) GetTemplateAverageBuildTime(ctx context.Context, arg database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
@@ -1344,6 +1412,14 @@ func (q *querier) GetTemplateVersionVariables(ctx context.Context, templateVersi
 	return q.db.GetTemplateVersionVariables(ctx, templateVersionID)
 }
 
+func (q *querier) GetTemplateVersionVariablesRedacted(ctx context.Context, templateVersionID uuid.UUID) ([]database.GetTemplateVersionVariablesRedactedRow, error) {
+	// Authorized the same as reading the unredacted variables.
+	if _, err := q.GetTemplateVersionVariables(ctx, templateVersionID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionVariablesRedacted(ctx, templateVersionID)
+}
+
 // GetTemplateVersionsByIDs is only used for workspace build data.
 // The workspace is already fetched.
 func (q *querier) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.TemplateVersion, error) {
@@ -1375,6 +1451,15 @@ func (q *querier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt
 	return q.db.GetTemplateVersionsCreatedAfter(ctx, createdAt)
 }
 
+// GetTemplateVersionsWithStatusByIDs mirrors GetTemplateVersionsByIDs and
+// requires system level read access.
+func (q *querier) GetTemplateVersionsWithStatusByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetTemplateVersionsWithStatusByIDsRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionsWithStatusByIDs(ctx, ids)
+}
+
 func (q *querier) GetTemplates(ctx context.Context) ([]database.Template, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
 		return nil, err
@@ -1482,6 +1567,13 @@ func (q *querier) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (data
 	return q.db.GetWorkspaceAgentByID(ctx, id)
 }
 
+func (q *querier) GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx context.Context, id uuid.UUID) (database.WorkspaceAgent, error) {
+	if _, err := q.GetWorkspaceByAgentID(ctx, id); err != nil {
+		return database.WorkspaceAgent{}, err
+	}
+	return q.db.GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx, id)
+}
+
 // GetWorkspaceAgentByInstanceID might want to be a system call? Unsure exactly,
 // but this will fail. Need to figure out what AuthInstanceID is, and if it
 // is essentially an auth token. But the caller using this function is not
@@ -1528,8 +1620,8 @@ func (q *querier) GetWorkspaceAgentMetadata(ctx context.Context, workspaceAgentI
 	return q.db.GetWorkspaceAgentMetadata(ctx, workspaceAgentID)
 }
 
-func (q *querier) GetWorkspaceAgentStats(ctx context.Context, createdAfter time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
-	return q.db.GetWorkspaceAgentStats(ctx, createdAfter)
+func (q *querier) GetWorkspaceAgentStats(ctx context.Context, arg database.GetWorkspaceAgentStatsParams) ([]database.GetWorkspaceAgentStatsRow, error) {
+	return q.db.GetWorkspaceAgentStats(ctx, arg)
 }
 
 func (q *querier) GetWorkspaceAgentStatsAndLabels(ctx context.Context, createdAfter time.Time) ([]database.GetWorkspaceAgentStatsAndLabelsRow, error) {
@@ -1552,13 +1644,20 @@ func (q *querier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, createdAt
 	return q.db.GetWorkspaceAgentsCreatedAfter(ctx, createdAt)
 }
 
-func (q *querier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]database.WorkspaceAgent, error) {
-	workspace, err := q.GetWorkspaceByID(ctx, workspaceID)
+func (q *querier) GetWorkspaceAgentsCreatedAfterWithWorkspace(ctx context.Context, createdAt time.Time) ([]database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceAgentsCreatedAfterWithWorkspace(ctx, createdAt)
+}
+
+func (q *querier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, arg database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams) ([]database.WorkspaceAgent, error) {
+	_, err := q.GetWorkspaceByID(ctx, arg.WorkspaceID)
 	if err != nil {
 		return nil, err
 	}
 
-	return q.db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, workspace.ID)
+	return q.db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, arg)
 }
 
 func (q *querier) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg database.GetWorkspaceAppByAgentIDAndSlugParams) (database.WorkspaceApp, error) {
@@ -1624,6 +1723,17 @@ func (q *querier) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.Conte
 	return q.db.GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx, arg)
 }
 
+func (q *querier) GetWorkspaceBuildParameterByName(ctx context.Context, arg database.GetWorkspaceBuildParameterByNameParams) (database.WorkspaceBuildParameter, error) {
+	// Authorized call to get the workspace build. If we can read the build,
+	// we can read the param.
+	_, err := q.GetWorkspaceBuildByID(ctx, arg.WorkspaceBuildID)
+	if err != nil {
+		return database.WorkspaceBuildParameter{}, err
+	}
+
+	return q.db.GetWorkspaceBuildParameterByName(ctx, arg)
+}
+
 func (q *querier) GetWorkspaceBuildParameters(ctx context.Context, workspaceBuildID uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
 	// Authorized call to get the workspace build. If we can read the build,
 	// we can read the params.
@@ -1635,6 +1745,24 @@ func (q *querier) GetWorkspaceBuildParameters(ctx context.Context, workspaceBuil
 	return q.db.GetWorkspaceBuildParameters(ctx, workspaceBuildID)
 }
 
+func (q *querier) GetWorkspaceBuildParametersByBuildIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceBuildParametersByBuildIDs(ctx, ids)
+}
+
+func (q *querier) GetWorkspaceBuildWithTemplateVersionByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceBuildWithTemplateVersionByIDRow, error) {
+	row, err := q.db.GetWorkspaceBuildWithTemplateVersionByID(ctx, id)
+	if err != nil {
+		return database.GetWorkspaceBuildWithTemplateVersionByIDRow{}, err
+	}
+	if _, err := q.GetWorkspaceByID(ctx, row.WorkspaceBuild.WorkspaceID); err != nil {
+		return database.GetWorkspaceBuildWithTemplateVersionByIDRow{}, err
+	}
+	return row, nil
+}
+
 func (q *querier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg database.GetWorkspaceBuildsByWorkspaceIDParams) ([]database.WorkspaceBuild, error) {
 	if _, err := q.GetWorkspaceByID(ctx, arg.WorkspaceID); err != nil {
 		return nil, err
@@ -1645,11 +1773,11 @@ func (q *querier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg datab
 // Telemetry related functions. These functions are system functions for returning
 // telemetry data. Never called by a user.
 
-func (q *querier) GetWorkspaceBuildsCreatedAfter(ctx context.Context, createdAt time.Time) ([]database.WorkspaceBuild, error) {
+func (q *querier) GetWorkspaceBuildsCreatedAfter(ctx context.Context, arg database.GetWorkspaceBuildsCreatedAfterParams) ([]database.WorkspaceBuild, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
 		return nil, err
 	}
-	return q.db.GetWorkspaceBuildsCreatedAfter(ctx, createdAt)
+	return q.db.GetWorkspaceBuildsCreatedAfter(ctx, arg)
 }
 
 func (q *querier) GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUID) (database.Workspace, error) {
@@ -1668,10 +1796,15 @@ func (q *querier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspaceApp
 	return fetch(q.log, q.auth, q.db.GetWorkspaceByWorkspaceAppID)(ctx, workspaceAppID)
 }
 
-func (q *querier) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
-	return fetchWithPostFilter(q.auth, func(ctx context.Context, _ interface{}) ([]database.WorkspaceProxy, error) {
-		return q.db.GetWorkspaceProxies(ctx)
-	})(ctx, nil)
+func (q *querier) GetWorkspaceCountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceWorkspace.WithOwner(ownerID.String())); err != nil {
+		return 0, err
+	}
+	return q.db.GetWorkspaceCountByOwner(ctx, ownerID)
+}
+
+func (q *querier) GetWorkspaceProxies(ctx context.Context, search string) ([]database.WorkspaceProxy, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetWorkspaceProxies)(ctx, search)
 }
 
 func (q *querier) GetWorkspaceProxyByHostname(ctx context.Context, params database.GetWorkspaceProxyByHostnameParams) (database.WorkspaceProxy, error) {
@@ -1689,6 +1822,10 @@ func (q *querier) GetWorkspaceProxyByName(ctx context.Context, name string) (dat
 	return fetch(q.log, q.auth, q.db.GetWorkspaceProxyByName)(ctx, name)
 }
 
+func (q *querier) GetWorkspaceProxyByTokenHash(ctx context.Context, hash []byte) (database.WorkspaceProxy, error) {
+	return fetch(q.log, q.auth, q.db.GetWorkspaceProxyByTokenHash)(ctx, hash)
+}
+
 func (q *querier) GetWorkspaceResourceByID(ctx context.Context, id uuid.UUID) (database.WorkspaceResource, error) {
 	// TODO: Optimize this
 	resource, err := q.db.GetWorkspaceResourceByID(ctx, id)
@@ -2002,6 +2139,16 @@ func (q *querier) InsertWorkspaceAgentMetadata(ctx context.Context, arg database
 	return q.db.InsertWorkspaceAgentMetadata(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceAgentMetadataBatch(ctx context.Context, arg database.InsertWorkspaceAgentMetadataBatchParams) error {
+	// We don't check for workspace ownership here since the agent metadata may
+	// be associated with an orphaned agent used by a dry run build.
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+
+	return q.db.InsertWorkspaceAgentMetadataBatch(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceAgentStat(ctx context.Context, arg database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	// TODO: This is a workspace agent operation. Should users be able to query this?
 	// Not really sure what this is for.
@@ -2154,6 +2301,13 @@ func (q *querier) UpdateMemberRoles(ctx context.Context, arg database.UpdateMemb
 	return q.db.UpdateMemberRoles(ctx, arg)
 }
 
+func (q *querier) UpdateProvisionerDaemonLastSeenAt(ctx context.Context, arg database.UpdateProvisionerDaemonLastSeenAtParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateProvisionerDaemonLastSeenAt(ctx, arg)
+}
+
 // TODO: We need to create a ProvisionerJob resource type
 func (q *querier) UpdateProvisionerJobByID(ctx context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	// if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
@@ -2726,6 +2880,14 @@ func (q *querier) GetAuthorizedWorkspaces(ctx context.Context, arg database.GetW
 	return q.GetWorkspaces(ctx, arg)
 }
 
+func (q *querier) GetWorkspaceAgentLogsAfterWithGap(ctx context.Context, arg database.GetWorkspaceAgentLogsAfterParams) (database.WorkspaceAgentLogsAfter, error) {
+	_, err := q.GetWorkspaceAgentByID(ctx, arg.AgentID)
+	if err != nil {
+		return database.WorkspaceAgentLogsAfter{}, err
+	}
+	return q.db.GetWorkspaceAgentLogsAfterWithGap(ctx, arg)
+}
+
 // GetAuthorizedUsers is not required for dbauthz since GetUsers is already
 // authenticated.
 func (q *querier) GetAuthorizedUsers(ctx context.Context, arg database.GetUsersParams, _ rbac.PreparedAuthorized) ([]database.GetUsersRow, error) {
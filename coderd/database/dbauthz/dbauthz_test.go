@@ -20,6 +20,7 @@ import (
 	"github.com/coder/coder/coderd/database/dbgen"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/util/slice"
+	"github.com/coder/coder/codersdk"
 )
 
 func TestAsNoActor(t *testing.T) {
@@ -287,7 +288,7 @@ func (s *MethodTestSuite) TestGroup() {
 	}))
 	s.Run("GetGroupMembers", s.Subtest(func(db database.Store, check *expects) {
 		g := dbgen.Group(s.T(), db, database.Group{})
-		_ = dbgen.GroupMember(s.T(), db, database.GroupMember{})
+		_ = dbgen.GroupMember(s.T(), db, database.GroupMember{GroupID: g.ID})
 		check.Args(g.ID).Asserts(g, rbac.ActionRead)
 	}))
 	s.Run("InsertAllUsersGroup", s.Subtest(func(db database.Store, check *expects) {
@@ -332,6 +333,16 @@ func (s *MethodTestSuite) TestGroup() {
 			UserID:         u1.ID,
 		}).Asserts(rbac.ResourceGroup.InOrg(o.ID), rbac.ActionUpdate).Returns()
 	}))
+	s.Run("DeleteAllGroupMembersByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u1 := dbgen.User(s.T(), db, database.User{})
+		o1 := dbgen.Organization(s.T(), db, database.Organization{})
+		o2 := dbgen.Organization(s.T(), db, database.Organization{})
+		g1 := dbgen.Group(s.T(), db, database.Group{OrganizationID: o1.ID})
+		g2 := dbgen.Group(s.T(), db, database.Group{OrganizationID: o2.ID})
+		_ = dbgen.GroupMember(s.T(), db, database.GroupMember{GroupID: g1.ID, UserID: u1.ID})
+		_ = dbgen.GroupMember(s.T(), db, database.GroupMember{GroupID: g2.ID, UserID: u1.ID})
+		check.Args(u1.ID).Asserts(rbac.ResourceGroup, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("UpdateGroupByID", s.Subtest(func(db database.Store, check *expects) {
 		g := dbgen.Group(s.T(), db, database.Group{})
 		check.Args(database.UpdateGroupByIDParams{
@@ -433,6 +444,16 @@ func (s *MethodTestSuite) TestProvsionerJob() {
 		b := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
 		check.Args([]uuid.UUID{a.ID, b.ID}).Asserts().Returns(slice.New(a, b))
 	}))
+	s.Run("CancelPendingProvisionerJobs", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
+		check.Args(database.Now()).Asserts(rbac.ResourceSystem, rbac.ActionUpdate).Returns(int64(1))
+	}))
+	s.Run("CountRunningProvisionerJobs", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{
+			StartedAt: sql.NullTime{Time: database.Now(), Valid: true},
+		})
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(int64(1))
+	}))
 	s.Run("GetProvisionerLogsAfterID", s.Subtest(func(db database.Store, check *expects) {
 		w := dbgen.Workspace(s.T(), db, database.Workspace{})
 		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{
@@ -443,6 +464,20 @@ func (s *MethodTestSuite) TestProvsionerJob() {
 			JobID: j.ID,
 		}).Asserts(w, rbac.ActionRead).Returns([]database.ProvisionerJobLog{})
 	}))
+	s.Run("GetProvisionerJobWithLogTail", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Workspace(s.T(), db, database.Workspace{})
+		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{
+			Type: database.ProvisionerJobTypeWorkspaceBuild,
+		})
+		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{JobID: j.ID, WorkspaceID: w.ID})
+		check.Args(database.GetProvisionerJobWithLogTailParams{
+			ID:        j.ID,
+			TailLines: 10,
+		}).Asserts(w, rbac.ActionRead).Returns(database.ProvisionerJobWithLogTail{
+			ProvisionerJob: j,
+			Logs:           []database.ProvisionerJobLog{},
+		})
+	}))
 }
 
 func (s *MethodTestSuite) TestLicense() {
@@ -504,7 +539,9 @@ func (s *MethodTestSuite) TestOrganization() {
 		o := dbgen.Organization(s.T(), db, database.Organization{})
 		a := dbgen.Group(s.T(), db, database.Group{OrganizationID: o.ID})
 		b := dbgen.Group(s.T(), db, database.Group{OrganizationID: o.ID})
-		check.Args(o.ID).Asserts(a, rbac.ActionRead, b, rbac.ActionRead).
+		check.Args(database.GetGroupsByOrganizationIDParams{
+			OrganizationID: o.ID,
+		}).Asserts(a, rbac.ActionRead, b, rbac.ActionRead).
 			Returns([]database.Group{a, b})
 	}))
 	s.Run("GetOrganizationByID", s.Subtest(func(db database.Store, check *expects) {
@@ -606,6 +643,10 @@ func (s *MethodTestSuite) TestWorkspaceProxy() {
 		p, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
 		check.Args(p.ID).Asserts(p, rbac.ActionRead).Returns(p)
 	}))
+	s.Run("GetWorkspaceProxyByTokenHash", s.Subtest(func(db database.Store, check *expects) {
+		p, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
+		check.Args(p.TokenHashedSecret).Asserts(p, rbac.ActionRead).Returns(p)
+	}))
 	s.Run("UpdateWorkspaceProxyDeleted", s.Subtest(func(db database.Store, check *expects) {
 		p, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
 		check.Args(database.UpdateWorkspaceProxyDeletedParams{
@@ -614,9 +655,10 @@ func (s *MethodTestSuite) TestWorkspaceProxy() {
 		}).Asserts(p, rbac.ActionDelete)
 	}))
 	s.Run("GetWorkspaceProxies", s.Subtest(func(db database.Store, check *expects) {
-		p1, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
-		p2, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
-		check.Args().Asserts(p1, rbac.ActionRead, p2, rbac.ActionRead).Returns(slice.New(p1, p2))
+		// Proxies are returned sorted by name.
+		p1, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{Name: "a-proxy"})
+		p2, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{Name: "b-proxy"})
+		check.Args("").Asserts(p1, rbac.ActionRead, p2, rbac.ActionRead).Returns(slice.New(p1, p2))
 	}))
 }
 
@@ -652,6 +694,19 @@ func (s *MethodTestSuite) TestTemplate() {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		check.Args(t1.ID).Asserts(t1, rbac.ActionRead).Returns(t1)
 	}))
+	s.Run("GetDeletedTemplates", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		_ = dbgen.Template(s.T(), db, database.Template{})
+		err := db.UpdateTemplateDeletedByID(context.Background(), database.UpdateTemplateDeletedByIDParams{
+			ID:        t1.ID,
+			Deleted:   true,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(s.T(), err)
+		t1, err = db.GetTemplateByID(context.Background(), t1.ID)
+		require.NoError(s.T(), err)
+		check.Args().Asserts(t1, rbac.ActionRead).Returns(slice.New(t1))
+	}))
 	s.Run("GetTemplateByOrganizationAndName", s.Subtest(func(db database.Store, check *expects) {
 		o1 := dbgen.Organization(s.T(), db, database.Organization{})
 		t1 := dbgen.Template(s.T(), db, database.Template{
@@ -696,6 +751,18 @@ func (s *MethodTestSuite) TestTemplate() {
 		})
 		check.Args(tv.ID).Asserts(t1, rbac.ActionRead).Returns([]database.TemplateVersionVariable{tvv1})
 	}))
+	s.Run("GetTemplateVersionVariablesRedacted", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		tvv1 := dbgen.TemplateVersionVariable(s.T(), db, database.TemplateVersionVariable{
+			TemplateVersionID: tv.ID,
+		})
+		check.Args(tv.ID).Asserts(t1, rbac.ActionRead).Returns([]database.GetTemplateVersionVariablesRedactedRow{
+			database.GetTemplateVersionVariablesRedactedRow(tvv1),
+		})
+	}))
 	s.Run("GetTemplateGroupRoles", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		check.Args(t1.ID).Asserts(t1, rbac.ActionUpdate)
@@ -849,7 +916,19 @@ func (s *MethodTestSuite) TestUser() {
 	}))
 	s.Run("GetQuotaConsumedForUser", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
-		check.Args(u.ID).Asserts(u, rbac.ActionRead).Returns(int64(0))
+		check.Args(database.GetQuotaConsumedForUserParams{
+			OwnerID: u.ID,
+		}).Asserts(u, rbac.ActionRead).Returns(int64(0))
+	}))
+	s.Run("GetQuotaConsumedForUserIncludingPending", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.GetQuotaConsumedForUserIncludingPendingParams{
+			OwnerID:          u.ID,
+			PendingDailyCost: 1,
+		}).Asserts(u, rbac.ActionRead).Returns(database.GetQuotaConsumedForUserIncludingPendingRow{
+			Consumed:         1,
+			ExceedsAllowance: true,
+		})
 	}))
 	s.Run("GetUserByEmailOrUsername", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
@@ -1031,6 +1110,13 @@ func (s *MethodTestSuite) TestWorkspace() {
 		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
 		check.Args(agt.ID).Asserts(ws, rbac.ActionRead).Returns(agt)
 	}))
+	s.Run("GetWorkspaceAgentByIDExcludeDeletedWorkspace", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(agt.ID).Asserts(ws, rbac.ActionRead).Returns(agt)
+	}))
 	s.Run("GetWorkspaceAgentByInstanceID", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
@@ -1077,6 +1163,17 @@ func (s *MethodTestSuite) TestWorkspace() {
 			AgentID: agt.ID,
 		}).Asserts(ws, rbac.ActionRead).Returns([]database.WorkspaceAgentLog{})
 	}))
+	s.Run("GetWorkspaceAgentLogsAfterWithGap", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.GetWorkspaceAgentLogsAfterParams{
+			AgentID: agt.ID,
+		}).Asserts(ws, rbac.ActionRead).Returns(database.WorkspaceAgentLogsAfter{
+			Logs: []database.WorkspaceAgentLog{},
+		})
+	}))
 	s.Run("GetWorkspaceAppByAgentIDAndSlug", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
@@ -1104,6 +1201,24 @@ func (s *MethodTestSuite) TestWorkspace() {
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
 		check.Args(build.ID).Asserts(ws, rbac.ActionRead).Returns(build)
 	}))
+	s.Run("GetWorkspaceAgentsInLatestBuildByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams{
+			WorkspaceID: ws.ID,
+		}).Asserts(ws, rbac.ActionRead).Returns([]database.WorkspaceAgent{agt})
+	}))
+	s.Run("GetWorkspaceBuildWithTemplateVersionByID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, TemplateVersionID: tv.ID})
+		check.Args(build.ID).Asserts(ws, rbac.ActionRead).Returns(database.GetWorkspaceBuildWithTemplateVersionByIDRow{
+			WorkspaceBuild:      build,
+			TemplateVersionName: tv.Name,
+		})
+	}))
 	s.Run("GetWorkspaceBuildByJobID", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
@@ -1117,12 +1232,36 @@ func (s *MethodTestSuite) TestWorkspace() {
 			BuildNumber: build.BuildNumber,
 		}).Asserts(ws, rbac.ActionRead).Returns(build)
 	}))
+	s.Run("GetWorkspaceBuildParameterByName", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
+		require.NoError(s.T(), db.InsertWorkspaceBuildParameters(context.Background(), database.InsertWorkspaceBuildParametersParams{
+			WorkspaceBuildID: build.ID,
+			Name:             []string{"region"},
+			Value:            []string{"us-east"},
+		}))
+		check.Args(database.GetWorkspaceBuildParameterByNameParams{
+			WorkspaceBuildID: build.ID,
+			Name:             "region",
+		}).Asserts(ws, rbac.ActionRead).Returns(database.WorkspaceBuildParameter{
+			WorkspaceBuildID: build.ID,
+			Name:             "region",
+			Value:            "us-east",
+		})
+	}))
 	s.Run("GetWorkspaceBuildParameters", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
 		check.Args(build.ID).Asserts(ws, rbac.ActionRead).
 			Returns([]database.WorkspaceBuildParameter{})
 	}))
+	s.Run("GetWorkspaceBuildParametersByBuildIDs", s.Subtest(func(db database.Store, check *expects) {
+		a := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{})
+		b := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{})
+		check.Args([]uuid.UUID{a.ID, b.ID}).
+			Asserts(rbac.ResourceSystem, rbac.ActionRead).
+			Returns([]database.WorkspaceBuildParameter{})
+	}))
 	s.Run("GetWorkspaceBuildsByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, BuildNumber: 1})
@@ -1271,6 +1410,11 @@ func (s *MethodTestSuite) TestWorkspace() {
 		app := dbgen.WorkspaceApp(s.T(), db, database.WorkspaceApp{AgentID: agt.ID})
 		check.Args(app.ID).Asserts(ws, rbac.ActionRead).Returns(ws)
 	}))
+	s.Run("GetWorkspaceCountByOwner", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		_ = dbgen.Workspace(s.T(), db, database.Workspace{OwnerID: u.ID})
+		check.Args(u.ID).Asserts(rbac.ResourceWorkspace.WithOwner(u.ID.String()), rbac.ActionRead).Returns(int64(1))
+	}))
 }
 
 func (s *MethodTestSuite) TestExtraMethods() {
@@ -1293,6 +1437,10 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			LoginType: database.LoginTypeGithub,
 		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate).Returns(l)
 	}))
+	s.Run("GetTemplateAndDeploymentDAUs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetTemplateAndDeploymentDAUsParams{TemplateID: uuid.New()}).
+			Asserts(rbac.ResourceSystem, rbac.ActionRead)
+	}))
 	s.Run("GetLatestWorkspaceBuildsByWorkspaceIDs", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		b := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
@@ -1324,6 +1472,9 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("GetActiveUserCount", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(int64(0))
 	}))
+	s.Run("GetActiveUserCountSince", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(int64(0))
+	}))
 	s.Run("GetUnexpiredLicenses", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead)
 	}))
@@ -1331,6 +1482,11 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(rbac.ResourceSystem, rbac.ActionRead)
 	}))
+	s.Run("GetAuthorizationUserRolesByIDs", s.Subtest(func(db database.Store, check *expects) {
+		a := dbgen.User(s.T(), db, database.User{})
+		b := dbgen.User(s.T(), db, database.User{})
+		check.Args([]uuid.UUID{a.ID, b.ID}).Asserts(rbac.ResourceSystem, rbac.ActionRead)
+	}))
 	s.Run("GetDERPMeshKey", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead)
 	}))
@@ -1382,6 +1538,9 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("UpsertLastUpdateCheck", s.Subtest(func(db database.Store, check *expects) {
 		check.Args("value").Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
 	}))
+	s.Run("GetDeploymentWorkspaceStats", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(int32(0)).Asserts()
+	}))
 	s.Run("GetLastUpdateCheck", s.Subtest(func(db database.Store, check *expects) {
 		err := db.UpsertLastUpdateCheck(context.Background(), "value")
 		require.NoError(s.T(), err)
@@ -1389,12 +1548,27 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	}))
 	s.Run("GetWorkspaceBuildsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{CreatedAt: time.Now().Add(-time.Hour)})
-		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionRead)
+		check.Args(database.GetWorkspaceBuildsCreatedAfterParams{CreatedAt: time.Now()}).Asserts(rbac.ResourceSystem, rbac.ActionRead)
 	}))
 	s.Run("GetWorkspaceAgentsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionRead)
 	}))
+	s.Run("GetWorkspaceAgentsCreatedAfterWithWorkspace", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{CreatedAt: time.Now().Add(-time.Hour)})
+		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionRead)
+	}))
+	s.Run("InsertWorkspaceAgentMetadataBatch", s.Subtest(func(db database.Store, check *expects) {
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{})
+		check.Args(database.InsertWorkspaceAgentMetadataBatchParams{
+			WorkspaceAgentID: agt.ID,
+			DisplayName:      []string{"CPU"},
+			Key:              []string{"cpu"},
+			Script:           []string{"echo 1"},
+			Timeout:          []int64{1},
+			Interval:         []int64{10},
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate)
+	}))
 	s.Run("GetWorkspaceAppsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.WorkspaceApp(s.T(), db, database.WorkspaceApp{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionRead)
@@ -1413,7 +1587,7 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("GetProvisionerJobsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		// TODO: add provisioner job resource type
 		_ = dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{CreatedAt: time.Now().Add(-time.Hour)})
-		check.Args(time.Now()).Asserts( /*rbac.ResourceSystem, rbac.ActionRead*/ )
+		check.Args(database.GetProvisionerJobsCreatedAfterParams{CreatedAt: time.Now()}).Asserts( /*rbac.ResourceSystem, rbac.ActionRead*/ )
 	}))
 	s.Run("GetTemplateVersionsByIDs", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
@@ -1431,6 +1605,16 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			Asserts(rbac.ResourceSystem, rbac.ActionRead).
 			Returns(slice.New(tv1, tv2, tv3))
 	}))
+	s.Run("GetTemplateVersionsWithStatusByIDs", s.Subtest(func(db database.Store, check *expects) {
+		job := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{JobID: job.ID})
+		check.Args([]uuid.UUID{tv.ID}).
+			Asserts(rbac.ResourceSystem, rbac.ActionRead).
+			Returns([]database.GetTemplateVersionsWithStatusByIDsRow{{
+				TemplateVersion: tv,
+				JobStatus:       string(codersdk.ProvisionerJobPending),
+			}})
+	}))
 	s.Run("GetWorkspaceAppsByAgentIDs", s.Subtest(func(db database.Store, check *expects) {
 		aWs := dbgen.Workspace(s.T(), db, database.Workspace{})
 		aBuild := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: aWs.ID, JobID: uuid.New()})
@@ -1558,6 +1742,16 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			ID: uuid.New(),
 		}).Asserts( /*rbac.ResourceSystem, rbac.ActionCreate*/ )
 	}))
+	s.Run("UpdateProvisionerDaemonLastSeenAt", s.Subtest(func(db database.Store, check *expects) {
+		d, err := db.InsertProvisionerDaemon(context.Background(), database.InsertProvisionerDaemonParams{
+			ID: uuid.New(),
+		})
+		s.NoError(err, "insert provisioner daemon")
+		check.Args(database.UpdateProvisionerDaemonLastSeenAtParams{
+			ID:         d.ID,
+			LastSeenAt: sql.NullTime{Time: database.Now(), Valid: true},
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
 	s.Run("InsertTemplateVersionParameter", s.Subtest(func(db database.Store, check *expects) {
 		v := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{})
 		check.Args(database.InsertTemplateVersionParameterParams{
@@ -1,16 +1,19 @@
 package dbfake
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -35,6 +38,11 @@ var errDuplicateKey = &pq.Error{
 	Message: "duplicate key value violates unique constraint",
 }
 
+var errForeignKeyConstraint = &pq.Error{
+	Code:    "23503",
+	Message: "insert or update violates foreign key constraint",
+}
+
 // New returns an in-memory fake of the database.
 func New() database.Store {
 	q := &FakeQuerier{
@@ -96,6 +104,32 @@ type FakeQuerier struct {
 	*data
 }
 
+// SetMaxWorkspaceAgentLogLineLength sets the maximum length in bytes of a
+// single line passed to InsertWorkspaceAgentLogs. Zero disables the check.
+// TemplateLookups returns the number of times templates have been read by
+// queries, where loading many templates at once counts as a single lookup.
+func (q *FakeQuerier) TemplateLookups() int64 {
+	return q.templateLookups.Load()
+}
+
+func (q *FakeQuerier) SetMaxWorkspaceAgentLogLineLength(n int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.maxWorkspaceAgentLogLineLength = n
+}
+
+// SetValidateProvisionerJobInput enables checking that the Input passed to
+// InsertProvisionerJob is a JSON object containing the keys required by the
+// job type. It is disabled by default because many tests insert jobs with
+// placeholder input.
+func (q *FakeQuerier) SetValidateProvisionerJobInput(validate bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.validateProvisionerJobInput = validate
+}
+
 func (*FakeQuerier) Wrappers() []string {
 	return []string{}
 }
@@ -152,8 +186,41 @@ type data struct {
 	appSecurityKey          string
 	oauthSigningKey         string
 	lastLicenseID           int32
+	lastWorkspaceAgentLogID int64
 	defaultProxyDisplayName string
 	defaultProxyIconURL     string
+
+	// maxWorkspaceAgentLogLineLength is the maximum length of a single
+	// workspace agent log line in bytes. Zero means there is no limit.
+	maxWorkspaceAgentLogLineLength int
+	// validateProvisionerJobInput enables validation of provisioner job
+	// input on insert.
+	validateProvisionerJobInput bool
+	// templateLookups counts reads of the templates table so tests can
+	// assert how often queries look templates up.
+	templateLookups atomic.Int64
+}
+
+// provisionerJobInputRequiredKeys lists the input keys each job type must
+// set. They mirror the JSON tags of the job input structs in
+// provisionerdserver.
+var provisionerJobInputRequiredKeys = map[database.ProvisionerJobType][]string{
+	database.ProvisionerJobTypeTemplateVersionImport: {"template_version_id"},
+	database.ProvisionerJobTypeTemplateVersionDryRun: {"template_version_id"},
+	database.ProvisionerJobTypeWorkspaceBuild:        {"workspace_build_id"},
+}
+
+func validateProvisionerJobInput(typ database.ProvisionerJobType, input []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return xerrors.Errorf("invalid provisioner job input: %w", err)
+	}
+	for _, key := range provisionerJobInputRequiredKeys[typ] {
+		if _, ok := fields[key]; !ok {
+			return xerrors.Errorf("invalid provisioner job input: %s job is missing required key %q", typ, key)
+		}
+	}
+	return nil
 }
 
 func validateDatabaseTypeWithValid(v reflect.Value) (handled bool, err error) {
@@ -257,6 +324,16 @@ func (q *FakeQuerier) InTx(fn func(database.Store) error, _ *sql.TxOptions) erro
 	return fn(tx)
 }
 
+// tryPercentile sorts fs and returns its p-th percentile, or -1 if fs is
+// empty to match the SQL queries.
+func tryPercentile(fs []float64, p float64) float64 {
+	if len(fs) == 0 {
+		return -1
+	}
+	sort.Float64s(fs)
+	return slice.Percentile(fs, p)
+}
+
 // getUserByIDNoLock is used by other functions in the database fake.
 func (q *FakeQuerier) getUserByIDNoLock(id uuid.UUID) (database.User, error) {
 	for _, user := range q.users {
@@ -324,6 +401,23 @@ func mapAgentStatus(dbAgent database.WorkspaceAgent, agentInactiveDisconnectTime
 	return status
 }
 
+// mapAppHealth mirrors the health CASE in the workspace app read queries.
+// Apps without a healthcheck are disabled, and an app whose healthcheck has not
+// reported a result after enough time for the agent to run all of its checks is
+// treated as unhealthy instead of initializing forever.
+func mapAppHealth(app database.WorkspaceApp) database.WorkspaceAppHealth {
+	if app.HealthcheckUrl == "" {
+		return database.WorkspaceAppHealthDisabled
+	}
+	window := time.Duration(app.HealthcheckInterval*app.HealthcheckThreshold) * time.Second
+	if app.Health == database.WorkspaceAppHealthInitializing && !app.CreatedAt.Add(window).After(database.Now()) {
+		// The agent had enough time to report a healthcheck result but never
+		// did.
+		return database.WorkspaceAppHealthUnhealthy
+	}
+	return app.Health
+}
+
 func (q *FakeQuerier) convertToWorkspaceRowsNoLock(ctx context.Context, workspaces []database.Workspace, count int64) []database.GetWorkspacesRow {
 	rows := make([]database.GetWorkspacesRow, 0, len(workspaces))
 	for _, w := range workspaces {
@@ -352,14 +446,11 @@ func (q *FakeQuerier) convertToWorkspaceRowsNoLock(ctx context.Context, workspac
 		}
 
 		if build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, w.ID); err == nil {
-			for _, tv := range q.templateVersions {
-				if tv.ID == build.TemplateVersionID {
-					wr.TemplateVersionID = tv.ID
-					wr.TemplateVersionName = sql.NullString{
-						Valid:  true,
-						String: tv.Name,
-					}
-					break
+			if tv, err := q.getTemplateVersionByIDNoLock(ctx, build.TemplateVersionID); err == nil {
+				wr.TemplateVersionID = tv.ID
+				wr.TemplateVersionName = sql.NullString{
+					Valid:  true,
+					String: tv.Name,
 				}
 			}
 		}
@@ -431,21 +522,35 @@ func (q *FakeQuerier) getWorkspaceBuildByIDNoLock(_ context.Context, id uuid.UUI
 }
 
 func (q *FakeQuerier) getLatestWorkspaceBuildByWorkspaceIDNoLock(_ context.Context, workspaceID uuid.UUID) (database.WorkspaceBuild, error) {
-	var row database.WorkspaceBuild
-	var buildNum int32 = -1
-	for _, workspaceBuild := range q.workspaceBuilds {
-		if workspaceBuild.WorkspaceID == workspaceID && workspaceBuild.BuildNumber > buildNum {
-			row = q.workspaceBuildWithUserNoLock(workspaceBuild)
-			buildNum = workspaceBuild.BuildNumber
+	var latest *database.WorkspaceBuildTable
+	for i, workspaceBuild := range q.workspaceBuilds {
+		if workspaceBuild.WorkspaceID != workspaceID {
+			continue
+		}
+		if latest == nil || workspaceBuildIsNewer(workspaceBuild, *latest) {
+			latest = &q.workspaceBuilds[i]
 		}
 	}
-	if buildNum == -1 {
+	if latest == nil {
 		return database.WorkspaceBuild{}, sql.ErrNoRows
 	}
-	return row, nil
+	return q.workspaceBuildWithUserNoLock(*latest), nil
+}
+
+// workspaceBuildIsNewer reports whether a sorts before b when ordering by
+// build number, then creation time, then ID, all descending.
+func workspaceBuildIsNewer(a, b database.WorkspaceBuildTable) bool {
+	if a.BuildNumber != b.BuildNumber {
+		return a.BuildNumber > b.BuildNumber
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) > 0
 }
 
 func (q *FakeQuerier) getTemplateByIDNoLock(_ context.Context, id uuid.UUID) (database.Template, error) {
+	q.templateLookups.Add(1)
 	for _, template := range q.templates {
 		if template.ID == id {
 			return q.templateWithUserNoLock(template), nil
@@ -479,6 +584,27 @@ func (q *FakeQuerier) templateWithUserNoLock(tpl database.TemplateTable) databas
 	return withUser
 }
 
+// templateProvisionerTagsNoLock returns the provisioner tags required by the
+// template of the workspace build the job belongs to, if any.
+func (q *FakeQuerier) templateProvisionerTagsNoLock(jobID uuid.UUID) database.StringMap {
+	for _, build := range q.workspaceBuilds {
+		if build.JobID != jobID {
+			continue
+		}
+		for _, version := range q.templateVersions {
+			if version.ID != build.TemplateVersionID || !version.TemplateID.Valid {
+				continue
+			}
+			for _, template := range q.templates {
+				if template.ID == version.TemplateID.UUID {
+					return template.ProvisionerTags
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (q *FakeQuerier) templateVersionWithUserNoLock(tpl database.TemplateVersionTable) database.TemplateVersion {
 	var user database.User
 	for _, _user := range q.users {
@@ -565,6 +691,13 @@ func (q *FakeQuerier) getWorkspaceResourcesByJobIDNoLock(_ context.Context, jobI
 		}
 		resources = append(resources, resource)
 	}
+	// Sort for stable rendering, matching the SQL query.
+	slices.SortFunc(resources, func(a, b database.WorkspaceResource) bool {
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
 	return resources, nil
 }
 
@@ -611,6 +744,18 @@ func uniqueSortedUUIDs(uuids []uuid.UUID) []uuid.UUID {
 	return unique
 }
 
+// percentileCont mirrors PostgreSQL's PERCENTILE_CONT, interpolating linearly
+// between the two closest values. fs must be sorted.
+func percentileCont(fs []float64, p float64) float64 {
+	if len(fs) == 0 {
+		return -1
+	}
+	pos := p * float64(len(fs)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return fs[lower] + (fs[upper]-fs[lower])*(pos-float64(lower))
+}
+
 func (*FakeQuerier) AcquireLock(_ context.Context, _ int64) error {
 	return xerrors.New("AcquireLock must only be called within a transaction")
 }
@@ -658,6 +803,13 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 				break
 			}
 		}
+		// Builds must also satisfy the tags required by their template.
+		for key, value := range q.templateProvisionerTagsNoLock(provisionerJob.ID) {
+			if provided, found := tags[key]; !found || provided != value {
+				missing = true
+				break
+			}
+		}
 		if missing {
 			continue
 		}
@@ -670,10 +822,41 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 	return database.ProvisionerJob{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) CancelPendingProvisionerJobs(_ context.Context, canceledAt time.Time) (int64, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var count int64
+	for index, job := range q.provisionerJobs {
+		if job.StartedAt.Valid || job.CanceledAt.Valid {
+			continue
+		}
+		job.CanceledAt = sql.NullTime{Time: canceledAt, Valid: true}
+		job.CompletedAt = sql.NullTime{Time: canceledAt, Valid: true}
+		job.UpdatedAt = canceledAt
+		q.provisionerJobs[index] = job
+		count++
+	}
+	return count, nil
+}
+
 func (*FakeQuerier) CleanTailnetCoordinators(_ context.Context) error {
 	return ErrUnimplemented
 }
 
+func (q *FakeQuerier) CountRunningProvisionerJobs(_ context.Context) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var count int64
+	for _, job := range q.provisionerJobs {
+		if job.StartedAt.Valid && !job.CompletedAt.Valid && !job.CanceledAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (q *FakeQuerier) DeleteAPIKeyByID(_ context.Context, id string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -702,6 +885,21 @@ func (q *FakeQuerier) DeleteAPIKeysByUserID(_ context.Context, userID uuid.UUID)
 	return nil
 }
 
+func (q *FakeQuerier) DeleteAllGroupMembersByUserID(_ context.Context, userID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	newMembers := q.groupMembers[:0]
+	for _, member := range q.groupMembers {
+		if member.UserID != userID {
+			newMembers = append(newMembers, member)
+		}
+	}
+	q.groupMembers = newMembers
+
+	return nil
+}
+
 func (q *FakeQuerier) DeleteApplicationConnectAPIKeysByUserID(_ context.Context, userID uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -804,8 +1002,25 @@ func (q *FakeQuerier) DeleteLicense(_ context.Context, id int32) (int32, error)
 	return 0, sql.ErrNoRows
 }
 
-func (*FakeQuerier) DeleteOldWorkspaceAgentLogs(_ context.Context) error {
-	// noop
+func (q *FakeQuerier) DeleteOldWorkspaceAgentLogs(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	threshold := database.Now().Add(-7 * 24 * time.Hour)
+	oldAgents := make(map[uuid.UUID]struct{})
+	for _, agent := range q.workspaceAgents {
+		if agent.LastConnectedAt.Valid && agent.LastConnectedAt.Time.Before(threshold) {
+			oldAgents[agent.ID] = struct{}{}
+		}
+	}
+	logs := make([]database.WorkspaceAgentLog, 0, len(q.workspaceAgentLogs))
+	for _, log := range q.workspaceAgentLogs {
+		if _, ok := oldAgents[log.AgentID]; ok {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	q.workspaceAgentLogs = logs
 	return nil
 }
 
@@ -918,6 +1133,19 @@ func (q *FakeQuerier) GetActiveUserCount(_ context.Context) (int64, error) {
 	return active, nil
 }
 
+func (q *FakeQuerier) GetActiveUserCountSince(_ context.Context, since time.Time) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	active := int64(0)
+	for _, u := range q.users {
+		if u.Status == database.UserStatusActive && !u.Deleted && u.LastSeenAt.After(since) {
+			active++
+		}
+	}
+	return active, nil
+}
+
 func (*FakeQuerier) GetAllTailnetAgents(_ context.Context) ([]database.TailnetAgent, error) {
 	return nil, ErrUnimplemented
 }
@@ -1030,6 +1258,28 @@ func (q *FakeQuerier) GetAuthorizationUserRoles(_ context.Context, userID uuid.U
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	return q.getAuthorizationUserRolesNoLock(userID)
+}
+
+func (q *FakeQuerier) GetAuthorizationUserRolesByIDs(_ context.Context, ids []uuid.UUID) ([]database.GetAuthorizationUserRolesByIDsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetAuthorizationUserRolesByIDsRow, 0, len(ids))
+	for _, id := range ids {
+		row, err := q.getAuthorizationUserRolesNoLock(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, database.GetAuthorizationUserRolesByIDsRow(row))
+	}
+	return rows, nil
+}
+
+func (q *FakeQuerier) getAuthorizationUserRolesNoLock(userID uuid.UUID) (database.GetAuthorizationUserRolesRow, error) {
 	var user *database.User
 	roles := make([]string, 0)
 	for _, u := range q.users {
@@ -1083,6 +1333,26 @@ func (q *FakeQuerier) GetDefaultProxyConfig(_ context.Context) (database.GetDefa
 	}, nil
 }
 
+func (q *FakeQuerier) GetDeletedTemplates(_ context.Context) ([]database.Template, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	templates := make([]database.TemplateTable, 0)
+	for _, template := range q.templates {
+		if template.Deleted {
+			templates = append(templates, template)
+		}
+	}
+	slices.SortFunc(templates, func(i, j database.TemplateTable) bool {
+		if !i.UpdatedAt.Equal(j.UpdatedAt) {
+			return i.UpdatedAt.After(j.UpdatedAt)
+		}
+		return i.ID.String() < j.ID.String()
+	})
+
+	return q.templatesWithUserNoLock(templates), nil
+}
+
 func (q *FakeQuerier) GetDeploymentDAUs(_ context.Context, tzOffset int32) ([]database.GetDeploymentDAUsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1110,8 +1380,12 @@ func (q *FakeQuerier) GetDeploymentDAUs(_ context.Context, tzOffset int32) ([]da
 
 	var rs []database.GetDeploymentDAUsRow
 	for _, key := range seenKeys {
-		ids := seens[key]
-		for id := range ids {
+		ids := maps.Keys(seens[key])
+		// Map iteration order is random, so sort to match the SQL ordering.
+		slices.SortFunc(ids, func(a, b uuid.UUID) bool {
+			return a.String() < b.String()
+		})
+		for _, id := range ids {
 			rs = append(rs, database.GetDeploymentDAUsRow{
 				Date:   key,
 				UserID: id,
@@ -1165,24 +1439,22 @@ func (q *FakeQuerier) GetDeploymentWorkspaceAgentStats(_ context.Context, create
 		latencies = append(latencies, agentStat.ConnectionMedianLatencyMS)
 	}
 
-	tryPercentile := func(fs []float64, p float64) float64 {
-		if len(fs) == 0 {
-			return -1
-		}
-		sort.Float64s(fs)
-		return fs[int(float64(len(fs))*p/100)]
-	}
-
 	stat.WorkspaceConnectionLatency50 = tryPercentile(latencies, 50)
 	stat.WorkspaceConnectionLatency95 = tryPercentile(latencies, 95)
+	stat.HasData = len(latencies) > 0
 
 	return stat, nil
 }
 
-func (q *FakeQuerier) GetDeploymentWorkspaceStats(ctx context.Context) (database.GetDeploymentWorkspaceStatsRow, error) {
+func (q *FakeQuerier) GetDeploymentWorkspaceStats(ctx context.Context, buildingWindowSeconds int32) (database.GetDeploymentWorkspaceStatsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	buildingWindow := 30 * time.Second
+	if buildingWindowSeconds > 0 {
+		buildingWindow = time.Duration(buildingWindowSeconds) * time.Second
+	}
+
 	stat := database.GetDeploymentWorkspaceStatsRow{}
 	for _, workspace := range q.workspaces {
 		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
@@ -1199,7 +1471,7 @@ func (q *FakeQuerier) GetDeploymentWorkspaceStats(ctx context.Context) (database
 		}
 		if job.StartedAt.Valid &&
 			!job.CanceledAt.Valid &&
-			time.Since(job.UpdatedAt) <= 30*time.Second &&
+			time.Since(job.UpdatedAt) <= buildingWindow &&
 			!job.CompletedAt.Valid {
 			stat.BuildingWorkspaces++
 			continue
@@ -1267,12 +1539,17 @@ func (q *FakeQuerier) GetFileTemplates(_ context.Context, id uuid.UUID) ([]datab
 		return rows, nil
 	}
 
+	seen := make(map[uuid.UUID]struct{})
 	for _, job := range q.provisionerJobs {
 		if job.FileID == id {
 			for _, version := range q.templateVersions {
 				if version.JobID == job.ID {
 					for _, template := range q.templates {
 						if template.ID == version.TemplateID.UUID {
+							if _, ok := seen[template.ID]; ok {
+								continue
+							}
+							seen[template.ID] = struct{}{}
 							rows = append(rows, database.GetFileTemplatesRow{
 								FileID:                 file.ID,
 								FileCreatedBy:          file.CreatedBy,
@@ -1339,8 +1616,10 @@ func (q *FakeQuerier) GetGroupByOrgAndName(_ context.Context, arg database.GetGr
 	defer q.mutex.RUnlock()
 
 	for _, group := range q.groups {
-		if group.OrganizationID == arg.OrganizationID &&
-			group.Name == arg.Name {
+		if group.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		if group.Name == arg.Name || (arg.CaseInsensitive && strings.EqualFold(group.Name, arg.Name)) {
 			return group, nil
 		}
 	}
@@ -1373,14 +1652,21 @@ func (q *FakeQuerier) GetGroupMembers(_ context.Context, groupID uuid.UUID) ([]d
 	return users, nil
 }
 
-func (q *FakeQuerier) GetGroupsByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.Group, error) {
+func (q *FakeQuerier) GetGroupsByOrganizationID(_ context.Context, arg database.GetGroupsByOrganizationIDParams) ([]database.Group, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	var groups []database.Group
 	for _, group := range q.groups {
-		// Omit the allUsers group.
-		if group.OrganizationID == organizationID && group.ID != organizationID {
+		if group.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		// Omit the allUsers group unless requested.
+		if arg.IncludeEveryone || group.ID != arg.OrganizationID {
 			groups = append(groups, group)
 		}
 	}
@@ -1445,6 +1731,11 @@ func (q *FakeQuerier) GetLatestWorkspaceBuilds(_ context.Context) ([]database.Wo
 }
 
 func (q *FakeQuerier) GetLatestWorkspaceBuildsByWorkspaceIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceBuild, error) {
+	// Like the real database, nothing to look up is not an error.
+	if len(ids) == 0 {
+		return []database.WorkspaceBuild{}, nil
+	}
+
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
@@ -1596,10 +1887,9 @@ func (q *FakeQuerier) GetOrganizations(_ context.Context) ([]database.Organizati
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	if len(q.organizations) == 0 {
-		return nil, sql.ErrNoRows
-	}
-	return q.organizations, nil
+	// Match the SQL implementation, which returns an empty result rather
+	// than an error when there are no organizations.
+	return slices.Clone(q.organizations), nil
 }
 
 func (q *FakeQuerier) GetOrganizationsByUserID(_ context.Context, userID uuid.UUID) ([]database.Organization, error) {
@@ -1712,6 +2002,34 @@ func (q *FakeQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (
 	return q.getProvisionerJobByIDNoLock(ctx, id)
 }
 
+func (q *FakeQuerier) GetProvisionerJobWithLogTail(ctx context.Context, arg database.GetProvisionerJobWithLogTailParams) (database.ProvisionerJobWithLogTail, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	job, err := q.getProvisionerJobByIDNoLock(ctx, arg.ID)
+	if err != nil {
+		return database.ProvisionerJobWithLogTail{}, err
+	}
+
+	logs := make([]database.ProvisionerJobLog, 0)
+	for _, jobLog := range q.provisionerJobLogs {
+		if jobLog.JobID == arg.ID {
+			logs = append(logs, jobLog)
+		}
+	}
+	tail := arg.TailLines
+	if tail < 0 {
+		tail = 0
+	}
+	if tail < len(logs) {
+		logs = logs[len(logs)-tail:]
+	}
+	return database.ProvisionerJobWithLogTail{
+		ProvisionerJob: job,
+		Logs:           logs,
+	}, nil
+}
+
 func (q *FakeQuerier) GetProvisionerJobsByIDs(_ context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1736,43 +2054,63 @@ func (q *FakeQuerier) GetProvisionerJobsByIDsWithQueuePosition(_ context.Context
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	// Jobs only compete with other jobs that can be acquired by the same
+	// daemons, so each provisioner type and tag set has its own queue.
+	poolKey := func(job database.ProvisionerJob) string {
+		tags := []byte("{}")
+		if len(job.Tags) > 0 {
+			tags, _ = json.Marshal(job.Tags)
+		}
+		return string(job.Provisioner) + string(tags)
+	}
+	positions := make(map[uuid.UUID]int64)
+	sizes := make(map[string]int64)
+	for _, job := range q.provisionerJobs {
+		if job.StartedAt.Valid {
+			continue
+		}
+		key := poolKey(job)
+		sizes[key]++
+		positions[job.ID] = sizes[key]
+	}
+
 	jobs := make([]database.GetProvisionerJobsByIDsWithQueuePositionRow, 0)
-	queuePosition := int64(1)
 	for _, job := range q.provisionerJobs {
-		for _, id := range ids {
-			if id == job.ID {
-				job := database.GetProvisionerJobsByIDsWithQueuePositionRow{
-					ProvisionerJob: job,
-				}
-				if !job.ProvisionerJob.StartedAt.Valid {
-					job.QueuePosition = queuePosition
-				}
-				jobs = append(jobs, job)
-				break
-			}
+		if !slices.Contains(ids, job.ID) {
+			continue
 		}
-		if !job.StartedAt.Valid {
-			queuePosition++
+		row := database.GetProvisionerJobsByIDsWithQueuePositionRow{
+			ProvisionerJob: job,
 		}
-	}
-	for _, job := range jobs {
-		if !job.ProvisionerJob.StartedAt.Valid {
-			// Set it to the max position!
-			job.QueueSize = queuePosition
+		if !job.StartedAt.Valid {
+			row.QueuePosition = positions[job.ID]
+			row.QueueSize = sizes[poolKey(job)]
 		}
+		jobs = append(jobs, row)
 	}
 	return jobs, nil
 }
 
-func (q *FakeQuerier) GetProvisionerJobsCreatedAfter(_ context.Context, after time.Time) ([]database.ProvisionerJob, error) {
+func (q *FakeQuerier) GetProvisionerJobsCreatedAfter(_ context.Context, arg database.GetProvisionerJobsCreatedAfterParams) ([]database.ProvisionerJob, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	jobs := make([]database.ProvisionerJob, 0)
 	for _, job := range q.provisionerJobs {
-		if job.CreatedAt.After(after) {
-			jobs = append(jobs, job)
+		if !job.CreatedAt.After(arg.CreatedAt) {
+			continue
+		}
+		if len(arg.Types) > 0 && !slices.Contains(arg.Types, job.Type) {
+			continue
+		}
+		if arg.Status != "" && string(db2sdk.ProvisionerJobStatus(job)) != arg.Status {
+			continue
 		}
+		jobs = append(jobs, job)
 	}
 	return jobs, nil
 }
@@ -1795,6 +2133,9 @@ func (q *FakeQuerier) GetProvisionerLogsAfterID(_ context.Context, arg database.
 		}
 		logs = append(logs, jobLog)
 	}
+	if arg.Limit > 0 && int(arg.Limit) < len(logs) {
+		logs = logs[:arg.Limit]
+	}
 	return logs, nil
 }
 
@@ -1802,6 +2143,10 @@ func (q *FakeQuerier) GetQuotaAllowanceForUser(_ context.Context, userID uuid.UU
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	return q.getQuotaAllowanceForUserNoLock(userID), nil
+}
+
+func (q *FakeQuerier) getQuotaAllowanceForUserNoLock(userID uuid.UUID) int64 {
 	var sum int64
 	for _, member := range q.groupMembers {
 		if member.UserID != userID {
@@ -1813,13 +2158,30 @@ func (q *FakeQuerier) GetQuotaAllowanceForUser(_ context.Context, userID uuid.UU
 			}
 		}
 	}
-	return sum, nil
+	return sum
 }
 
-func (q *FakeQuerier) GetQuotaConsumedForUser(_ context.Context, userID uuid.UUID) (int64, error) {
+func (q *FakeQuerier) GetQuotaConsumedForUser(_ context.Context, arg database.GetQuotaConsumedForUserParams) (int64, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	return q.getQuotaConsumedForUserNoLock(arg.OwnerID, arg.OnlyRunning), nil
+}
+
+func (q *FakeQuerier) GetQuotaConsumedForUserIncludingPending(_ context.Context, arg database.GetQuotaConsumedForUserIncludingPendingParams) (database.GetQuotaConsumedForUserIncludingPendingRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	consumed := q.getQuotaConsumedForUserNoLock(arg.OwnerID, arg.OnlyRunning) + int64(arg.PendingDailyCost)
+	allowance := q.getQuotaAllowanceForUserNoLock(arg.OwnerID)
+	return database.GetQuotaConsumedForUserIncludingPendingRow{
+		Consumed:         consumed,
+		Allowance:        allowance,
+		ExceedsAllowance: consumed > allowance,
+	}, nil
+}
+
+func (q *FakeQuerier) getQuotaConsumedForUserNoLock(userID uuid.UUID, onlyRunning bool) int64 {
 	var sum int64
 	for _, workspace := range q.workspaces {
 		if workspace.OwnerID != userID {
@@ -1838,9 +2200,12 @@ func (q *FakeQuerier) GetQuotaConsumedForUser(_ context.Context, userID uuid.UUI
 				lastBuild = build
 			}
 		}
+		if onlyRunning && lastBuild.Transition != database.WorkspaceTransitionStart {
+			continue
+		}
 		sum += int64(lastBuild.DailyCost)
 	}
-	return sum, nil
+	return sum
 }
 
 func (q *FakeQuerier) GetReplicaByID(_ context.Context, id uuid.UUID) (database.Replica, error) {
@@ -1887,6 +2252,49 @@ func (*FakeQuerier) GetTailnetClientsForAgent(context.Context, uuid.UUID) ([]dat
 	return nil, ErrUnimplemented
 }
 
+func (q *FakeQuerier) GetTemplateAndDeploymentDAUs(_ context.Context, arg database.GetTemplateAndDeploymentDAUsParams) ([]database.GetTemplateAndDeploymentDAUsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	templateSeens := make(map[time.Time]map[uuid.UUID]struct{})
+	deploymentSeens := make(map[time.Time]map[uuid.UUID]struct{})
+	for _, as := range q.workspaceAgentStats {
+		if as.ConnectionCount == 0 {
+			continue
+		}
+
+		date := as.CreatedAt.UTC().Add(time.Duration(arg.TzOffset) * time.Hour * -1).Truncate(time.Hour * 24)
+
+		if deploymentSeens[date] == nil {
+			deploymentSeens[date] = make(map[uuid.UUID]struct{})
+		}
+		deploymentSeens[date][as.UserID] = struct{}{}
+
+		if as.TemplateID != arg.TemplateID {
+			continue
+		}
+		if templateSeens[date] == nil {
+			templateSeens[date] = make(map[uuid.UUID]struct{})
+		}
+		templateSeens[date][as.UserID] = struct{}{}
+	}
+
+	dates := maps.Keys(deploymentSeens)
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	rs := make([]database.GetTemplateAndDeploymentDAUsRow, 0, len(dates))
+	for _, date := range dates {
+		rs = append(rs, database.GetTemplateAndDeploymentDAUsRow{
+			Date:                  date,
+			TemplateActiveUsers:   int64(len(templateSeens[date])),
+			DeploymentActiveUsers: int64(len(deploymentSeens[date])),
+		})
+	}
+	return rs, nil
+}
+
 func (q *FakeQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.GetTemplateAverageBuildTimeRow{}, err
@@ -1913,31 +2321,26 @@ func (q *FakeQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg datab
 		if err != nil {
 			return emptyRow, err
 		}
-		if job.CompletedAt.Valid {
-			took := job.CompletedAt.Time.Sub(job.StartedAt.Time).Seconds()
-			switch wb.Transition {
-			case database.WorkspaceTransitionStart:
-				startTimes = append(startTimes, took)
-			case database.WorkspaceTransitionStop:
-				stopTimes = append(stopTimes, took)
-			case database.WorkspaceTransitionDelete:
-				deleteTimes = append(deleteTimes, took)
-			}
+		// Canceled and failed builds are not counted.
+		if !job.CompletedAt.Valid || job.CanceledAt.Valid || job.Error.String != "" {
+			continue
 		}
-	}
-
-	tryPercentile := func(fs []float64, p float64) float64 {
-		if len(fs) == 0 {
-			return -1
+		took := job.CompletedAt.Time.Sub(job.StartedAt.Time).Seconds()
+		switch wb.Transition {
+		case database.WorkspaceTransitionStart:
+			startTimes = append(startTimes, took)
+		case database.WorkspaceTransitionStop:
+			stopTimes = append(stopTimes, took)
+		case database.WorkspaceTransitionDelete:
+			deleteTimes = append(deleteTimes, took)
 		}
-		sort.Float64s(fs)
-		return fs[int(float64(len(fs))*p/100)]
 	}
 
 	var row database.GetTemplateAverageBuildTimeRow
 	row.Delete50, row.Delete95 = tryPercentile(deleteTimes, 50), tryPercentile(deleteTimes, 95)
 	row.Stop50, row.Stop95 = tryPercentile(stopTimes, 50), tryPercentile(stopTimes, 95)
 	row.Start50, row.Start95 = tryPercentile(startTimes, 50), tryPercentile(startTimes, 95)
+	row.StartCount, row.StopCount, row.DeleteCount = int64(len(startTimes)), int64(len(stopTimes)), int64(len(deleteTimes))
 	return row, nil
 }
 
@@ -2124,14 +2527,42 @@ func (q *FakeQuerier) GetTemplateInsights(_ context.Context, arg database.GetTem
 	})
 	result := database.GetTemplateInsightsRow{
 		TemplateIDs: templateIDs,
-		ActiveUsers: int64(len(appUsageIntervalsByUser)),
 	}
+	var sessionDurations []int64
 	for _, intervals := range appUsageIntervalsByUser {
-		for _, interval := range intervals {
+		if int64(len(intervals))*300 >= arg.MinActiveSeconds {
+			result.ActiveUsers++
+		}
+		starts := make([]time.Time, 0, len(intervals))
+		for start, interval := range intervals {
 			result.UsageJetbrainsSeconds += interval.UsageJetbrainsSeconds
 			result.UsageVscodeSeconds += interval.UsageVscodeSeconds
 			result.UsageReconnectingPtySeconds += interval.UsageReconnectingPtySeconds
 			result.UsageSshSeconds += interval.UsageSshSeconds
+			starts = append(starts, start)
+		}
+
+		// Coalesce contiguous intervals into sessions.
+		slices.SortFunc(starts, func(a, b time.Time) bool {
+			return a.Before(b)
+		})
+		for i, start := range starts {
+			if i > 0 && start.Sub(starts[i-1]) == 5*time.Minute {
+				sessionDurations[len(sessionDurations)-1] += 300
+				continue
+			}
+			sessionDurations = append(sessionDurations, 300)
+		}
+	}
+	if len(sessionDurations) > 0 {
+		slices.Sort(sessionDurations)
+		mid := len(sessionDurations) / 2
+		if len(sessionDurations)%2 == 0 {
+			// PERCENTILE_CONT interpolates as a float and the ::bigint cast
+			// rounds half to even.
+			result.MedianSessionDurationSeconds = int64(math.RoundToEven(float64(sessionDurations[mid-1]+sessionDurations[mid]) / 2))
+		} else {
+			result.MedianSessionDurationSeconds = sessionDurations[mid]
 		}
 	}
 	return result, nil
@@ -2162,7 +2593,7 @@ func (q *FakeQuerier) GetTemplateParameterInsights(ctx context.Context, arg data
 			if err != nil {
 				return nil, err
 			}
-			if slices.Contains(arg.TemplateIDs, ws.TemplateID) {
+			if !slices.Contains(arg.TemplateIDs, ws.TemplateID) {
 				delete(latestWorkspaceBuilds, wsID)
 			}
 		}
@@ -2305,6 +2736,25 @@ func (q *FakeQuerier) GetTemplateVersionVariables(_ context.Context, templateVer
 	return variables, nil
 }
 
+func (q *FakeQuerier) GetTemplateVersionVariablesRedacted(_ context.Context, templateVersionID uuid.UUID) ([]database.GetTemplateVersionVariablesRedactedRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	variables := make([]database.GetTemplateVersionVariablesRedactedRow, 0)
+	for _, v := range q.templateVersionVariables {
+		if v.TemplateVersionID != templateVersionID {
+			continue
+		}
+		row := database.GetTemplateVersionVariablesRedactedRow(v)
+		if row.Sensitive {
+			row.Value = ""
+			row.DefaultValue = ""
+		}
+		variables = append(variables, row)
+	}
+	return variables, nil
+}
+
 func (q *FakeQuerier) GetTemplateVersionsByIDs(_ context.Context, ids []uuid.UUID) ([]database.TemplateVersion, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2388,17 +2838,40 @@ func (q *FakeQuerier) GetTemplateVersionsByTemplateID(_ context.Context, arg dat
 	return version, nil
 }
 
-func (q *FakeQuerier) GetTemplateVersionsCreatedAfter(_ context.Context, after time.Time) ([]database.TemplateVersion, error) {
+func (q *FakeQuerier) GetTemplateVersionsCreatedAfter(_ context.Context, after time.Time) ([]database.TemplateVersion, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	versions := make([]database.TemplateVersion, 0)
+	for _, version := range q.templateVersions {
+		if version.CreatedAt.After(after) {
+			versions = append(versions, q.templateVersionWithUserNoLock(version))
+		}
+	}
+	return versions, nil
+}
+
+func (q *FakeQuerier) GetTemplateVersionsWithStatusByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetTemplateVersionsWithStatusByIDsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	versions := make([]database.TemplateVersion, 0)
+	rows := make([]database.GetTemplateVersionsWithStatusByIDsRow, 0)
 	for _, version := range q.templateVersions {
-		if version.CreatedAt.After(after) {
-			versions = append(versions, q.templateVersionWithUserNoLock(version))
+		if !slices.Contains(ids, version.ID) {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, version.JobID)
+		if err != nil {
+			// The SQL query uses an inner join, so versions without a job
+			// are omitted.
+			continue
 		}
+		rows = append(rows, database.GetTemplateVersionsWithStatusByIDsRow{
+			TemplateVersion: q.templateVersionWithUserNoLock(version),
+			JobStatus:       string(db2sdk.ProvisionerJobStatus(job)),
+		})
 	}
-	return versions, nil
+	return rows, nil
 }
 
 func (q *FakeQuerier) GetTemplates(_ context.Context) ([]database.Template, error) {
@@ -2481,6 +2954,12 @@ func (q *FakeQuerier) GetUserLatencyInsights(_ context.Context, arg database.Get
 		return nil, err
 	}
 
+	for _, p := range arg.Percentiles {
+		if p < 0 || p > 1 {
+			return nil, xerrors.Errorf("percentile value %v is not between 0 and 1", p)
+		}
+	}
+
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
@@ -2507,14 +2986,6 @@ func (q *FakeQuerier) GetUserLatencyInsights(_ context.Context, arg database.Get
 		seenTemplatesByUserID[s.UserID][s.TemplateID] = struct{}{}
 	}
 
-	tryPercentile := func(fs []float64, p float64) float64 {
-		if len(fs) == 0 {
-			return -1
-		}
-		sort.Float64s(fs)
-		return fs[int(float64(len(fs))*p/100)]
-	}
-
 	var rows []database.GetUserLatencyInsightsRow
 	for userID, latencies := range latenciesByUserID {
 		sort.Float64s(latencies)
@@ -2538,6 +3009,10 @@ func (q *FakeQuerier) GetUserLatencyInsights(_ context.Context, arg database.Get
 			WorkspaceConnectionLatency50: tryPercentile(latencies, 50),
 			WorkspaceConnectionLatency95: tryPercentile(latencies, 95),
 		}
+		row.WorkspaceConnectionLatencyPercentiles = make([]float64, 0, len(arg.Percentiles))
+		for _, p := range arg.Percentiles {
+			row.WorkspaceConnectionLatencyPercentiles = append(row.WorkspaceConnectionLatencyPercentiles, percentileCont(latencies, p))
+		}
 		rows = append(rows, row)
 	}
 	slices.SortFunc(rows, func(a, b database.GetUserLatencyInsightsRow) bool {
@@ -2672,6 +3147,49 @@ func (q *FakeQuerier) GetUsers(_ context.Context, params database.GetUsersParams
 		users = usersFilteredByLastSeen
 	}
 
+	if !params.CreatedBefore.IsZero() {
+		usersFilteredByCreatedAt := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if !user.CreatedAt.After(params.CreatedBefore) {
+				usersFilteredByCreatedAt = append(usersFilteredByCreatedAt, users[i])
+			}
+		}
+		users = usersFilteredByCreatedAt
+	}
+
+	if !params.CreatedAfter.IsZero() {
+		usersFilteredByCreatedAt := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if !user.CreatedAt.Before(params.CreatedAfter) {
+				usersFilteredByCreatedAt = append(usersFilteredByCreatedAt, users[i])
+			}
+		}
+		users = usersFilteredByCreatedAt
+	}
+
+	if len(params.LoginType) > 0 {
+		usersFilteredByLoginType := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if slice.Contains(params.LoginType, user.LoginType) {
+				usersFilteredByLoginType = append(usersFilteredByLoginType, users[i])
+			}
+		}
+		users = usersFilteredByLoginType
+	}
+
+	if params.OrganizationID != uuid.Nil {
+		usersFilteredByOrganization := make([]database.User, 0, len(users))
+		for i, user := range users {
+			for _, member := range q.organizationMembers {
+				if member.UserID == user.ID && member.OrganizationID == params.OrganizationID {
+					usersFilteredByOrganization = append(usersFilteredByOrganization, users[i])
+					break
+				}
+			}
+		}
+		users = usersFilteredByOrganization
+	}
+
 	beforePageCount := len(users)
 
 	if params.OffsetOpt > 0 {
@@ -2707,16 +3225,22 @@ func (q *FakeQuerier) GetUsersByIDs(_ context.Context, ids []uuid.UUID) ([]datab
 	return users, nil
 }
 
-func (q *FakeQuerier) GetWorkspaceAgentByAuthToken(_ context.Context, authToken uuid.UUID) (database.WorkspaceAgent, error) {
+func (q *FakeQuerier) GetWorkspaceAgentByAuthToken(ctx context.Context, authToken uuid.UUID) (database.WorkspaceAgent, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	// The schema sorts this by created at, so we iterate the array backwards.
 	for i := len(q.workspaceAgents) - 1; i >= 0; i-- {
 		agent := q.workspaceAgents[i]
-		if agent.AuthToken == authToken {
-			return agent, nil
+		if agent.AuthToken != authToken {
+			continue
 		}
+		// Agents of deleted workspaces must not be able to authenticate.
+		workspace, err := q.getWorkspaceByAgentIDNoLock(ctx, agent.ID)
+		if err == nil && workspace.Deleted {
+			continue
+		}
+		return agent, nil
 	}
 	return database.WorkspaceAgent{}, sql.ErrNoRows
 }
@@ -2728,6 +3252,20 @@ func (q *FakeQuerier) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (
 	return q.getWorkspaceAgentByIDNoLock(ctx, id)
 }
 
+func (q *FakeQuerier) GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx context.Context, id uuid.UUID) (database.WorkspaceAgent, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspace, err := q.getWorkspaceByAgentIDNoLock(ctx, id)
+	if err != nil {
+		return database.WorkspaceAgent{}, err
+	}
+	if workspace.Deleted {
+		return database.WorkspaceAgent{}, sql.ErrNoRows
+	}
+	return q.getWorkspaceAgentByIDNoLock(ctx, id)
+}
+
 func (q *FakeQuerier) GetWorkspaceAgentByInstanceID(_ context.Context, instanceID string) (database.WorkspaceAgent, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2791,20 +3329,46 @@ func (q *FakeQuerier) GetWorkspaceAgentMetadata(_ context.Context, workspaceAgen
 	return metadata, nil
 }
 
-func (q *FakeQuerier) GetWorkspaceAgentStats(_ context.Context, createdAfter time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
+func (q *FakeQuerier) GetWorkspaceAgentStats(_ context.Context, arg database.GetWorkspaceAgentStatsParams) ([]database.GetWorkspaceAgentStatsRow, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	readyAgents := map[uuid.UUID]struct{}{}
+	if arg.ReadyOnly {
+		for _, agent := range q.workspaceAgents {
+			if agent.LifecycleState == database.WorkspaceAgentLifecycleStateReady {
+				readyAgents[agent.ID] = struct{}{}
+			}
+		}
+	}
+	include := func(agentStat database.WorkspaceAgentStat) bool {
+		if !agentStat.CreatedAt.After(arg.CreatedAt) {
+			return false
+		}
+		if arg.ExcludeZeroConnections && agentStat.ConnectionCount == 0 {
+			return false
+		}
+		if arg.ReadyOnly {
+			_, ok := readyAgents[agentStat.AgentID]
+			return ok
+		}
+		return true
+	}
+
 	agentStatsCreatedAfter := make([]database.WorkspaceAgentStat, 0)
 	for _, agentStat := range q.workspaceAgentStats {
-		if agentStat.CreatedAt.After(createdAfter) {
+		if include(agentStat) {
 			agentStatsCreatedAfter = append(agentStatsCreatedAfter, agentStat)
 		}
 	}
 
 	latestAgentStats := map[uuid.UUID]database.WorkspaceAgentStat{}
 	for _, agentStat := range q.workspaceAgentStats {
-		if agentStat.CreatedAt.After(createdAfter) {
+		if include(agentStat) {
 			latestAgentStats[agentStat.AgentID] = agentStat
 		}
 	}
@@ -2840,14 +3404,6 @@ func (q *FakeQuerier) GetWorkspaceAgentStats(_ context.Context, createdAfter tim
 		latenciesByAgent[agentStat.AgentID] = append(latenciesByAgent[agentStat.AgentID], agentStat.ConnectionMedianLatencyMS)
 	}
 
-	tryPercentile := func(fs []float64, p float64) float64 {
-		if len(fs) == 0 {
-			return -1
-		}
-		sort.Float64s(fs)
-		return fs[int(float64(len(fs))*p/100)]
-	}
-
 	for _, stat := range statByAgent {
 		stat.AggregatedFrom = minimumDateByAgent[stat.AgentID]
 		statByAgent[stat.AgentID] = stat
@@ -2959,12 +3515,41 @@ func (q *FakeQuerier) GetWorkspaceAgentsCreatedAfter(_ context.Context, after ti
 	return workspaceAgents, nil
 }
 
-func (q *FakeQuerier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]database.WorkspaceAgent, error) {
+func (q *FakeQuerier) GetWorkspaceAgentsCreatedAfterWithWorkspace(ctx context.Context, after time.Time) ([]database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow, 0)
+	for _, agent := range q.workspaceAgents {
+		if !agent.CreatedAt.After(after) {
+			continue
+		}
+		workspace, err := q.getWorkspaceByAgentIDNoLock(ctx, agent.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow{
+			WorkspaceAgent: agent,
+			WorkspaceID:    workspace.ID,
+			TemplateID:     workspace.TemplateID,
+		})
+	}
+	return rows, nil
+}
+
+func (q *FakeQuerier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, arg database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams) ([]database.WorkspaceAgent, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	// Get latest build for workspace.
-	workspaceBuild, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspaceID)
+	workspaceBuild, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, arg.WorkspaceID)
 	if err != nil {
 		return nil, xerrors.Errorf("get latest workspace build: %w", err)
 	}
@@ -2978,9 +3563,15 @@ func (q *FakeQuerier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.C
 		return []database.WorkspaceAgent{}, nil
 	}
 
-	resourceIDs := make([]uuid.UUID, len(resources))
-	for i, resource := range resources {
-		resourceIDs[i] = resource.ID
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		if arg.ResourceType != "" && resource.Type != arg.ResourceType {
+			continue
+		}
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	if len(resourceIDs) == 0 {
+		return []database.WorkspaceAgent{}, nil
 	}
 
 	agents, err := q.getWorkspaceAgentsByResourceIDsNoLock(ctx, resourceIDs)
@@ -3006,6 +3597,10 @@ func (q *FakeQuerier) GetWorkspaceAppByAgentIDAndSlug(_ context.Context, arg dat
 		if app.Slug != arg.Slug {
 			continue
 		}
+		if arg.ExcludeExternal && app.External {
+			continue
+		}
+		app.Health = mapAppHealth(app)
 		return app, nil
 	}
 	return database.WorkspaceApp{}, sql.ErrNoRows
@@ -3018,6 +3613,7 @@ func (q *FakeQuerier) GetWorkspaceAppsByAgentID(_ context.Context, id uuid.UUID)
 	apps := make([]database.WorkspaceApp, 0)
 	for _, app := range q.workspaceApps {
 		if app.AgentID == id {
+			app.Health = mapAppHealth(app)
 			apps = append(apps, app)
 		}
 	}
@@ -3035,6 +3631,7 @@ func (q *FakeQuerier) GetWorkspaceAppsByAgentIDs(_ context.Context, ids []uuid.U
 	for _, app := range q.workspaceApps {
 		for _, id := range ids {
 			if app.AgentID == id {
+				app.Health = mapAppHealth(app)
 				apps = append(apps, app)
 				break
 			}
@@ -3050,6 +3647,7 @@ func (q *FakeQuerier) GetWorkspaceAppsCreatedAfter(_ context.Context, after time
 	apps := make([]database.WorkspaceApp, 0)
 	for _, app := range q.workspaceApps {
 		if app.CreatedAt.After(after) {
+			app.Health = mapAppHealth(app)
 			apps = append(apps, app)
 		}
 	}
@@ -3095,6 +3693,22 @@ func (q *FakeQuerier) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(_ context.Con
 	return database.WorkspaceBuild{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceBuildParameterByName(_ context.Context, arg database.GetWorkspaceBuildParameterByNameParams) (database.WorkspaceBuildParameter, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceBuildParameter{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, param := range q.workspaceBuildParameters {
+		if param.WorkspaceBuildID == arg.WorkspaceBuildID && param.Name == arg.Name {
+			return param, nil
+		}
+	}
+	return database.WorkspaceBuildParameter{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetWorkspaceBuildParameters(_ context.Context, workspaceBuildID uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -3109,6 +3723,37 @@ func (q *FakeQuerier) GetWorkspaceBuildParameters(_ context.Context, workspaceBu
 	return params, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceBuildParametersByBuildIDs(_ context.Context, ids []uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	params := make([]database.WorkspaceBuildParameter, 0)
+	for _, param := range q.workspaceBuildParameters {
+		if slices.Contains(ids, param.WorkspaceBuildID) {
+			params = append(params, param)
+		}
+	}
+	return params, nil
+}
+
+func (q *FakeQuerier) GetWorkspaceBuildWithTemplateVersionByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceBuildWithTemplateVersionByIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	build, err := q.getWorkspaceBuildByIDNoLock(ctx, id)
+	if err != nil {
+		return database.GetWorkspaceBuildWithTemplateVersionByIDRow{}, err
+	}
+	version, err := q.getTemplateVersionByIDNoLock(ctx, build.TemplateVersionID)
+	if err != nil {
+		return database.GetWorkspaceBuildWithTemplateVersionByIDRow{}, err
+	}
+	return database.GetWorkspaceBuildWithTemplateVersionByIDRow{
+		WorkspaceBuild:      build,
+		TemplateVersionName: version.Name,
+	}, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceBuildsByWorkspaceID(_ context.Context,
 	params database.GetWorkspaceBuildsByWorkspaceIDParams,
 ) ([]database.WorkspaceBuild, error) {
@@ -3124,9 +3769,14 @@ func (q *FakeQuerier) GetWorkspaceBuildsByWorkspaceID(_ context.Context,
 		if workspaceBuild.CreatedAt.Before(params.Since) {
 			continue
 		}
-		if workspaceBuild.WorkspaceID == params.WorkspaceID {
-			history = append(history, q.workspaceBuildWithUserNoLock(workspaceBuild))
+		if workspaceBuild.WorkspaceID != params.WorkspaceID {
+			continue
+		}
+		build := q.workspaceBuildWithUserNoLock(workspaceBuild)
+		if params.InitiatorUsername != "" && !strings.EqualFold(build.InitiatorByUsername, params.InitiatorUsername) {
+			continue
 		}
+		history = append(history, build)
 	}
 
 	// Order by build_number
@@ -3172,15 +3822,22 @@ func (q *FakeQuerier) GetWorkspaceBuildsByWorkspaceID(_ context.Context,
 	return history, nil
 }
 
-func (q *FakeQuerier) GetWorkspaceBuildsCreatedAfter(_ context.Context, after time.Time) ([]database.WorkspaceBuild, error) {
+func (q *FakeQuerier) GetWorkspaceBuildsCreatedAfter(_ context.Context, arg database.GetWorkspaceBuildsCreatedAfterParams) ([]database.WorkspaceBuild, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	workspaceBuilds := make([]database.WorkspaceBuild, 0)
 	for _, workspaceBuild := range q.workspaceBuilds {
-		if workspaceBuild.CreatedAt.After(after) {
-			workspaceBuilds = append(workspaceBuilds, q.workspaceBuildWithUserNoLock(workspaceBuild))
+		if !workspaceBuild.CreatedAt.After(arg.CreatedAt) {
+			continue
+		}
+		if arg.Transition != "" && string(workspaceBuild.Transition) != arg.Transition {
+			continue
+		}
+		if arg.Reason != "" && string(workspaceBuild.Reason) != arg.Reason {
+			continue
 		}
+		workspaceBuilds = append(workspaceBuilds, q.workspaceBuildWithUserNoLock(workspaceBuild))
 	}
 	return workspaceBuilds, nil
 }
@@ -3216,7 +3873,7 @@ func (q *FakeQuerier) GetWorkspaceByOwnerIDAndName(_ context.Context, arg databa
 		if !strings.EqualFold(workspace.Name, arg.Name) {
 			continue
 		}
-		if workspace.Deleted != arg.Deleted {
+		if !arg.IncludeDeleted && workspace.Deleted != arg.Deleted {
 			continue
 		}
 
@@ -3248,17 +3905,38 @@ func (q *FakeQuerier) GetWorkspaceByWorkspaceAppID(_ context.Context, workspaceA
 	return database.Workspace{}, sql.ErrNoRows
 }
 
-func (q *FakeQuerier) GetWorkspaceProxies(_ context.Context) ([]database.WorkspaceProxy, error) {
+func (q *FakeQuerier) GetWorkspaceCountByOwner(_ context.Context, ownerID uuid.UUID) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var count int64
+	for _, workspace := range q.workspaces {
+		if workspace.OwnerID == ownerID && !workspace.Deleted {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *FakeQuerier) GetWorkspaceProxies(_ context.Context, search string) ([]database.WorkspaceProxy, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	cpy := make([]database.WorkspaceProxy, 0, len(q.workspaceProxies))
 
+	search = strings.ToLower(search)
 	for _, p := range q.workspaceProxies {
-		if !p.Deleted {
-			cpy = append(cpy, p)
+		if p.Deleted {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(p.DisplayName), search) {
+			continue
 		}
+		cpy = append(cpy, p)
 	}
+	slices.SortFunc(cpy, func(a, b database.WorkspaceProxy) bool {
+		return a.Name < b.Name
+	})
 	return cpy, nil
 }
 
@@ -3325,6 +4003,21 @@ func (q *FakeQuerier) GetWorkspaceProxyByName(_ context.Context, name string) (d
 	return database.WorkspaceProxy{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceProxyByTokenHash(_ context.Context, hash []byte) (database.WorkspaceProxy, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, proxy := range q.workspaceProxies {
+		if proxy.Deleted {
+			continue
+		}
+		if bytes.Equal(proxy.TokenHashedSecret, hash) {
+			return proxy, nil
+		}
+	}
+	return database.WorkspaceProxy{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetWorkspaceResourceByID(_ context.Context, id uuid.UUID) (database.WorkspaceResource, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -3426,6 +4119,14 @@ func (q *FakeQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, no
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	// Many workspaces usually share few templates, so index them once
+	// instead of scanning all templates for every workspace.
+	q.templateLookups.Add(1)
+	templates := make(map[uuid.UUID]database.TemplateTable, len(q.templates))
+	for _, template := range q.templates {
+		templates[template.ID] = template
+	}
+
 	workspaces := []database.Workspace{}
 	for _, workspace := range q.workspaces {
 		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
@@ -3433,6 +4134,15 @@ func (q *FakeQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, no
 			return nil, err
 		}
 
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			return nil, xerrors.Errorf("get provisioner job by ID: %w", err)
+		}
+		// Skip workspaces whose latest build is still in progress.
+		if !job.CompletedAt.Valid {
+			continue
+		}
+
 		if build.Transition == database.WorkspaceTransitionStart &&
 			!build.Deadline.IsZero() &&
 			build.Deadline.Before(now) &&
@@ -3448,18 +4158,14 @@ func (q *FakeQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, no
 			continue
 		}
 
-		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
-		if err != nil {
-			return nil, xerrors.Errorf("get provisioner job by ID: %w", err)
-		}
 		if db2sdk.ProvisionerJobStatus(job) == codersdk.ProvisionerJobFailed {
 			workspaces = append(workspaces, workspace)
 			continue
 		}
 
-		template, err := q.GetTemplateByID(ctx, workspace.TemplateID)
-		if err != nil {
-			return nil, xerrors.Errorf("get template by ID: %w", err)
+		template, ok := templates[workspace.TemplateID]
+		if !ok {
+			return nil, xerrors.Errorf("get template by ID: %w", sql.ErrNoRows)
 		}
 		if !workspace.LockedAt.Valid && template.InactivityTTL > 0 {
 			workspaces = append(workspaces, workspace)
@@ -3661,6 +4367,17 @@ func (q *FakeQuerier) InsertGroupMember(_ context.Context, arg database.InsertGr
 		}
 	}
 
+	groupExists := false
+	for _, group := range q.groups {
+		if group.ID == arg.GroupID {
+			groupExists = true
+			break
+		}
+	}
+	if !groupExists {
+		return errForeignKeyConstraint
+	}
+
 	//nolint:gosimple
 	q.groupMembers = append(q.groupMembers, database.GroupMember{
 		GroupID: arg.GroupID,
@@ -3743,6 +4460,7 @@ func (q *FakeQuerier) InsertProvisionerDaemon(_ context.Context, arg database.In
 		Name:         arg.Name,
 		Provisioners: arg.Provisioners,
 		Tags:         arg.Tags,
+		LastSeenAt:   sql.NullTime{Time: arg.CreatedAt, Valid: true},
 	}
 	q.provisionerDaemons = append(q.provisionerDaemons, daemon)
 	return daemon, nil
@@ -3756,6 +4474,20 @@ func (q *FakeQuerier) InsertProvisionerJob(_ context.Context, arg database.Inser
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
+	if q.validateProvisionerJobInput {
+		if err := validateProvisionerJobInput(arg.Type, arg.Input); err != nil {
+			return database.ProvisionerJob{}, err
+		}
+	}
+
+	// Zero timestamps would break queries that filter on creation time.
+	if arg.CreatedAt.IsZero() {
+		arg.CreatedAt = database.Now()
+	}
+	if arg.UpdatedAt.Before(arg.CreatedAt) {
+		arg.UpdatedAt = arg.CreatedAt
+	}
+
 	job := database.ProvisionerJob{
 		ID:             arg.ID,
 		CreatedAt:      arg.CreatedAt,
@@ -3852,6 +4584,7 @@ func (q *FakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTempl
 		AllowUserCancelWorkspaceJobs: arg.AllowUserCancelWorkspaceJobs,
 		AllowUserAutostart:           true,
 		AllowUserAutostop:            true,
+		ProvisionerTags:              arg.ProvisionerTags,
 	}
 	q.templates = append(q.templates, template)
 	return nil
@@ -4096,12 +4829,14 @@ func (q *FakeQuerier) InsertWorkspaceAgentLogs(_ context.Context, arg database.I
 	defer q.mutex.Unlock()
 
 	logs := []database.WorkspaceAgentLog{}
-	id := int64(0)
-	if len(q.workspaceAgentLogs) > 0 {
-		id = q.workspaceAgentLogs[len(q.workspaceAgentLogs)-1].ID
-	}
+	// IDs come from a sequence in PostgreSQL, so they are never reused
+	// even after old logs are deleted.
+	id := q.lastWorkspaceAgentLogID
 	outputLength := int32(0)
 	for index, output := range arg.Output {
+		if q.maxWorkspaceAgentLogLineLength > 0 && len(output) > q.maxWorkspaceAgentLogLineLength {
+			return nil, xerrors.Errorf("log line %d is %d bytes, exceeding the maximum of %d bytes", index, len(output), q.maxWorkspaceAgentLogLineLength)
+		}
 		id++
 		logs = append(logs, database.WorkspaceAgentLog{
 			ID:        id,
@@ -4129,6 +4864,7 @@ func (q *FakeQuerier) InsertWorkspaceAgentLogs(_ context.Context, arg database.I
 		break
 	}
 	q.workspaceAgentLogs = append(q.workspaceAgentLogs, logs...)
+	q.lastWorkspaceAgentLogID = id
 	return logs, nil
 }
 
@@ -4150,6 +4886,33 @@ func (q *FakeQuerier) InsertWorkspaceAgentMetadata(_ context.Context, arg databa
 	return nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceAgentMetadataBatch(_ context.Context, arg database.InsertWorkspaceAgentMetadataBatchParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	for _, n := range []int{len(arg.DisplayName), len(arg.Script), len(arg.Timeout), len(arg.Interval)} {
+		if n != len(arg.Key) {
+			return xerrors.Errorf("metadata fields have mismatched lengths")
+		}
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, key := range arg.Key {
+		q.workspaceAgentMetadata = append(q.workspaceAgentMetadata, database.WorkspaceAgentMetadatum{
+			WorkspaceAgentID: arg.WorkspaceAgentID,
+			DisplayName:      arg.DisplayName[i],
+			Key:              key,
+			Script:           arg.Script[i],
+			Timeout:          arg.Timeout[i],
+			Interval:         arg.Interval[i],
+		})
+	}
+	return nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceAgentStat(_ context.Context, p database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	if err := validateDatabaseType(p); err != nil {
 		return database.WorkspaceAgentStat{}, err
@@ -4194,6 +4957,9 @@ func (q *FakeQuerier) InsertWorkspaceAgentStats(_ context.Context, arg database.
 	if err := json.Unmarshal(arg.ConnectionsByProto, &connectionsByProto); err != nil {
 		return err
 	}
+	if len(connectionsByProto) != len(arg.ID) {
+		return xerrors.Errorf("connections_by_proto has %d entries, expected %d", len(connectionsByProto), len(arg.ID))
+	}
 	for i := 0; i < len(arg.ID); i++ {
 		cbp, err := json.Marshal(connectionsByProto[i])
 		if err != nil {
@@ -4551,6 +5317,30 @@ func (q *FakeQuerier) UpdateMemberRoles(_ context.Context, arg database.UpdateMe
 	return database.OrganizationMember{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateProvisionerDaemonLastSeenAt(_ context.Context, arg database.UpdateProvisionerDaemonLastSeenAtParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, daemon := range q.provisionerDaemons {
+		if daemon.ID != arg.ID {
+			continue
+		}
+		// Never move last_seen_at backwards.
+		if daemon.LastSeenAt.Valid && daemon.LastSeenAt.Time.After(arg.LastSeenAt.Time) {
+			return nil
+		}
+		daemon.LastSeenAt = arg.LastSeenAt
+		q.provisionerDaemons[index] = daemon
+		return nil
+	}
+	// An :exec query updating no rows is not an error.
+	return nil
+}
+
 func (q *FakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4693,6 +5483,18 @@ func (q *FakeQuerier) UpdateTemplateDeletedByID(_ context.Context, arg database.
 		if template.ID != arg.ID {
 			continue
 		}
+		if template.Deleted && !arg.Deleted {
+			// Restoring a template must not collide with a live template of
+			// the same name in the organization.
+			for _, other := range q.templates {
+				if other.Deleted || other.ID == template.ID || other.OrganizationID != template.OrganizationID {
+					continue
+				}
+				if strings.EqualFold(other.Name, template.Name) {
+					return errDuplicateKey
+				}
+			}
+		}
 		template.Deleted = arg.Deleted
 		template.UpdatedAt = arg.UpdatedAt
 		q.templates[index] = template
@@ -4747,6 +5549,7 @@ func (q *FakeQuerier) UpdateTemplateScheduleByID(_ context.Context, arg database
 		tpl.FailureTTL = arg.FailureTTL
 		tpl.InactivityTTL = arg.InactivityTTL
 		tpl.LockedTTL = arg.LockedTTL
+		tpl.AutobuildPaused = arg.AutobuildPaused
 		q.templates[idx] = tpl
 		return nil
 	}
@@ -5815,6 +6618,7 @@ func (q *FakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 	preloadedWorkspaceBuilds := map[uuid.UUID]database.WorkspaceBuild{}
 	preloadedProvisionerJobs := map[uuid.UUID]database.ProvisionerJob{}
 	preloadedUsers := map[uuid.UUID]database.User{}
+	preloadedTemplateNames := map[uuid.UUID]string{}
 
 	for _, w := range workspaces {
 		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, w.ID)
@@ -5837,12 +6641,33 @@ func (q *FakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 		} else if !errors.Is(err, sql.ErrNoRows) {
 			return nil, xerrors.Errorf("get user: %w", err)
 		}
+
+		preloadedTemplateNames[w.ID] = "unknown"
+		template, err := q.getTemplateByIDNoLock(ctx, w.TemplateID)
+		if err == nil {
+			preloadedTemplateNames[w.ID] = template.Name
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("get template: %w", err)
+		}
 	}
 
 	sort.Slice(workspaces, func(i, j int) bool {
 		w1 := workspaces[i]
 		w2 := workspaces[j]
 
+		// Order by: template names, then workspace names
+		if database.WorkspaceSortBy(arg.SortBy) == database.WorkspaceSortByTemplate {
+			t1 := strings.ToLower(preloadedTemplateNames[w1.ID])
+			t2 := strings.ToLower(preloadedTemplateNames[w2.ID])
+			if t1 != t2 {
+				return t1 < t2
+			}
+			n1, n2 := strings.ToLower(w1.Name), strings.ToLower(w2.Name)
+			if n1 != n2 {
+				return n1 < n2
+			}
+		}
+
 		// Order by: running first
 		w1IsRunning := isRunning(preloadedWorkspaceBuilds[w1.ID], preloadedProvisionerJobs[w1.ID])
 		w2IsRunning := isRunning(preloadedWorkspaceBuilds[w2.ID], preloadedProvisionerJobs[w2.ID])
@@ -5916,3 +6741,27 @@ func (q *FakeQuerier) GetAuthorizedUsers(ctx context.Context, arg database.GetUs
 	}
 	return filteredUsers, nil
 }
+
+func (q *FakeQuerier) GetWorkspaceAgentLogsAfterWithGap(ctx context.Context, arg database.GetWorkspaceAgentLogsAfterParams) (database.WorkspaceAgentLogsAfter, error) {
+	logs, err := q.GetWorkspaceAgentLogsAfter(ctx, arg)
+	if err != nil {
+		return database.WorkspaceAgentLogsAfter{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var oldest int64
+	for _, log := range q.workspaceAgentLogs {
+		if log.AgentID != arg.AgentID {
+			continue
+		}
+		if oldest == 0 || log.ID < oldest {
+			oldest = log.ID
+		}
+	}
+	return database.WorkspaceAgentLogsAfter{
+		Logs: logs,
+		Gap:  arg.CreatedAfter > 0 && (oldest == 0 || oldest > arg.CreatedAfter),
+	}, nil
+}
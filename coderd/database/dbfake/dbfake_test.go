@@ -2,17 +2,24 @@ package dbfake_test
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbfake"
 	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/codersdk"
 )
 
 // test that transactions don't deadlock, and that we don't see intermediate state.
@@ -207,3 +214,2402 @@ func TestProxyByHostname(t *testing.T) {
 		})
 	}
 }
+
+// TestProvisionerLogsAfterIDLimit ensures that logs can be paged through using
+// the last ID of the previous page as the cursor.
+func TestProvisionerLogsAfterIDLimit(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+
+	const count = 25
+	params := database.InsertProvisionerJobLogsParams{
+		JobID: job.ID,
+	}
+	for i := 0; i < count; i++ {
+		params.CreatedAt = append(params.CreatedAt, database.Now())
+		params.Source = append(params.Source, database.LogSourceProvisioner)
+		params.Level = append(params.Level, database.LogLevelInfo)
+		params.Stage = append(params.Stage, "stage")
+		params.Output = append(params.Output, fmt.Sprintf("log %d", i))
+	}
+	inserted, err := db.InsertProvisionerJobLogs(ctx, params)
+	require.NoError(t, err)
+	require.Len(t, inserted, count)
+
+	all, err := db.GetProvisionerLogsAfterID(ctx, database.GetProvisionerLogsAfterIDParams{
+		JobID: job.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, all, count, "zero limit returns all logs")
+
+	var (
+		paged []database.ProvisionerJobLog
+		after int64
+		pages int
+	)
+	for {
+		page, err := db.GetProvisionerLogsAfterID(ctx, database.GetProvisionerLogsAfterIDParams{
+			JobID:        job.ID,
+			CreatedAfter: after,
+			Limit:        10,
+		})
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		require.LessOrEqual(t, len(page), 10)
+		paged = append(paged, page...)
+		after = page[len(page)-1].ID
+		pages++
+	}
+	require.Equal(t, 3, pages)
+	require.Equal(t, inserted, paged)
+}
+
+func TestProvisionerJobWithLogTail(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+	other := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+
+	insertLogs := func(jobID uuid.UUID, count int) []database.ProvisionerJobLog {
+		params := database.InsertProvisionerJobLogsParams{
+			JobID: jobID,
+		}
+		for i := 0; i < count; i++ {
+			params.CreatedAt = append(params.CreatedAt, database.Now())
+			params.Source = append(params.Source, database.LogSourceProvisioner)
+			params.Level = append(params.Level, database.LogLevelInfo)
+			params.Stage = append(params.Stage, "stage")
+			params.Output = append(params.Output, fmt.Sprintf("log %d", i))
+		}
+		logs, err := db.InsertProvisionerJobLogs(ctx, params)
+		require.NoError(t, err)
+		return logs
+	}
+	inserted := insertLogs(job.ID, 10)
+	// Logs for other jobs must not show up in the tail.
+	_ = insertLogs(other.ID, 3)
+
+	tail, err := db.GetProvisionerJobWithLogTail(ctx, database.GetProvisionerJobWithLogTailParams{
+		ID:        job.ID,
+		TailLines: 3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, job.ID, tail.ProvisionerJob.ID)
+	require.Equal(t, inserted[7:], tail.Logs)
+
+	tail, err = db.GetProvisionerJobWithLogTail(ctx, database.GetProvisionerJobWithLogTailParams{
+		ID:        job.ID,
+		TailLines: 50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, inserted, tail.Logs)
+
+	_, err = db.GetProvisionerJobWithLogTail(ctx, database.GetProvisionerJobWithLogTailParams{
+		ID:        uuid.New(),
+		TailLines: 3,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestCancelPendingProvisionerJobs ensures that only jobs which have not been
+// acquired by a provisioner are canceled.
+func TestCancelPendingProvisionerJobs(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := database.Now()
+
+	// Started jobs are inserted first so acquiring them doesn't pick up one
+	// of the pending jobs.
+	running := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		StartedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	completed := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		StartedAt:   sql.NullTime{Time: now, Valid: true},
+		CompletedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	pending := []database.ProvisionerJob{
+		dbgen.ProvisionerJob(t, db, database.ProvisionerJob{}),
+		dbgen.ProvisionerJob(t, db, database.ProvisionerJob{}),
+	}
+
+	canceledAt := now.Add(time.Minute)
+	count, err := db.CancelPendingProvisionerJobs(ctx, canceledAt)
+	require.NoError(t, err)
+	require.EqualValues(t, len(pending), count)
+
+	for _, job := range pending {
+		job, err := db.GetProvisionerJobByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.True(t, job.CanceledAt.Valid)
+		require.Equal(t, canceledAt, job.CanceledAt.Time)
+		require.True(t, job.CompletedAt.Valid)
+	}
+	for _, job := range []database.ProvisionerJob{running, completed} {
+		got, err := db.GetProvisionerJobByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.False(t, got.CanceledAt.Valid)
+		require.Equal(t, job.CompletedAt, got.CompletedAt)
+	}
+
+	// Jobs that are already canceled are not counted again.
+	count, err = db.CancelPendingProvisionerJobs(ctx, canceledAt)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+// TestTemplateAverageBuildTimeCounts ensures the sample counts match the number
+// of successfully completed builds of each transition.
+func TestTemplateAverageBuildTimeCounts(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := database.Now()
+
+	tpl := dbgen.Template(t, db, database.Template{})
+	version := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		TemplateID: uuid.NullUUID{UUID: tpl.ID, Valid: true},
+	})
+
+	build := func(transition database.WorkspaceTransition, job database.ProvisionerJob) {
+		// Tag each job so acquiring it doesn't pick up any other job.
+		job.Tags = database.StringMap{uuid.NewString(): "true"}
+		job = dbgen.ProvisionerJob(t, db, job)
+		dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			TemplateVersionID: version.ID,
+			JobID:             job.ID,
+			Transition:        transition,
+		})
+	}
+	completed := database.ProvisionerJob{
+		StartedAt:   sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+		CompletedAt: sql.NullTime{Time: now, Valid: true},
+	}
+	for i := 0; i < 3; i++ {
+		build(database.WorkspaceTransitionStart, completed)
+	}
+	build(database.WorkspaceTransitionStop, completed)
+	// Failed and canceled builds are not counted.
+	failed := completed
+	failed.Error = sql.NullString{String: "failed", Valid: true}
+	build(database.WorkspaceTransitionStop, failed)
+	canceled := completed
+	canceled.CanceledAt = sql.NullTime{Time: now, Valid: true}
+	build(database.WorkspaceTransitionDelete, canceled)
+
+	row, err := db.GetTemplateAverageBuildTime(ctx, database.GetTemplateAverageBuildTimeParams{
+		TemplateID: uuid.NullUUID{UUID: tpl.ID, Valid: true},
+		StartTime:  sql.NullTime{Time: now.Add(-time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, row.StartCount)
+	require.EqualValues(t, 1, row.StopCount)
+	require.EqualValues(t, 0, row.DeleteCount)
+	require.Equal(t, float64(-1), row.Delete50)
+}
+
+// TestTemplateInsightsMedianSessionDuration ensures contiguous usage intervals
+// are coalesced into sessions when computing the median session duration.
+func TestTemplateInsightsMedianSessionDuration(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	templateID := uuid.New()
+
+	stat := func(userID uuid.UUID, interval int) {
+		dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+			CreatedAt:       start.Add(time.Duration(interval)*5*time.Minute + time.Minute),
+			UserID:          userID,
+			TemplateID:      templateID,
+			ConnectionCount: 1,
+			SessionCountSSH: 1,
+		})
+	}
+
+	// The first user has a 15 minute session followed by a 5 minute session.
+	first := uuid.New()
+	for _, interval := range []int{0, 1, 2, 4} {
+		stat(first, interval)
+	}
+	// The second user has a single 10 minute session.
+	second := uuid.New()
+	for _, interval := range []int{0, 1} {
+		stat(second, interval)
+	}
+
+	row, err := db.GetTemplateInsights(context.Background(), database.GetTemplateInsightsParams{
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		TemplateIDs: []uuid.UUID{templateID},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, row.ActiveUsers)
+	require.EqualValues(t, 6*300, row.UsageSshSeconds)
+	require.EqualValues(t, 600, row.MedianSessionDurationSeconds)
+
+	// A third user with a 20 minute session makes the number of sessions
+	// even, so the median is interpolated between the middle two.
+	third := uuid.New()
+	for _, interval := range []int{6, 7, 8, 9} {
+		stat(third, interval)
+	}
+	row, err = db.GetTemplateInsights(context.Background(), database.GetTemplateInsightsParams{
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		TemplateIDs: []uuid.UUID{templateID},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 750, row.MedianSessionDurationSeconds)
+}
+
+// TestTemplateInsightsMinActiveSeconds ensures users below the usage threshold
+// are not counted as active.
+func TestTemplateInsightsMinActiveSeconds(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	templateID := uuid.New()
+
+	stat := func(userID uuid.UUID, interval int) {
+		dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+			CreatedAt:       start.Add(time.Duration(interval)*5*time.Minute + time.Minute),
+			UserID:          userID,
+			TemplateID:      templateID,
+			ConnectionCount: 1,
+			SessionCountSSH: 1,
+		})
+	}
+
+	// The barely active user connected once, the heavily active user for
+	// 30 minutes.
+	stat(uuid.New(), 0)
+	heavy := uuid.New()
+	for interval := 0; interval < 6; interval++ {
+		stat(heavy, interval)
+	}
+
+	for _, tc := range []struct {
+		minActiveSeconds int64
+		activeUsers      int64
+	}{
+		{minActiveSeconds: 0, activeUsers: 2},
+		{minActiveSeconds: 300, activeUsers: 2},
+		{minActiveSeconds: 600, activeUsers: 1},
+		{minActiveSeconds: 1800, activeUsers: 1},
+		{minActiveSeconds: 3600, activeUsers: 0},
+	} {
+		row, err := db.GetTemplateInsights(context.Background(), database.GetTemplateInsightsParams{
+			StartTime:        start,
+			EndTime:          start.Add(time.Hour),
+			TemplateIDs:      []uuid.UUID{templateID},
+			MinActiveSeconds: tc.minActiveSeconds,
+		})
+		require.NoError(t, err)
+		require.Equal(t, tc.activeUsers, row.ActiveUsers, "min active seconds %d", tc.minActiveSeconds)
+		// Usage is reported regardless of the threshold.
+		require.EqualValues(t, 7*300, row.UsageSshSeconds)
+	}
+}
+
+// TestWorkspaceBuildWithTemplateVersionByID ensures the template version name
+// is resolved alongside the build.
+func TestWorkspaceBuildWithTemplateVersionByID(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	version := dbgen.TemplateVersion(t, db, database.TemplateVersion{Name: "beautiful-version"})
+	build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{TemplateVersionID: version.ID})
+
+	row, err := db.GetWorkspaceBuildWithTemplateVersionByID(ctx, build.ID)
+	require.NoError(t, err)
+	require.Equal(t, build, row.WorkspaceBuild)
+	require.Equal(t, "beautiful-version", row.TemplateVersionName)
+
+	_, err = db.GetWorkspaceBuildWithTemplateVersionByID(ctx, uuid.New())
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// TestDeploymentWorkspaceStatsBuildingWindow ensures a running job is only
+// considered building if it was updated within the building window.
+func TestDeploymentWorkspaceStatsBuildingWindow(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	// Acquiring the job sets the last update to the start time.
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		StartedAt: sql.NullTime{Time: database.Now().Add(-20 * time.Second), Valid: true},
+	})
+	workspace := dbgen.Workspace(t, db, database.Workspace{})
+	dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID: workspace.ID,
+		JobID:       job.ID,
+	})
+
+	for _, c := range []struct {
+		windowSeconds int32
+		building      int64
+	}{
+		{windowSeconds: 0, building: 1},
+		{windowSeconds: 10, building: 0},
+		{windowSeconds: 19, building: 0},
+		{windowSeconds: 21, building: 1},
+		{windowSeconds: 60, building: 1},
+	} {
+		stats, err := db.GetDeploymentWorkspaceStats(ctx, c.windowSeconds)
+		require.NoError(t, err)
+		require.Equal(t, c.building, stats.BuildingWorkspaces, "window %ds", c.windowSeconds)
+	}
+}
+
+// TestWorkspaceAgentsInLatestBuildByResourceType ensures agents can be filtered
+// by the type of the resource they are on.
+func TestWorkspaceAgentsInLatestBuildByResourceType(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	workspace := dbgen.Workspace(t, db, database.Workspace{})
+	build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{WorkspaceID: workspace.ID})
+	disk := dbgen.WorkspaceResource(t, db, database.WorkspaceResource{
+		JobID: build.JobID,
+		Type:  "google_compute_disk",
+	})
+	pod := dbgen.WorkspaceResource(t, db, database.WorkspaceResource{
+		JobID: build.JobID,
+		Type:  "kubernetes_pod",
+	})
+	diskAgent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{ResourceID: disk.ID})
+	podAgent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{ResourceID: pod.ID})
+
+	agents, err := db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams{
+		WorkspaceID: workspace.ID,
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []database.WorkspaceAgent{diskAgent, podAgent}, agents)
+
+	agents, err = db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams{
+		WorkspaceID:  workspace.ID,
+		ResourceType: "kubernetes_pod",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []database.WorkspaceAgent{podAgent}, agents)
+
+	agents, err = db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams{
+		WorkspaceID:  workspace.ID,
+		ResourceType: "docker_container",
+	})
+	require.NoError(t, err)
+	require.Empty(t, agents)
+}
+
+// TestAcquireProvisionerJobTemplateTags ensures builds of a template that
+// requires provisioner tags are only acquired by a matching daemon.
+func TestAcquireProvisionerJobTemplateTags(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	tpl := dbgen.Template(t, db, database.Template{
+		ProvisionerTags: database.StringMap{"region": "eu"},
+	})
+	version := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		TemplateID: uuid.NullUUID{UUID: tpl.ID, Valid: true},
+	})
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		Type: database.ProvisionerJobTypeWorkspaceBuild,
+	})
+	dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		TemplateVersionID: version.ID,
+		JobID:             job.ID,
+	})
+
+	acquire := func(tags map[string]string) (database.ProvisionerJob, error) {
+		rawTags, err := json.Marshal(tags)
+		require.NoError(t, err)
+		return db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			StartedAt: sql.NullTime{Time: database.Now(), Valid: true},
+			Types:     []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Tags:      rawTags,
+		})
+	}
+
+	_, err := acquire(map[string]string{})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = acquire(map[string]string{"region": "us"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	acquired, err := acquire(map[string]string{"region": "eu"})
+	require.NoError(t, err)
+	require.Equal(t, job.ID, acquired.ID)
+}
+
+// TestTemplateVersionsWithStatusByIDs ensures the import job status is
+// resolved for each template version.
+func TestTemplateVersionsWithStatusByIDs(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := database.Now()
+
+	succeededJob := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		Type:        database.ProvisionerJobTypeTemplateVersionImport,
+		StartedAt:   sql.NullTime{Time: now, Valid: true},
+		CompletedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	failedJob := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		Type:        database.ProvisionerJobTypeTemplateVersionImport,
+		StartedAt:   sql.NullTime{Time: now, Valid: true},
+		CompletedAt: sql.NullTime{Time: now, Valid: true},
+		Error:       sql.NullString{String: "import failed", Valid: true},
+	})
+	succeeded := dbgen.TemplateVersion(t, db, database.TemplateVersion{JobID: succeededJob.ID})
+	failed := dbgen.TemplateVersion(t, db, database.TemplateVersion{JobID: failedJob.ID})
+
+	rows, err := db.GetTemplateVersionsWithStatusByIDs(ctx, []uuid.UUID{succeeded.ID, failed.ID})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	statuses := make(map[uuid.UUID]codersdk.ProvisionerJobStatus)
+	for _, row := range rows {
+		statuses[row.TemplateVersion.ID] = codersdk.ProvisionerJobStatus(row.JobStatus)
+	}
+	require.Equal(t, codersdk.ProvisionerJobSucceeded, statuses[succeeded.ID])
+	require.Equal(t, codersdk.ProvisionerJobFailed, statuses[failed.ID])
+}
+
+// TestDeploymentDAUsOrdering ensures users within a single date are always
+// returned in the same order.
+func TestDeploymentDAUsOrdering(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := database.Now()
+
+	for i := 0; i < 10; i++ {
+		dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+			CreatedAt:       now,
+			ConnectionCount: 1,
+		})
+	}
+
+	expected, err := db.GetDeploymentDAUs(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, expected, 10)
+	require.True(t, sort.SliceIsSorted(expected, func(i, j int) bool {
+		return expected[i].UserID.String() < expected[j].UserID.String()
+	}))
+
+	for i := 0; i < 10; i++ {
+		rows, err := db.GetDeploymentDAUs(ctx, 0)
+		require.NoError(t, err)
+		require.Equal(t, expected, rows)
+	}
+}
+
+// TestUserCreatedAtFilter ensures GetUsers can be filtered to users created
+// within a time range.
+func TestUserCreatedAtFilter(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := database.Now()
+
+	lastMonth := dbgen.User(t, db, database.User{CreatedAt: now.AddDate(0, -1, 0)})
+	lastWeek := dbgen.User(t, db, database.User{CreatedAt: now.AddDate(0, 0, -7)})
+	today := dbgen.User(t, db, database.User{CreatedAt: now})
+
+	rows, err := db.GetUsers(ctx, database.GetUsersParams{
+		CreatedAfter: now.AddDate(0, 0, -14),
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{lastWeek.ID, today.ID}, userIDs(rows))
+
+	rows, err = db.GetUsers(ctx, database.GetUsersParams{
+		CreatedBefore: now.AddDate(0, 0, -1),
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{lastMonth.ID, lastWeek.ID}, userIDs(rows))
+
+	rows, err = db.GetUsers(ctx, database.GetUsersParams{
+		CreatedAfter:  now.AddDate(0, 0, -14),
+		CreatedBefore: now.AddDate(0, 0, -1),
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{lastWeek.ID}, userIDs(rows))
+}
+
+// TestUserLoginTypeFilter ensures GetUsers can be filtered by login type.
+func TestUserLoginTypeFilter(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	password := dbgen.User(t, db, database.User{LoginType: database.LoginTypePassword})
+	oidc := dbgen.User(t, db, database.User{LoginType: database.LoginTypeOIDC})
+	github := dbgen.User(t, db, database.User{LoginType: database.LoginTypeGithub})
+
+	rows, err := db.GetUsers(ctx, database.GetUsersParams{
+		LoginType: []database.LoginType{database.LoginTypeOIDC},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{oidc.ID}, userIDs(rows))
+
+	rows, err = db.GetUsers(ctx, database.GetUsersParams{
+		LoginType: []database.LoginType{database.LoginTypePassword, database.LoginTypeOIDC},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{password.ID, oidc.ID}, userIDs(rows))
+
+	rows, err = db.GetUsers(ctx, database.GetUsersParams{})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{password.ID, oidc.ID, github.ID}, userIDs(rows))
+}
+
+func userIDs(rows []database.GetUsersRow) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	return ids
+}
+
+// TestActiveUserCountSince ensures only active users seen after the cutoff
+// are counted.
+func TestActiveUserCountSince(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := database.Now()
+
+	dbgen.User(t, db, database.User{
+		LastSeenAt: now.AddDate(0, 0, -1),
+	})
+	dbgen.User(t, db, database.User{
+		LastSeenAt: now.AddDate(0, 0, -10),
+	})
+	dbgen.User(t, db, database.User{
+		LastSeenAt: now.AddDate(0, 0, -90),
+	})
+	suspended := dbgen.User(t, db, database.User{
+		LastSeenAt: now.AddDate(0, 0, -1),
+	})
+	_, err := db.UpdateUserStatus(ctx, database.UpdateUserStatusParams{
+		ID:        suspended.ID,
+		Status:    database.UserStatusSuspended,
+		UpdatedAt: now,
+	})
+	require.NoError(t, err)
+
+	count, err := db.GetActiveUserCountSince(ctx, now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+
+	count, err = db.GetActiveUserCountSince(ctx, now.AddDate(0, 0, -5))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+
+	count, err = db.GetActiveUserCount(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, count)
+}
+
+// TestWorkspaceProxyByTokenHash ensures proxies can be looked up by the hash
+// of their token, and that deleted proxies are ignored.
+func TestWorkspaceProxyByTokenHash(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	proxy, secret := dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{})
+	hashedSecret := sha256.Sum256([]byte(secret))
+
+	found, err := db.GetWorkspaceProxyByTokenHash(ctx, hashedSecret[:])
+	require.NoError(t, err)
+	require.Equal(t, proxy.ID, found.ID)
+
+	unknown := sha256.Sum256([]byte("not-a-proxy-token"))
+	_, err = db.GetWorkspaceProxyByTokenHash(ctx, unknown[:])
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = db.UpdateWorkspaceProxyDeleted(ctx, database.UpdateWorkspaceProxyDeletedParams{
+		ID:      proxy.ID,
+		Deleted: true,
+	})
+	require.NoError(t, err)
+	_, err = db.GetWorkspaceProxyByTokenHash(ctx, hashedSecret[:])
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestProvisionerJobQueuePositionByProvisioner(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	echo1 := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{Provisioner: database.ProvisionerTypeEcho})
+	terraform1 := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{Provisioner: database.ProvisionerTypeTerraform})
+	echo2 := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{Provisioner: database.ProvisionerTypeEcho})
+	terraform2 := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{Provisioner: database.ProvisionerTypeTerraform})
+	terraform3 := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{Provisioner: database.ProvisionerTypeTerraform})
+
+	rows, err := db.GetProvisionerJobsByIDsWithQueuePosition(ctx, []uuid.UUID{
+		echo1.ID, terraform1.ID, echo2.ID, terraform2.ID, terraform3.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+
+	type position struct {
+		position int64
+		size     int64
+	}
+	expected := map[uuid.UUID]position{
+		echo1.ID:      {position: 1, size: 2},
+		echo2.ID:      {position: 2, size: 2},
+		terraform1.ID: {position: 1, size: 3},
+		terraform2.ID: {position: 2, size: 3},
+		terraform3.ID: {position: 3, size: 3},
+	}
+	for _, row := range rows {
+		want := expected[row.ProvisionerJob.ID]
+		require.Equal(t, want.position, row.QueuePosition, "job %s", row.ProvisionerJob.Provisioner)
+		require.Equal(t, want.size, row.QueueSize, "job %s", row.ProvisionerJob.Provisioner)
+	}
+}
+
+func TestInsertProvisionerJobDefaultTimestamps(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	before := database.Now()
+	job, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:            uuid.New(),
+		Provisioner:   database.ProvisionerTypeEcho,
+		StorageMethod: database.ProvisionerStorageMethodFile,
+		Type:          database.ProvisionerJobTypeWorkspaceBuild,
+		Input:         []byte("{}"),
+	})
+	require.NoError(t, err)
+	require.False(t, job.CreatedAt.IsZero())
+	require.False(t, job.CreatedAt.Before(before))
+	require.False(t, job.UpdatedAt.Before(job.CreatedAt))
+
+	jobs, err := db.GetProvisionerJobsCreatedAfter(ctx, database.GetProvisionerJobsCreatedAfterParams{
+		CreatedAt: before.Add(-time.Second),
+	})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, job.ID, jobs[0].ID)
+
+	// An UpdatedAt older than CreatedAt is clamped.
+	createdAt := database.Now()
+	job, err = db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+		ID:            uuid.New(),
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt.Add(-time.Hour),
+		Provisioner:   database.ProvisionerTypeEcho,
+		StorageMethod: database.ProvisionerStorageMethodFile,
+		Type:          database.ProvisionerJobTypeWorkspaceBuild,
+		Input:         []byte("{}"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, createdAt, job.CreatedAt)
+	require.Equal(t, createdAt, job.UpdatedAt)
+}
+
+func TestWorkspaceBuildsCreatedAfterFilters(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	after := database.Now().Add(-time.Hour)
+	autostart := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		Transition: database.WorkspaceTransitionStart,
+		Reason:     database.BuildReasonAutostart,
+	})
+	_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		Transition: database.WorkspaceTransitionStart,
+		Reason:     database.BuildReasonInitiator,
+	})
+	autostop := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		Transition: database.WorkspaceTransitionStop,
+		Reason:     database.BuildReasonAutostop,
+	})
+	// Builds before the cutoff are never returned.
+	_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		CreatedAt:  after.Add(-time.Hour),
+		Transition: database.WorkspaceTransitionStart,
+		Reason:     database.BuildReasonAutostart,
+	})
+
+	builds, err := db.GetWorkspaceBuildsCreatedAfter(ctx, database.GetWorkspaceBuildsCreatedAfterParams{
+		CreatedAt: after,
+	})
+	require.NoError(t, err)
+	require.Len(t, builds, 3)
+
+	builds, err = db.GetWorkspaceBuildsCreatedAfter(ctx, database.GetWorkspaceBuildsCreatedAfterParams{
+		CreatedAt:  after,
+		Transition: string(database.WorkspaceTransitionStart),
+	})
+	require.NoError(t, err)
+	require.Len(t, builds, 2)
+
+	builds, err = db.GetWorkspaceBuildsCreatedAfter(ctx, database.GetWorkspaceBuildsCreatedAfterParams{
+		CreatedAt:  after,
+		Transition: string(database.WorkspaceTransitionStart),
+		Reason:     string(database.BuildReasonAutostart),
+	})
+	require.NoError(t, err)
+	require.Len(t, builds, 1)
+	require.Equal(t, autostart.ID, builds[0].ID)
+
+	builds, err = db.GetWorkspaceBuildsCreatedAfter(ctx, database.GetWorkspaceBuildsCreatedAfterParams{
+		CreatedAt: after,
+		Reason:    string(database.BuildReasonAutostop),
+	})
+	require.NoError(t, err)
+	require.Len(t, builds, 1)
+	require.Equal(t, autostop.ID, builds[0].ID)
+}
+
+func TestGroupByOrgAndNameCaseInsensitive(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	group := dbgen.Group(t, db, database.Group{
+		OrganizationID: org.ID,
+		Name:           "Engineers",
+	})
+
+	_, err := db.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
+		OrganizationID: org.ID,
+		Name:           "engineers",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	found, err := db.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
+		OrganizationID:  org.ID,
+		Name:            "engineers",
+		CaseInsensitive: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, group.ID, found.ID)
+}
+
+func TestQuotaConsumedForUserIncludingPending(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	group := dbgen.Group(t, db, database.Group{
+		OrganizationID: org.ID,
+		QuotaAllowance: 10,
+	})
+	_ = dbgen.GroupMember(t, db, database.GroupMember{UserID: user.ID, GroupID: group.ID})
+	workspace := dbgen.Workspace(t, db, database.Workspace{
+		OwnerID:        user.ID,
+		OrganizationID: org.ID,
+	})
+	build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{WorkspaceID: workspace.ID})
+	err := db.UpdateWorkspaceBuildCostByID(ctx, database.UpdateWorkspaceBuildCostByIDParams{
+		ID:        build.ID,
+		DailyCost: 6,
+	})
+	require.NoError(t, err)
+
+	row, err := db.GetQuotaConsumedForUserIncludingPending(ctx, database.GetQuotaConsumedForUserIncludingPendingParams{
+		OwnerID:          user.ID,
+		PendingDailyCost: 4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), row.Consumed)
+	require.Equal(t, int64(10), row.Allowance)
+	require.False(t, row.ExceedsAllowance)
+
+	row, err = db.GetQuotaConsumedForUserIncludingPending(ctx, database.GetQuotaConsumedForUserIncludingPendingParams{
+		OwnerID:          user.ID,
+		PendingDailyCost: 5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(11), row.Consumed)
+	require.True(t, row.ExceedsAllowance)
+}
+
+func TestQuotaConsumedForUserOnlyRunning(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	for _, transition := range []database.WorkspaceTransition{
+		database.WorkspaceTransitionStart,
+		database.WorkspaceTransitionStop,
+	} {
+		workspace := dbgen.Workspace(t, db, database.Workspace{
+			OwnerID:        user.ID,
+			OrganizationID: org.ID,
+		})
+		build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID: workspace.ID,
+			Transition:  transition,
+		})
+		err := db.UpdateWorkspaceBuildCostByID(ctx, database.UpdateWorkspaceBuildCostByIDParams{
+			ID:        build.ID,
+			DailyCost: 5,
+		})
+		require.NoError(t, err)
+	}
+
+	consumed, err := db.GetQuotaConsumedForUser(ctx, database.GetQuotaConsumedForUserParams{
+		OwnerID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), consumed)
+
+	// The stopped workspace is excluded when only running workspaces count.
+	consumed, err = db.GetQuotaConsumedForUser(ctx, database.GetQuotaConsumedForUserParams{
+		OwnerID:     user.ID,
+		OnlyRunning: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), consumed)
+
+	row, err := db.GetQuotaConsumedForUserIncludingPending(ctx, database.GetQuotaConsumedForUserIncludingPendingParams{
+		OwnerID:          user.ID,
+		OnlyRunning:      true,
+		PendingDailyCost: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), row.Consumed)
+}
+
+func TestWorkspaceAppByAgentIDAndSlugExcludeExternal(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	// Slugs are unique per agent, so the external and internal apps belong to
+	// different agents.
+	externalAgent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	internalAgent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	external := dbgen.WorkspaceApp(t, db, database.WorkspaceApp{
+		AgentID:  externalAgent.ID,
+		Slug:     "code",
+		External: true,
+	})
+	internal := dbgen.WorkspaceApp(t, db, database.WorkspaceApp{
+		AgentID: internalAgent.ID,
+		Slug:    "code",
+	})
+
+	found, err := db.GetWorkspaceAppByAgentIDAndSlug(ctx, database.GetWorkspaceAppByAgentIDAndSlugParams{
+		AgentID: externalAgent.ID,
+		Slug:    "code",
+	})
+	require.NoError(t, err)
+	require.Equal(t, external.ID, found.ID)
+
+	_, err = db.GetWorkspaceAppByAgentIDAndSlug(ctx, database.GetWorkspaceAppByAgentIDAndSlugParams{
+		AgentID:         externalAgent.ID,
+		Slug:            "code",
+		ExcludeExternal: true,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	found, err = db.GetWorkspaceAppByAgentIDAndSlug(ctx, database.GetWorkspaceAppByAgentIDAndSlugParams{
+		AgentID:         internalAgent.ID,
+		Slug:            "code",
+		ExcludeExternal: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, internal.ID, found.ID)
+}
+
+func TestWorkspaceAppHealthInitializing(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	agent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	fresh := dbgen.WorkspaceApp(t, db, database.WorkspaceApp{
+		AgentID:              agent.ID,
+		HealthcheckInterval:  5,
+		HealthcheckThreshold: 6,
+		Health:               database.WorkspaceAppHealthInitializing,
+	})
+	// This app never reported a healthcheck result despite having had time
+	// to run all of its checks.
+	stale := dbgen.WorkspaceApp(t, db, database.WorkspaceApp{
+		AgentID:              agent.ID,
+		CreatedAt:            database.Now().Add(-time.Minute),
+		HealthcheckInterval:  5,
+		HealthcheckThreshold: 6,
+		Health:               database.WorkspaceAppHealthInitializing,
+	})
+	reported := dbgen.WorkspaceApp(t, db, database.WorkspaceApp{
+		AgentID:              agent.ID,
+		CreatedAt:            database.Now().Add(-time.Minute),
+		HealthcheckInterval:  5,
+		HealthcheckThreshold: 6,
+		Health:               database.WorkspaceAppHealthHealthy,
+	})
+	// Apps without a healthcheck are always disabled, whatever was stored.
+	noHealthcheck, err := db.InsertWorkspaceApp(ctx, database.InsertWorkspaceAppParams{
+		ID:           uuid.New(),
+		CreatedAt:    database.Now(),
+		AgentID:      agent.ID,
+		Slug:         "no-healthcheck",
+		SharingLevel: database.AppSharingLevelOwner,
+		Health:       database.WorkspaceAppHealthInitializing,
+	})
+	require.NoError(t, err)
+
+	apps, err := db.GetWorkspaceAppsByAgentID(ctx, agent.ID)
+	require.NoError(t, err)
+	health := make(map[uuid.UUID]database.WorkspaceAppHealth)
+	for _, app := range apps {
+		health[app.ID] = app.Health
+	}
+	require.Equal(t, database.WorkspaceAppHealthInitializing, health[fresh.ID])
+	require.Equal(t, database.WorkspaceAppHealthUnhealthy, health[stale.ID])
+	require.Equal(t, database.WorkspaceAppHealthHealthy, health[reported.ID])
+	require.Equal(t, database.WorkspaceAppHealthDisabled, health[noHealthcheck.ID])
+}
+
+func TestLatestWorkspaceBuildsByWorkspaceIDsEmpty(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{})
+
+	builds, err := db.GetLatestWorkspaceBuildsByWorkspaceIDs(ctx, []uuid.UUID{})
+	require.NoError(t, err)
+	require.Empty(t, builds)
+
+	builds, err = db.GetLatestWorkspaceBuildsByWorkspaceIDs(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, builds)
+}
+
+func TestWorkspaceResourcesByJobIDOrdering(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+	for _, resource := range []database.WorkspaceResource{
+		{Type: "google_compute_instance", Name: "dev"},
+		{Type: "docker_volume", Name: "home"},
+		{Type: "docker_container", Name: "workspace"},
+		{Type: "docker_container", Name: "sidecar"},
+	} {
+		resource.JobID = job.ID
+		_ = dbgen.WorkspaceResource(t, db, resource)
+	}
+
+	resources, err := db.GetWorkspaceResourcesByJobID(ctx, job.ID)
+	require.NoError(t, err)
+	got := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		got = append(got, resource.Type+"."+resource.Name)
+	}
+	require.Equal(t, []string{
+		"docker_container.sidecar",
+		"docker_container.workspace",
+		"docker_volume.home",
+		"google_compute_instance.dev",
+	}, got)
+}
+
+func TestWorkspaceAgentStatsReadyOnly(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	ready := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	err := db.UpdateWorkspaceAgentLifecycleStateByID(ctx, database.UpdateWorkspaceAgentLifecycleStateByIDParams{
+		ID:             ready.ID,
+		LifecycleState: database.WorkspaceAgentLifecycleStateReady,
+	})
+	require.NoError(t, err)
+	starting := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	err = db.UpdateWorkspaceAgentLifecycleStateByID(ctx, database.UpdateWorkspaceAgentLifecycleStateByIDParams{
+		ID:             starting.ID,
+		LifecycleState: database.WorkspaceAgentLifecycleStateStarting,
+	})
+	require.NoError(t, err)
+
+	createdAfter := database.Now().Add(-time.Hour)
+	for _, agentID := range []uuid.UUID{ready.ID, starting.ID} {
+		_ = dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+			AgentID:                   agentID,
+			RxBytes:                   10,
+			TxBytes:                   20,
+			SessionCountSSH:           1,
+			ConnectionMedianLatencyMS: 5,
+		})
+	}
+
+	stats, err := db.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{
+		CreatedAt: createdAfter,
+	})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+
+	stats, err = db.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{
+		CreatedAt: createdAfter,
+		ReadyOnly: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, ready.ID, stats[0].AgentID)
+	require.EqualValues(t, 10, stats[0].WorkspaceRxBytes)
+	require.EqualValues(t, 1, stats[0].SessionCountSSH)
+}
+
+func TestWorkspaceAgentStatsExcludeZeroConnections(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	agentID := uuid.New()
+	now := database.Now()
+	_ = dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+		CreatedAt:                 now.Add(-time.Minute),
+		AgentID:                   agentID,
+		ConnectionCount:           1,
+		RxBytes:                   10,
+		SessionCountSSH:           1,
+		ConnectionMedianLatencyMS: 5,
+	})
+	// The newest sample has no connections but reports stale sessions.
+	_ = dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+		CreatedAt:                 now,
+		AgentID:                   agentID,
+		ConnectionCount:           0,
+		RxBytes:                   10,
+		SessionCountSSH:           3,
+		ConnectionMedianLatencyMS: 5,
+	})
+
+	stats, err := db.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{
+		CreatedAt: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.EqualValues(t, 20, stats[0].WorkspaceRxBytes)
+	require.EqualValues(t, 3, stats[0].SessionCountSSH)
+
+	stats, err = db.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{
+		CreatedAt:              now.Add(-time.Hour),
+		ExcludeZeroConnections: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.EqualValues(t, 10, stats[0].WorkspaceRxBytes)
+	require.EqualValues(t, 1, stats[0].SessionCountSSH)
+}
+
+func TestTemplateParameterInsightsTemplateFilter(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	createTemplate := func(paramName string) database.Template {
+		tpl := dbgen.Template(t, db, database.Template{OrganizationID: org.ID})
+		tv := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			TemplateID:     uuid.NullUUID{UUID: tpl.ID, Valid: true},
+		})
+		_, err := db.InsertTemplateVersionParameter(ctx, database.InsertTemplateVersionParameterParams{
+			TemplateVersionID: tv.ID,
+			Name:              paramName,
+			Type:              "string",
+			Options:           json.RawMessage("[]"),
+		})
+		require.NoError(t, err)
+		ws := dbgen.Workspace(t, db, database.Workspace{
+			OrganizationID: org.ID,
+			OwnerID:        user.ID,
+			TemplateID:     tpl.ID,
+		})
+		build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID:       ws.ID,
+			TemplateVersionID: tv.ID,
+		})
+		err = db.InsertWorkspaceBuildParameters(ctx, database.InsertWorkspaceBuildParametersParams{
+			WorkspaceBuildID: build.ID,
+			Name:             []string{paramName},
+			Value:            []string{"value"},
+		})
+		require.NoError(t, err)
+		return tpl
+	}
+	first := createTemplate("first")
+	_ = createTemplate("second")
+
+	rows, err := db.GetTemplateParameterInsights(ctx, database.GetTemplateParameterInsightsParams{
+		StartTime:   database.Now().Add(-time.Hour),
+		EndTime:     database.Now().Add(time.Hour),
+		TemplateIDs: []uuid.UUID{first.ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "first", rows[0].Name)
+	require.Equal(t, []uuid.UUID{first.ID}, rows[0].TemplateIDs)
+	require.EqualValues(t, 1, rows[0].Count)
+}
+
+func TestInsertWorkspaceAgentStatsConnectionsByProtoMismatch(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	require.NotPanics(t, func() {
+		err := db.InsertWorkspaceAgentStats(ctx, database.InsertWorkspaceAgentStatsParams{
+			ID:                          []uuid.UUID{uuid.New(), uuid.New()},
+			CreatedAt:                   []time.Time{database.Now(), database.Now()},
+			UserID:                      []uuid.UUID{uuid.New(), uuid.New()},
+			WorkspaceID:                 []uuid.UUID{uuid.New(), uuid.New()},
+			TemplateID:                  []uuid.UUID{uuid.New(), uuid.New()},
+			AgentID:                     []uuid.UUID{uuid.New(), uuid.New()},
+			ConnectionsByProto:          json.RawMessage(`[{}]`),
+			ConnectionCount:             []int64{0, 0},
+			RxPackets:                   []int64{0, 0},
+			RxBytes:                     []int64{0, 0},
+			TxPackets:                   []int64{0, 0},
+			TxBytes:                     []int64{0, 0},
+			SessionCountVSCode:          []int64{0, 0},
+			SessionCountJetBrains:       []int64{0, 0},
+			SessionCountReconnectingPTY: []int64{0, 0},
+			SessionCountSSH:             []int64{0, 0},
+			ConnectionMedianLatencyMS:   []float64{0, 0},
+		})
+		require.ErrorContains(t, err, "connections_by_proto has 1 entries, expected 2")
+	})
+
+	stats, err := db.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{})
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
+
+func TestUserLatencyInsightsPercentiles(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	user := dbgen.User(t, db, database.User{})
+	templateID := uuid.New()
+	now := database.Now()
+	for i := 1; i <= 100; i++ {
+		_ = dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+			CreatedAt:                 now,
+			UserID:                    user.ID,
+			TemplateID:                templateID,
+			ConnectionCount:           1,
+			ConnectionMedianLatencyMS: float64(i),
+		})
+	}
+
+	rows, err := db.GetUserLatencyInsights(ctx, database.GetUserLatencyInsightsParams{
+		Percentiles: []float64{0.99, 0.5},
+		StartTime:   now.Add(-time.Hour),
+		EndTime:     now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, user.ID, rows[0].UserID)
+	require.Len(t, rows[0].WorkspaceConnectionLatencyPercentiles, 2)
+	// PERCENTILE_CONT interpolates between the closest ranks.
+	require.InDelta(t, 99.01, rows[0].WorkspaceConnectionLatencyPercentiles[0], 0.0001)
+	require.InDelta(t, 50.5, rows[0].WorkspaceConnectionLatencyPercentiles[1], 0.0001)
+
+	_, err = db.GetUserLatencyInsights(ctx, database.GetUserLatencyInsightsParams{
+		Percentiles: []float64{99},
+		StartTime:   now.Add(-time.Hour),
+		EndTime:     now.Add(time.Hour),
+	})
+	require.Error(t, err)
+}
+
+func TestGetDeletedTemplates(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	first := dbgen.Template(t, db, database.Template{})
+	second := dbgen.Template(t, db, database.Template{})
+	live := dbgen.Template(t, db, database.Template{})
+
+	templates, err := db.GetDeletedTemplates(ctx)
+	require.NoError(t, err)
+	require.Empty(t, templates)
+
+	now := database.Now()
+	for i, template := range []database.Template{first, second} {
+		err := db.UpdateTemplateDeletedByID(ctx, database.UpdateTemplateDeletedByIDParams{
+			ID:        template.ID,
+			Deleted:   true,
+			UpdatedAt: now.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	templates, err = db.GetDeletedTemplates(ctx)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	// The most recently deleted template comes first.
+	require.Equal(t, second.ID, templates[0].ID)
+	require.Equal(t, first.ID, templates[1].ID)
+	for _, template := range templates {
+		require.True(t, template.Deleted)
+		require.NotEqual(t, live.ID, template.ID)
+	}
+}
+
+func TestRestoreTemplate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		ctx := context.Background()
+
+		template := dbgen.Template(t, db, database.Template{})
+		err := db.UpdateTemplateDeletedByID(ctx, database.UpdateTemplateDeletedByIDParams{
+			ID:        template.ID,
+			Deleted:   true,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+
+		err = db.UpdateTemplateDeletedByID(ctx, database.UpdateTemplateDeletedByIDParams{
+			ID:        template.ID,
+			Deleted:   false,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+
+		template, err = db.GetTemplateByID(ctx, template.ID)
+		require.NoError(t, err)
+		require.False(t, template.Deleted)
+	})
+
+	t.Run("NameCollision", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		ctx := context.Background()
+
+		org := dbgen.Organization(t, db, database.Organization{})
+		deleted := dbgen.Template(t, db, database.Template{
+			OrganizationID: org.ID,
+			Name:           "template",
+		})
+		err := db.UpdateTemplateDeletedByID(ctx, database.UpdateTemplateDeletedByIDParams{
+			ID:        deleted.ID,
+			Deleted:   true,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+		_ = dbgen.Template(t, db, database.Template{
+			OrganizationID: org.ID,
+			Name:           "Template",
+		})
+
+		err = db.UpdateTemplateDeletedByID(ctx, database.UpdateTemplateDeletedByIDParams{
+			ID:        deleted.ID,
+			Deleted:   false,
+			UpdatedAt: database.Now(),
+		})
+		require.True(t, database.IsUniqueViolation(err))
+
+		deleted, err = db.GetTemplateByID(ctx, deleted.ID)
+		require.NoError(t, err)
+		require.True(t, deleted.Deleted)
+	})
+}
+
+func TestWorkspaceByOwnerIDAndNameIncludeDeleted(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	user := dbgen.User(t, db, database.User{})
+	now := database.Now()
+	deleted := dbgen.Workspace(t, db, database.Workspace{
+		OwnerID:   user.ID,
+		Name:      "dev",
+		CreatedAt: now,
+	})
+	err := db.UpdateWorkspaceDeletedByID(ctx, database.UpdateWorkspaceDeletedByIDParams{
+		ID:      deleted.ID,
+		Deleted: true,
+	})
+	require.NoError(t, err)
+	live := dbgen.Workspace(t, db, database.Workspace{
+		OwnerID:   user.ID,
+		Name:      "dev",
+		CreatedAt: now.Add(-time.Hour),
+	})
+
+	workspace, err := db.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
+		OwnerID: user.ID,
+		Name:    "dev",
+	})
+	require.NoError(t, err)
+	require.Equal(t, live.ID, workspace.ID)
+
+	workspace, err = db.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
+		OwnerID:        user.ID,
+		IncludeDeleted: true,
+		Name:           "dev",
+	})
+	require.NoError(t, err)
+	require.Equal(t, deleted.ID, workspace.ID)
+}
+
+func TestGetFileTemplatesDeduplicates(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	file := dbgen.File(t, db, database.File{})
+	template := dbgen.Template(t, db, database.Template{OrganizationID: org.ID})
+	for i := 0; i < 2; i++ {
+		job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			FileID:         file.ID,
+			Type:           database.ProvisionerJobTypeTemplateVersionImport,
+		})
+		_ = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			TemplateID:     uuid.NullUUID{UUID: template.ID, Valid: true},
+			JobID:          job.ID,
+		})
+	}
+
+	rows, err := db.GetFileTemplates(ctx, file.ID)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, template.ID, rows[0].TemplateID)
+	require.Equal(t, file.ID, rows[0].FileID)
+}
+
+func TestGetOrganizationsEmpty(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	orgs, err := db.GetOrganizations(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, orgs)
+	require.Empty(t, orgs)
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	orgs, err = db.GetOrganizations(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []database.Organization{org}, orgs)
+}
+
+func TestGroupsByOrganizationIDIncludeEveryone(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	everyone, err := db.InsertAllUsersGroup(ctx, org.ID)
+	require.NoError(t, err)
+	group := dbgen.Group(t, db, database.Group{OrganizationID: org.ID})
+
+	groups, err := db.GetGroupsByOrganizationID(ctx, database.GetGroupsByOrganizationIDParams{
+		OrganizationID: org.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []database.Group{group}, groups)
+
+	groups, err = db.GetGroupsByOrganizationID(ctx, database.GetGroupsByOrganizationIDParams{
+		OrganizationID:  org.ID,
+		IncludeEveryone: true,
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []database.Group{everyone, group}, groups)
+}
+
+func TestWorkspaceAgentByAuthTokenDeletedWorkspace(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	workspace := dbgen.Workspace(t, db, database.Workspace{})
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+	_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID: workspace.ID,
+		JobID:       job.ID,
+	})
+	resource := dbgen.WorkspaceResource(t, db, database.WorkspaceResource{JobID: job.ID})
+	agent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{ResourceID: resource.ID})
+
+	got, err := db.GetWorkspaceAgentByAuthToken(ctx, agent.AuthToken)
+	require.NoError(t, err)
+	require.Equal(t, agent.ID, got.ID)
+
+	err = db.UpdateWorkspaceDeletedByID(ctx, database.UpdateWorkspaceDeletedByIDParams{
+		ID:      workspace.ID,
+		Deleted: true,
+	})
+	require.NoError(t, err)
+
+	_, err = db.GetWorkspaceAgentByAuthToken(ctx, agent.AuthToken)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestProvisionerDaemonLastSeen(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	const threshold = time.Minute
+	registered := database.Now()
+	daemon, err := db.InsertProvisionerDaemon(ctx, database.InsertProvisionerDaemonParams{
+		ID:        uuid.New(),
+		CreatedAt: registered,
+		Name:      "daemon",
+	})
+	require.NoError(t, err)
+	require.False(t, daemon.IsStale(registered, threshold))
+
+	// Advance past the threshold without the daemon polling.
+	now := registered.Add(2 * threshold)
+	daemons, err := db.GetProvisionerDaemons(ctx)
+	require.NoError(t, err)
+	require.Len(t, daemons, 1)
+	require.True(t, daemons[0].IsStale(now, threshold))
+
+	err = db.UpdateProvisionerDaemonLastSeenAt(ctx, database.UpdateProvisionerDaemonLastSeenAtParams{
+		ID:         daemon.ID,
+		LastSeenAt: sql.NullTime{Time: now, Valid: true},
+	})
+	require.NoError(t, err)
+	// An older timestamp must not move last seen backwards.
+	err = db.UpdateProvisionerDaemonLastSeenAt(ctx, database.UpdateProvisionerDaemonLastSeenAtParams{
+		ID:         daemon.ID,
+		LastSeenAt: sql.NullTime{Time: registered, Valid: true},
+	})
+	require.NoError(t, err)
+
+	daemons, err = db.GetProvisionerDaemons(ctx)
+	require.NoError(t, err)
+	require.Len(t, daemons, 1)
+	require.False(t, daemons[0].IsStale(now, threshold))
+	require.True(t, daemons[0].LastSeenAt.Time.Equal(now))
+
+	// Like the SQL query, updating an unknown daemon is a no-op.
+	err = db.UpdateProvisionerDaemonLastSeenAt(ctx, database.UpdateProvisionerDaemonLastSeenAtParams{
+		ID:         uuid.New(),
+		LastSeenAt: sql.NullTime{Time: now, Valid: true},
+	})
+	require.NoError(t, err)
+}
+
+func TestWorkspaceAgentLogsAfterWithGap(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	agent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	insertLogs := func(outputs ...string) []database.WorkspaceAgentLog {
+		params := database.InsertWorkspaceAgentLogsParams{
+			AgentID: agent.ID,
+		}
+		for _, output := range outputs {
+			params.CreatedAt = append(params.CreatedAt, database.Now())
+			params.Output = append(params.Output, output)
+			params.Level = append(params.Level, database.LogLevelInfo)
+			params.Source = append(params.Source, database.WorkspaceAgentLogSourceStartupScript)
+			params.OutputLength += int32(len(output))
+		}
+		logs, err := db.InsertWorkspaceAgentLogs(ctx, params)
+		require.NoError(t, err)
+		return logs
+	}
+
+	first := insertLogs("one", "two")
+	result, err := db.GetWorkspaceAgentLogsAfterWithGap(ctx, database.GetWorkspaceAgentLogsAfterParams{
+		AgentID:      agent.ID,
+		CreatedAfter: first[0].ID,
+	})
+	require.NoError(t, err)
+	require.False(t, result.Gap)
+	require.Equal(t, first[1:], result.Logs)
+
+	// Truncate the logs of the agent, as happens when it hasn't connected
+	// in a while, then have it log again.
+	err = db.UpdateWorkspaceAgentConnectionByID(ctx, database.UpdateWorkspaceAgentConnectionByIDParams{
+		ID:              agent.ID,
+		LastConnectedAt: sql.NullTime{Time: database.Now().Add(-8 * 24 * time.Hour), Valid: true},
+		UpdatedAt:       database.Now(),
+	})
+	require.NoError(t, err)
+	err = db.DeleteOldWorkspaceAgentLogs(ctx)
+	require.NoError(t, err)
+	second := insertLogs("three")
+	// IDs come from a sequence, so they are never reused.
+	require.Greater(t, second[0].ID, first[1].ID)
+
+	result, err = db.GetWorkspaceAgentLogsAfterWithGap(ctx, database.GetWorkspaceAgentLogsAfterParams{
+		AgentID:      agent.ID,
+		CreatedAfter: first[1].ID,
+	})
+	require.NoError(t, err)
+	require.True(t, result.Gap)
+	require.Equal(t, second, result.Logs)
+
+	// Following from the start never reports a gap.
+	result, err = db.GetWorkspaceAgentLogsAfterWithGap(ctx, database.GetWorkspaceAgentLogsAfterParams{
+		AgentID: agent.ID,
+	})
+	require.NoError(t, err)
+	require.False(t, result.Gap)
+}
+
+func TestInsertWorkspaceAgentLogsMaxLineLength(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	db.(*dbfake.FakeQuerier).SetMaxWorkspaceAgentLogLineLength(16)
+	ctx := context.Background()
+
+	agent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	insert := func(output string) ([]database.WorkspaceAgentLog, error) {
+		return db.InsertWorkspaceAgentLogs(ctx, database.InsertWorkspaceAgentLogsParams{
+			AgentID:      agent.ID,
+			CreatedAt:    []time.Time{database.Now()},
+			Output:       []string{output},
+			Level:        []database.LogLevel{database.LogLevelInfo},
+			Source:       []database.WorkspaceAgentLogSource{database.WorkspaceAgentLogSourceStartupScript},
+			OutputLength: int32(len(output)),
+		})
+	}
+
+	logs, err := insert("hello world")
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	_, err = insert(strings.Repeat("a", 17))
+	require.ErrorContains(t, err, "exceeding the maximum of 16 bytes")
+
+	// The rejected line must not have been stored.
+	logs, err = db.GetWorkspaceAgentLogsAfter(ctx, database.GetWorkspaceAgentLogsAfterParams{
+		AgentID: agent.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, "hello world", logs[0].Output)
+}
+
+func TestInsertProvisionerJobInputValidation(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	db.(*dbfake.FakeQuerier).SetValidateProvisionerJobInput(true)
+	ctx := context.Background()
+
+	insert := func(typ database.ProvisionerJobType, input string) error {
+		_, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:            uuid.New(),
+			CreatedAt:     database.Now(),
+			UpdatedAt:     database.Now(),
+			Provisioner:   database.ProvisionerTypeEcho,
+			StorageMethod: database.ProvisionerStorageMethodFile,
+			Type:          typ,
+			Input:         []byte(input),
+			Tags:          database.StringMap{},
+		})
+		return err
+	}
+
+	err := insert(database.ProvisionerJobTypeTemplateVersionImport, `{"template_version_id":"`+uuid.NewString()+`"}`)
+	require.NoError(t, err)
+
+	err = insert(database.ProvisionerJobTypeTemplateVersionImport, `{}`)
+	require.ErrorContains(t, err, `missing required key "template_version_id"`)
+
+	err = insert(database.ProvisionerJobTypeWorkspaceBuild, `{"dry_run":false}`)
+	require.ErrorContains(t, err, `missing required key "workspace_build_id"`)
+
+	err = insert(database.ProvisionerJobTypeWorkspaceBuild, `not json`)
+	require.ErrorContains(t, err, "invalid provisioner job input")
+}
+
+func TestTemplateAndDeploymentDAUs(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	today := database.Now().Truncate(24 * time.Hour)
+	yesterday := today.AddDate(0, 0, -1)
+
+	template := dbgen.Template(t, db, database.Template{})
+	alice := dbgen.User(t, db, database.User{})
+	bob := dbgen.User(t, db, database.User{})
+	carol := dbgen.User(t, db, database.User{})
+	stat := func(createdAt time.Time, userID, templateID uuid.UUID) {
+		dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+			CreatedAt:       createdAt,
+			UserID:          userID,
+			TemplateID:      templateID,
+			ConnectionCount: 1,
+		})
+	}
+
+	// Yesterday only other templates were used.
+	stat(yesterday, alice.ID, uuid.New())
+	// Today two users used the template, and one user used another.
+	stat(today, alice.ID, template.ID)
+	stat(today.Add(time.Hour), alice.ID, template.ID)
+	stat(today, bob.ID, template.ID)
+	stat(today, carol.ID, uuid.New())
+
+	rows, err := db.GetTemplateAndDeploymentDAUs(ctx, database.GetTemplateAndDeploymentDAUsParams{
+		TemplateID: template.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []database.GetTemplateAndDeploymentDAUsRow{{
+		Date:                  yesterday,
+		TemplateActiveUsers:   0,
+		DeploymentActiveUsers: 1,
+	}, {
+		Date:                  today,
+		TemplateActiveUsers:   2,
+		DeploymentActiveUsers: 3,
+	}}, rows)
+}
+
+func TestGetWorkspacesSortByTemplate(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	alice := dbgen.User(t, db, database.User{Username: "alice"})
+	bob := dbgen.User(t, db, database.User{Username: "bob"})
+	zeta := dbgen.Template(t, db, database.Template{Name: "zeta", OrganizationID: org.ID})
+	alpha := dbgen.Template(t, db, database.Template{Name: "Alpha", OrganizationID: org.ID})
+
+	for _, w := range []database.Workspace{
+		{Name: "c", OwnerID: alice.ID, TemplateID: zeta.ID},
+		{Name: "b", OwnerID: bob.ID, TemplateID: alpha.ID},
+		{Name: "a", OwnerID: bob.ID, TemplateID: zeta.ID},
+		{Name: "d", OwnerID: alice.ID, TemplateID: alpha.ID},
+	} {
+		w.OrganizationID = org.ID
+		dbgen.Workspace(t, db, w)
+	}
+
+	rows, err := db.GetWorkspaces(ctx, database.GetWorkspacesParams{
+		SortBy: string(database.WorkspaceSortByTemplate),
+	})
+	require.NoError(t, err)
+	got := make([]string, 0, len(rows))
+	for _, row := range rows {
+		got = append(got, row.TemplateName+"/"+row.Name)
+	}
+	require.Equal(t, []string{"Alpha/b", "Alpha/d", "zeta/a", "zeta/c"}, got)
+}
+
+func TestGetWorkspaceCountByOwner(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	alice := dbgen.User(t, db, database.User{})
+	bob := dbgen.User(t, db, database.User{})
+	carol := dbgen.User(t, db, database.User{})
+	for i := 0; i < 3; i++ {
+		_ = dbgen.Workspace(t, db, database.Workspace{OwnerID: alice.ID})
+	}
+	_ = dbgen.Workspace(t, db, database.Workspace{OwnerID: bob.ID})
+	// Deleted workspaces are not counted.
+	deleted := dbgen.Workspace(t, db, database.Workspace{OwnerID: bob.ID})
+	err := db.UpdateWorkspaceDeletedByID(ctx, database.UpdateWorkspaceDeletedByIDParams{
+		ID:      deleted.ID,
+		Deleted: true,
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		ownerID uuid.UUID
+		count   int64
+	}{
+		{ownerID: alice.ID, count: 3},
+		{ownerID: bob.ID, count: 1},
+		{ownerID: carol.ID, count: 0},
+	} {
+		count, err := db.GetWorkspaceCountByOwner(ctx, tc.ownerID)
+		require.NoError(t, err)
+		require.Equal(t, tc.count, count)
+	}
+}
+
+func TestGetWorkspaceAgentsCreatedAfterWithWorkspace(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := database.Now()
+
+	template := dbgen.Template(t, db, database.Template{})
+	workspace := dbgen.Workspace(t, db, database.Workspace{TemplateID: template.ID})
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+	_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{WorkspaceID: workspace.ID, JobID: job.ID})
+	resource := dbgen.WorkspaceResource(t, db, database.WorkspaceResource{JobID: job.ID})
+	agent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{ResourceID: resource.ID, CreatedAt: now})
+	// Agents without a workspace, or created too early, are omitted.
+	_ = dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{CreatedAt: now})
+	_ = dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{ResourceID: resource.ID, CreatedAt: now.Add(-2 * time.Hour)})
+
+	rows, err := db.GetWorkspaceAgentsCreatedAfterWithWorkspace(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow{{
+		WorkspaceAgent: agent,
+		WorkspaceID:    workspace.ID,
+		TemplateID:     template.ID,
+	}}, rows)
+}
+
+func TestInsertWorkspaceAgentMetadataBatch(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	agent := dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{})
+	err := db.InsertWorkspaceAgentMetadataBatch(ctx, database.InsertWorkspaceAgentMetadataBatchParams{
+		WorkspaceAgentID: agent.ID,
+		DisplayName:      []string{"CPU", "Memory", "Disk"},
+		Key:              []string{"cpu", "mem", "disk"},
+		Script:           []string{"echo cpu", "echo mem", "echo disk"},
+		Timeout:          []int64{1, 2, 3},
+		Interval:         []int64{10, 20, 30},
+	})
+	require.NoError(t, err)
+
+	metadata, err := db.GetWorkspaceAgentMetadata(ctx, agent.ID)
+	require.NoError(t, err)
+	require.Len(t, metadata, 3)
+	for i, key := range []string{"cpu", "mem", "disk"} {
+		require.Equal(t, key, metadata[i].Key)
+		require.Equal(t, agent.ID, metadata[i].WorkspaceAgentID)
+		require.Equal(t, int64(i+1), metadata[i].Timeout)
+		require.Equal(t, int64((i+1)*10), metadata[i].Interval)
+	}
+
+	err = db.InsertWorkspaceAgentMetadataBatch(ctx, database.InsertWorkspaceAgentMetadataBatchParams{
+		WorkspaceAgentID: agent.ID,
+		DisplayName:      []string{"Load"},
+		Key:              []string{"load"},
+	})
+	require.Error(t, err)
+}
+
+func TestGetTemplateVersionVariablesRedacted(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	version := dbgen.TemplateVersion(t, db, database.TemplateVersion{})
+	public := dbgen.TemplateVersionVariable(t, db, database.TemplateVersionVariable{
+		TemplateVersionID: version.ID,
+		Name:              "region",
+		Value:             "us-east-1",
+		DefaultValue:      "eu-west-1",
+	})
+	_ = dbgen.TemplateVersionVariable(t, db, database.TemplateVersionVariable{
+		TemplateVersionID: version.ID,
+		Name:              "token",
+		Value:             "secret",
+		DefaultValue:      "default-secret",
+		Sensitive:         true,
+	})
+
+	variables, err := db.GetTemplateVersionVariablesRedacted(ctx, version.ID)
+	require.NoError(t, err)
+	require.Len(t, variables, 2)
+	require.Equal(t, database.GetTemplateVersionVariablesRedactedRow(public), variables[0])
+	require.Equal(t, "token", variables[1].Name)
+	require.True(t, variables[1].Sensitive)
+	require.Empty(t, variables[1].Value)
+	require.Empty(t, variables[1].DefaultValue)
+}
+
+func TestCountRunningProvisionerJobs(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := sql.NullTime{Time: database.Now(), Valid: true}
+
+	// Pending.
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+	// Running.
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{StartedAt: now})
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{StartedAt: now})
+	// Completed.
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{StartedAt: now, CompletedAt: now})
+	// Canceling.
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{StartedAt: now, CanceledAt: now})
+
+	count, err := db.CountRunningProvisionerJobs(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+}
+
+func TestGetProvisionerJobsCreatedAfterFilters(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	now := sql.NullTime{Time: database.Now(), Valid: true}
+	before := database.Now().Add(-time.Minute)
+
+	imported := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		Type:        database.ProvisionerJobTypeTemplateVersionImport,
+		StartedAt:   now,
+		CompletedAt: now,
+	})
+	pendingImport := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		Type: database.ProvisionerJobTypeTemplateVersionImport,
+	})
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		Type: database.ProvisionerJobTypeWorkspaceBuild,
+	})
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		Type: database.ProvisionerJobTypeTemplateVersionDryRun,
+	})
+
+	jobIDs := func(jobs []database.ProvisionerJob) []uuid.UUID {
+		ids := make([]uuid.UUID, 0, len(jobs))
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+		}
+		return ids
+	}
+
+	jobs, err := db.GetProvisionerJobsCreatedAfter(ctx, database.GetProvisionerJobsCreatedAfterParams{
+		CreatedAt: before,
+	})
+	require.NoError(t, err)
+	require.Len(t, jobs, 4)
+
+	jobs, err = db.GetProvisionerJobsCreatedAfter(ctx, database.GetProvisionerJobsCreatedAfterParams{
+		CreatedAt: before,
+		Types:     []database.ProvisionerJobType{database.ProvisionerJobTypeTemplateVersionImport},
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []uuid.UUID{imported.ID, pendingImport.ID}, jobIDs(jobs))
+
+	jobs, err = db.GetProvisionerJobsCreatedAfter(ctx, database.GetProvisionerJobsCreatedAfterParams{
+		CreatedAt: before,
+		Types:     []database.ProvisionerJobType{database.ProvisionerJobTypeTemplateVersionImport},
+		Status:    string(codersdk.ProvisionerJobSucceeded),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{imported.ID}, jobIDs(jobs))
+}
+
+func TestGetWorkspacesEligibleForTransitionInProgress(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	template := dbgen.Template(t, db, database.Template{})
+	newWorkspace := func(completed bool) database.Workspace {
+		workspace := dbgen.Workspace(t, db, database.Workspace{
+			TemplateID: template.ID,
+			AutostartSchedule: sql.NullString{
+				String: "CRON_TZ=UTC 0 * * * *",
+				Valid:  true,
+			},
+		})
+		job := database.ProvisionerJob{}
+		if completed {
+			job.CompletedAt = sql.NullTime{Time: database.Now(), Valid: true}
+		}
+		job = dbgen.ProvisionerJob(t, db, job)
+		dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID: workspace.ID,
+			JobID:       job.ID,
+			Transition:  database.WorkspaceTransitionStop,
+		})
+		return workspace
+	}
+	stopped := newWorkspace(true)
+	_ = newWorkspace(false)
+
+	workspaces, err := db.GetWorkspacesEligibleForTransition(ctx, database.Now())
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	require.Equal(t, stopped.ID, workspaces[0].ID)
+}
+
+func TestDeploymentWorkspaceAgentStatsHasData(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	stats, err := db.GetDeploymentWorkspaceAgentStats(ctx, database.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.False(t, stats.HasData)
+	require.Equal(t, float64(-1), stats.WorkspaceConnectionLatency50)
+
+	dbgen.WorkspaceAgentStat(t, db, database.WorkspaceAgentStat{
+		ConnectionMedianLatencyMS: 1,
+	})
+	stats, err = db.GetDeploymentWorkspaceAgentStats(ctx, database.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, stats.HasData)
+	require.Equal(t, float64(1), stats.WorkspaceConnectionLatency50)
+}
+
+func TestDeleteAllGroupMembersByUserID(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	user := dbgen.User(t, db, database.User{})
+	other := dbgen.User(t, db, database.User{})
+	var groups []database.Group
+	for i := 0; i < 2; i++ {
+		org := dbgen.Organization(t, db, database.Organization{})
+		group := dbgen.Group(t, db, database.Group{OrganizationID: org.ID})
+		dbgen.GroupMember(t, db, database.GroupMember{GroupID: group.ID, UserID: user.ID})
+		dbgen.GroupMember(t, db, database.GroupMember{GroupID: group.ID, UserID: other.ID})
+		groups = append(groups, group)
+	}
+
+	err := db.DeleteAllGroupMembersByUserID(ctx, user.ID)
+	require.NoError(t, err)
+
+	for _, group := range groups {
+		members, err := db.GetGroupMembers(ctx, group.ID)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		require.Equal(t, other.ID, members[0].ID)
+	}
+}
+
+func TestInsertGroupMemberNonexistentGroup(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	user := dbgen.User(t, db, database.User{})
+
+	err := db.InsertGroupMember(context.Background(), database.InsertGroupMemberParams{
+		UserID:  user.ID,
+		GroupID: uuid.New(),
+	})
+	require.Error(t, err)
+	var pqErr *pq.Error
+	require.ErrorAs(t, err, &pqErr)
+	require.Equal(t, "foreign_key_violation", pqErr.Code.Name())
+}
+
+func TestGetAuthorizationUserRolesByIDs(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	group := dbgen.Group(t, db, database.Group{OrganizationID: org.ID})
+	admin := dbgen.User(t, db, database.User{RBACRoles: []string{"owner"}})
+	member := dbgen.User(t, db, database.User{})
+	dbgen.OrganizationMember(t, db, database.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         member.ID,
+		Roles:          []string{"organization-admin:" + org.ID.String()},
+	})
+	grouped := dbgen.User(t, db, database.User{})
+	dbgen.GroupMember(t, db, database.GroupMember{GroupID: group.ID, UserID: grouped.ID})
+	// Users that are not requested must not be returned.
+	_ = dbgen.User(t, db, database.User{})
+
+	ids := []uuid.UUID{admin.ID, member.ID, grouped.ID}
+	rows, err := db.GetAuthorizationUserRolesByIDs(ctx, ids)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	for _, row := range rows {
+		single, err := db.GetAuthorizationUserRoles(ctx, row.ID)
+		require.NoError(t, err)
+		require.Equal(t, database.GetAuthorizationUserRolesRow(row), single)
+	}
+
+	byID := make(map[uuid.UUID]database.GetAuthorizationUserRolesByIDsRow)
+	for _, row := range rows {
+		byID[row.ID] = row
+	}
+	require.ElementsMatch(t, []string{"owner", "member"}, byID[admin.ID].Roles)
+	require.ElementsMatch(t, []string{
+		"member",
+		"organization-admin:" + org.ID.String(),
+		"organization-member:" + org.ID.String(),
+	}, byID[member.ID].Roles)
+	require.Equal(t, []string{group.ID.String()}, byID[grouped.ID].Groups)
+}
+
+func TestGetUsersOrganizationFilter(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	orgA := dbgen.Organization(t, db, database.Organization{})
+	orgB := dbgen.Organization(t, db, database.Organization{})
+	member := func(orgs ...database.Organization) database.User {
+		user := dbgen.User(t, db, database.User{})
+		for _, org := range orgs {
+			dbgen.OrganizationMember(t, db, database.OrganizationMember{
+				OrganizationID: org.ID,
+				UserID:         user.ID,
+			})
+		}
+		return user
+	}
+	onlyA := member(orgA)
+	both := member(orgA, orgB)
+	_ = member(orgB)
+	_ = member()
+
+	users, err := db.GetUsers(ctx, database.GetUsersParams{
+		OrganizationID: orgA.ID,
+	})
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+		require.EqualValues(t, 2, user.Count)
+	}
+	require.ElementsMatch(t, []uuid.UUID{onlyA.ID, both.ID}, ids)
+
+	users, err = db.GetUsers(ctx, database.GetUsersParams{})
+	require.NoError(t, err)
+	require.Len(t, users, 4)
+}
+
+func TestLatestWorkspaceBuildTieBreak(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	workspace := dbgen.Workspace(t, db, database.Workspace{})
+	now := database.Now()
+
+	// Two builds erroneously share a build number. The newer one wins.
+	_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID: workspace.ID,
+		BuildNumber: 2,
+		CreatedAt:   now.Add(-time.Minute),
+	})
+	newer := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID: workspace.ID,
+		BuildNumber: 2,
+		CreatedAt:   now,
+	})
+	_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID: workspace.ID,
+		BuildNumber: 1,
+		CreatedAt:   now.Add(time.Minute),
+	})
+	build, err := db.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Equal(t, newer.ID, build.ID)
+
+	// With identical creation times, the highest ID wins regardless of
+	// insertion order.
+	other := dbgen.Workspace(t, db, database.Workspace{})
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+	for _, id := range []uuid.UUID{high, low} {
+		_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			ID:          id,
+			WorkspaceID: other.ID,
+			BuildNumber: 1,
+			CreatedAt:   now,
+		})
+	}
+	build, err = db.GetLatestWorkspaceBuildByWorkspaceID(ctx, other.ID)
+	require.NoError(t, err)
+	require.Equal(t, high, build.ID)
+}
+
+func TestWorkspaceBuildsByWorkspaceIDInitiatorFilter(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+
+	alice := dbgen.User(t, db, database.User{Username: "alice"})
+	bob := dbgen.User(t, db, database.User{Username: "bob"})
+	workspace := dbgen.Workspace(t, db, database.Workspace{})
+	var aliceBuilds []uuid.UUID
+	for i, initiator := range []database.User{alice, bob, alice} {
+		build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID: workspace.ID,
+			BuildNumber: int32(i + 1),
+			InitiatorID: initiator.ID,
+		})
+		if initiator.ID == alice.ID {
+			aliceBuilds = append(aliceBuilds, build.ID)
+		}
+	}
+
+	builds, err := db.GetWorkspaceBuildsByWorkspaceID(ctx, database.GetWorkspaceBuildsByWorkspaceIDParams{
+		WorkspaceID:       workspace.ID,
+		InitiatorUsername: "Alice",
+	})
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, 0, len(builds))
+	for _, build := range builds {
+		require.Equal(t, "alice", build.InitiatorByUsername)
+		ids = append(ids, build.ID)
+	}
+	require.ElementsMatch(t, aliceBuilds, ids)
+
+	builds, err = db.GetWorkspaceBuildsByWorkspaceID(ctx, database.GetWorkspaceBuildsByWorkspaceIDParams{
+		WorkspaceID: workspace.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, builds, 3)
+}
+
+func TestGetWorkspaceBuildParameterByName(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{})
+	other := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{})
+	err := db.InsertWorkspaceBuildParameters(ctx, database.InsertWorkspaceBuildParametersParams{
+		WorkspaceBuildID: build.ID,
+		Name:             []string{"region", "size"},
+		Value:            []string{"us-east", "large"},
+	})
+	require.NoError(t, err)
+	err = db.InsertWorkspaceBuildParameters(ctx, database.InsertWorkspaceBuildParametersParams{
+		WorkspaceBuildID: other.ID,
+		Name:             []string{"region"},
+		Value:            []string{"eu-west"},
+	})
+	require.NoError(t, err)
+
+	param, err := db.GetWorkspaceBuildParameterByName(ctx, database.GetWorkspaceBuildParameterByNameParams{
+		WorkspaceBuildID: build.ID,
+		Name:             "region",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "us-east", param.Value)
+
+	_, err = db.GetWorkspaceBuildParameterByName(ctx, database.GetWorkspaceBuildParameterByNameParams{
+		WorkspaceBuildID: build.ID,
+		Name:             "missing",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetWorkspaceBuildParametersByBuildIDs(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	first := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{})
+	second := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{})
+	excluded := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{})
+	for _, arg := range []database.InsertWorkspaceBuildParametersParams{
+		{WorkspaceBuildID: first.ID, Name: []string{"region", "size"}, Value: []string{"us-east", "large"}},
+		{WorkspaceBuildID: second.ID, Name: []string{"region"}, Value: []string{"eu-west"}},
+		{WorkspaceBuildID: excluded.ID, Name: []string{"region"}, Value: []string{"ap-south"}},
+	} {
+		require.NoError(t, db.InsertWorkspaceBuildParameters(ctx, arg))
+	}
+
+	params, err := db.GetWorkspaceBuildParametersByBuildIDs(ctx, []uuid.UUID{first.ID, second.ID})
+	require.NoError(t, err)
+	require.Len(t, params, 3)
+
+	byBuild := make(map[uuid.UUID]map[string]string)
+	for _, param := range params {
+		if byBuild[param.WorkspaceBuildID] == nil {
+			byBuild[param.WorkspaceBuildID] = make(map[string]string)
+		}
+		byBuild[param.WorkspaceBuildID][param.Name] = param.Value
+	}
+	require.Equal(t, map[uuid.UUID]map[string]string{
+		first.ID:  {"region": "us-east", "size": "large"},
+		second.ID: {"region": "eu-west"},
+	}, byBuild)
+}
+
+func TestGetWorkspaceAgentByIDExcludeDeletedWorkspace(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	newAgent := func(deleted bool) database.WorkspaceAgent {
+		ws := dbgen.Workspace(t, db, database.Workspace{})
+		if deleted {
+			err := db.UpdateWorkspaceDeletedByID(ctx, database.UpdateWorkspaceDeletedByIDParams{
+				ID:      ws.ID,
+				Deleted: true,
+			})
+			require.NoError(t, err)
+		}
+		build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(t, db, database.WorkspaceResource{JobID: build.JobID})
+		return dbgen.WorkspaceAgent(t, db, database.WorkspaceAgent{ResourceID: res.ID})
+	}
+	live := newAgent(false)
+	deleted := newAgent(true)
+
+	agent, err := db.GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx, live.ID)
+	require.NoError(t, err)
+	require.Equal(t, live.ID, agent.ID)
+
+	_, err = db.GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx, deleted.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// The unfiltered query still returns agents of deleted workspaces.
+	agent, err = db.GetWorkspaceAgentByID(ctx, deleted.ID)
+	require.NoError(t, err)
+	require.Equal(t, deleted.ID, agent.ID)
+}
+
+func TestGetWorkspaceProxiesSearch(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	ctx := context.Background()
+	sydney, _ := dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{Name: "b-syd", DisplayName: "Sydney"})
+	_, _ = dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{Name: "c-fra", DisplayName: "Frankfurt"})
+	sydneyWest, _ := dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{Name: "a-syd-west", DisplayName: "Sydney West"})
+
+	proxies, err := db.GetWorkspaceProxies(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, proxies, 3)
+
+	proxies, err = db.GetWorkspaceProxies(ctx, "sydney")
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{sydneyWest.ID, sydney.ID}, []uuid.UUID{proxies[0].ID, proxies[1].ID})
+	require.Len(t, proxies, 2)
+}
+
+func BenchmarkGetWorkspacesEligibleForTransition(b *testing.B) {
+	for _, tc := range []struct {
+		workspaces int
+		templates  int
+	}{
+		{workspaces: 100, templates: 1},
+		{workspaces: 100, templates: 10},
+		{workspaces: 1000, templates: 10},
+	} {
+		tc := tc
+		b.Run(fmt.Sprintf("%dWorkspaces%dTemplates", tc.workspaces, tc.templates), func(b *testing.B) {
+			db := dbfake.New()
+			ctx := context.Background()
+
+			templates := make([]database.Template, 0, tc.templates)
+			for i := 0; i < tc.templates; i++ {
+				templates = append(templates, dbgen.Template(b, db, database.Template{}))
+			}
+			for i := 0; i < tc.workspaces; i++ {
+				workspace := dbgen.Workspace(b, db, database.Workspace{
+					TemplateID: templates[i%len(templates)].ID,
+				})
+				job := dbgen.ProvisionerJob(b, db, database.ProvisionerJob{
+					CompletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+				})
+				dbgen.WorkspaceBuild(b, db, database.WorkspaceBuild{
+					WorkspaceID: workspace.ID,
+					JobID:       job.ID,
+					Transition:  database.WorkspaceTransitionStart,
+				})
+			}
+
+			fake, ok := db.(*dbfake.FakeQuerier)
+			require.True(b, ok)
+			lookups := fake.TemplateLookups()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := db.GetWorkspacesEligibleForTransition(ctx, database.Now())
+				require.NoError(b, err)
+			}
+			b.StopTimer()
+			// Templates are loaded once per call rather than per workspace.
+			perOp := float64(fake.TemplateLookups()-lookups) / float64(b.N)
+			require.Equal(b, 1.0, perOp)
+			b.ReportMetric(perOp, "template-lookups/op")
+		})
+	}
+}
@@ -63,6 +63,9 @@ func AuditLog(t testing.TB, db database.Store, seed database.AuditLog) database.
 
 func Template(t testing.TB, db database.Store, seed database.Template) database.Template {
 	id := takeFirst(seed.ID, uuid.New())
+	if seed.ProvisionerTags == nil {
+		seed.ProvisionerTags = database.StringMap{}
+	}
 	err := db.InsertTemplate(genCtx, database.InsertTemplateParams{
 		ID:                           id,
 		CreatedAt:                    takeFirst(seed.CreatedAt, database.Now()),
@@ -78,6 +81,7 @@ func Template(t testing.TB, db database.Store, seed database.Template) database.
 		GroupACL:                     seed.GroupACL,
 		DisplayName:                  takeFirst(seed.DisplayName, namesgenerator.GetRandomName(1)),
 		AllowUserCancelWorkspaceJobs: seed.AllowUserCancelWorkspaceJobs,
+		ProvisionerTags:              seed.ProvisionerTags,
 	})
 	require.NoError(t, err, "insert template")
 
@@ -568,6 +572,76 @@ func WorkspaceAgentStat(t testing.TB, db database.Store, orig database.Workspace
 	return scheme
 }
 
+// SeededWorkspace is a linked set of rows created by SeedWorkspace.
+type SeededWorkspace struct {
+	Organization    database.Organization
+	User            database.User
+	Template        database.Template
+	TemplateVersion database.TemplateVersion
+	Workspace       database.Workspace
+	Build           database.WorkspaceBuild
+	BuildJob        database.ProvisionerJob
+}
+
+// SeedWorkspace creates an organization, a member user, a template with an
+// imported active version, and a workspace owned by the user with a single
+// completed build. Fields set in seed are maintained, but the IDs linking the
+// rows together are always filled in by SeedWorkspace.
+func SeedWorkspace(t testing.TB, db database.Store, seed SeededWorkspace) SeededWorkspace {
+	var out SeededWorkspace
+	out.Organization = Organization(t, db, seed.Organization)
+	out.User = User(t, db, seed.User)
+	OrganizationMember(t, db, database.OrganizationMember{
+		OrganizationID: out.Organization.ID,
+		UserID:         out.User.ID,
+	})
+
+	versionID := takeFirst(seed.TemplateVersion.ID, uuid.New())
+	seed.Template.OrganizationID = out.Organization.ID
+	seed.Template.ActiveVersionID = versionID
+	seed.Template.CreatedBy = out.User.ID
+	out.Template = Template(t, db, seed.Template)
+
+	importJob := ProvisionerJob(t, db, database.ProvisionerJob{
+		OrganizationID: out.Organization.ID,
+		InitiatorID:    out.User.ID,
+		Type:           database.ProvisionerJobTypeTemplateVersionImport,
+		CompletedAt:    sql.NullTime{Time: database.Now(), Valid: true},
+	})
+	seed.TemplateVersion.ID = versionID
+	seed.TemplateVersion.TemplateID = uuid.NullUUID{UUID: out.Template.ID, Valid: true}
+	seed.TemplateVersion.OrganizationID = out.Organization.ID
+	seed.TemplateVersion.JobID = importJob.ID
+	seed.TemplateVersion.CreatedBy = out.User.ID
+	out.TemplateVersion = TemplateVersion(t, db, seed.TemplateVersion)
+
+	seed.Workspace.OwnerID = out.User.ID
+	seed.Workspace.OrganizationID = out.Organization.ID
+	seed.Workspace.TemplateID = out.Template.ID
+	out.Workspace = Workspace(t, db, seed.Workspace)
+
+	buildID := takeFirst(seed.Build.ID, uuid.New())
+	seed.BuildJob.OrganizationID = out.Organization.ID
+	seed.BuildJob.InitiatorID = out.User.ID
+	seed.BuildJob.Type = database.ProvisionerJobTypeWorkspaceBuild
+	seed.BuildJob.Input = takeFirstSlice(seed.BuildJob.Input, must(json.Marshal(map[string]any{
+		"workspace_build_id": buildID,
+	})))
+	if !seed.BuildJob.CompletedAt.Valid {
+		seed.BuildJob.CompletedAt = sql.NullTime{Time: database.Now(), Valid: true}
+	}
+	out.BuildJob = ProvisionerJob(t, db, seed.BuildJob)
+
+	seed.Build.ID = buildID
+	seed.Build.WorkspaceID = out.Workspace.ID
+	seed.Build.TemplateVersionID = out.TemplateVersion.ID
+	seed.Build.InitiatorID = out.User.ID
+	seed.Build.JobID = out.BuildJob.ID
+	out.Build = WorkspaceBuild(t, db, seed.Build)
+
+	return out
+}
+
 func must[V any](v V, err error) V {
 	if err != nil {
 		panic(err)
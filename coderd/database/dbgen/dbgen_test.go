@@ -173,6 +173,43 @@ func TestGenerator(t *testing.T) {
 		exp := dbgen.GitSSHKey(t, db, database.GitSSHKey{})
 		require.Equal(t, exp, must(db.GetGitSSHKey(context.Background(), exp.UserID)))
 	})
+
+	t.Run("SeedWorkspace", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		ctx := context.Background()
+		seeded := dbgen.SeedWorkspace(t, db, dbgen.SeededWorkspace{
+			Workspace: database.Workspace{Name: "seeded"},
+			Build:     database.WorkspaceBuild{Transition: database.WorkspaceTransitionStop},
+		})
+
+		workspace := must(db.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
+			OwnerID: seeded.User.ID,
+			Name:    "seeded",
+		}))
+		require.Equal(t, seeded.Workspace.ID, workspace.ID)
+		require.Equal(t, seeded.Organization.ID, workspace.OrganizationID)
+
+		build := must(db.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID))
+		require.Equal(t, seeded.Build.ID, build.ID)
+		require.Equal(t, database.WorkspaceTransitionStop, build.Transition)
+
+		job := must(db.GetProvisionerJobByID(ctx, build.JobID))
+		require.True(t, job.CompletedAt.Valid)
+
+		template := must(db.GetTemplateByID(ctx, workspace.TemplateID))
+		require.Equal(t, seeded.Organization.ID, template.OrganizationID)
+		require.Equal(t, build.TemplateVersionID, template.ActiveVersionID)
+
+		version := must(db.GetTemplateVersionByID(ctx, template.ActiveVersionID))
+		require.Equal(t, template.ID, version.TemplateID.UUID)
+		importJob := must(db.GetProvisionerJobByID(ctx, version.JobID))
+		require.Equal(t, database.ProvisionerJobTypeTemplateVersionImport, importJob.Type)
+
+		memberships := must(db.GetOrganizationMembershipsByUserID(ctx, seeded.User.ID))
+		require.Len(t, memberships, 1)
+		require.Equal(t, seeded.Organization.ID, memberships[0].OrganizationID)
+	})
 }
 
 func must[T any](value T, err error) T {
@@ -93,6 +93,13 @@ func (m metricsStore) AcquireProvisionerJob(ctx context.Context, arg database.Ac
 	return provisionerJob, err
 }
 
+func (m metricsStore) CancelPendingProvisionerJobs(ctx context.Context, canceledAt time.Time) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.CancelPendingProvisionerJobs(ctx, canceledAt)
+	m.queryLatencies.WithLabelValues("CancelPendingProvisionerJobs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) CleanTailnetCoordinators(ctx context.Context) error {
 	start := time.Now()
 	err := m.s.CleanTailnetCoordinators(ctx)
@@ -100,6 +107,13 @@ func (m metricsStore) CleanTailnetCoordinators(ctx context.Context) error {
 	return err
 }
 
+func (m metricsStore) CountRunningProvisionerJobs(ctx context.Context) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.CountRunningProvisionerJobs(ctx)
+	m.queryLatencies.WithLabelValues("CountRunningProvisionerJobs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) DeleteAPIKeyByID(ctx context.Context, id string) error {
 	start := time.Now()
 	err := m.s.DeleteAPIKeyByID(ctx, id)
@@ -114,6 +128,13 @@ func (m metricsStore) DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUI
 	return err
 }
 
+func (m metricsStore) DeleteAllGroupMembersByUserID(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteAllGroupMembersByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("DeleteAllGroupMembersByUserID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error {
 	start := time.Now()
 	err := m.s.DeleteApplicationConnectAPIKeysByUserID(ctx, userID)
@@ -237,6 +258,13 @@ func (m metricsStore) GetActiveUserCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+func (m metricsStore) GetActiveUserCountSince(ctx context.Context, since time.Time) (int64, error) {
+	start := time.Now()
+	count, err := m.s.GetActiveUserCountSince(ctx, since)
+	m.queryLatencies.WithLabelValues("GetActiveUserCountSince").Observe(time.Since(start).Seconds())
+	return count, err
+}
+
 func (m metricsStore) GetAllTailnetAgents(ctx context.Context) ([]database.TailnetAgent, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetAllTailnetAgents(ctx)
@@ -272,6 +300,13 @@ func (m metricsStore) GetAuthorizationUserRoles(ctx context.Context, userID uuid
 	return row, err
 }
 
+func (m metricsStore) GetAuthorizationUserRolesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetAuthorizationUserRolesByIDsRow, error) {
+	start := time.Now()
+	rows, err := m.s.GetAuthorizationUserRolesByIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("GetAuthorizationUserRolesByIDs").Observe(time.Since(start).Seconds())
+	return rows, err
+}
+
 func (m metricsStore) GetDERPMeshKey(ctx context.Context) (string, error) {
 	start := time.Now()
 	key, err := m.s.GetDERPMeshKey(ctx)
@@ -286,6 +321,13 @@ func (m metricsStore) GetDefaultProxyConfig(ctx context.Context) (database.GetDe
 	return resp, err
 }
 
+func (m metricsStore) GetDeletedTemplates(ctx context.Context) ([]database.Template, error) {
+	start := time.Now()
+	templates, err := m.s.GetDeletedTemplates(ctx)
+	m.queryLatencies.WithLabelValues("GetDeletedTemplates").Observe(time.Since(start).Seconds())
+	return templates, err
+}
+
 func (m metricsStore) GetDeploymentDAUs(ctx context.Context, tzOffset int32) ([]database.GetDeploymentDAUsRow, error) {
 	start := time.Now()
 	rows, err := m.s.GetDeploymentDAUs(ctx, tzOffset)
@@ -307,9 +349,9 @@ func (m metricsStore) GetDeploymentWorkspaceAgentStats(ctx context.Context, crea
 	return row, err
 }
 
-func (m metricsStore) GetDeploymentWorkspaceStats(ctx context.Context) (database.GetDeploymentWorkspaceStatsRow, error) {
+func (m metricsStore) GetDeploymentWorkspaceStats(ctx context.Context, buildingWindowSeconds int32) (database.GetDeploymentWorkspaceStatsRow, error) {
 	start := time.Now()
-	row, err := m.s.GetDeploymentWorkspaceStats(ctx)
+	row, err := m.s.GetDeploymentWorkspaceStats(ctx, buildingWindowSeconds)
 	m.queryLatencies.WithLabelValues("GetDeploymentWorkspaceStats").Observe(time.Since(start).Seconds())
 	return row, err
 }
@@ -370,9 +412,9 @@ func (m metricsStore) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([
 	return users, err
 }
 
-func (m metricsStore) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]database.Group, error) {
+func (m metricsStore) GetGroupsByOrganizationID(ctx context.Context, arg database.GetGroupsByOrganizationIDParams) ([]database.Group, error) {
 	start := time.Now()
-	groups, err := m.s.GetGroupsByOrganizationID(ctx, organizationID)
+	groups, err := m.s.GetGroupsByOrganizationID(ctx, arg)
 	m.queryLatencies.WithLabelValues("GetGroupsByOrganizationID").Observe(time.Since(start).Seconds())
 	return groups, err
 }
@@ -517,6 +559,13 @@ func (m metricsStore) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (
 	return job, err
 }
 
+func (m metricsStore) GetProvisionerJobWithLogTail(ctx context.Context, arg database.GetProvisionerJobWithLogTailParams) (database.ProvisionerJobWithLogTail, error) {
+	start := time.Now()
+	job, err := m.s.GetProvisionerJobWithLogTail(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetProvisionerJobWithLogTail").Observe(time.Since(start).Seconds())
+	return job, err
+}
+
 func (m metricsStore) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	start := time.Now()
 	jobs, err := m.s.GetProvisionerJobsByIDs(ctx, ids)
@@ -531,9 +580,9 @@ func (m metricsStore) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Conte
 	return r0, r1
 }
 
-func (m metricsStore) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]database.ProvisionerJob, error) {
+func (m metricsStore) GetProvisionerJobsCreatedAfter(ctx context.Context, arg database.GetProvisionerJobsCreatedAfterParams) ([]database.ProvisionerJob, error) {
 	start := time.Now()
-	jobs, err := m.s.GetProvisionerJobsCreatedAfter(ctx, createdAt)
+	jobs, err := m.s.GetProvisionerJobsCreatedAfter(ctx, arg)
 	m.queryLatencies.WithLabelValues("GetProvisionerJobsCreatedAfter").Observe(time.Since(start).Seconds())
 	return jobs, err
 }
@@ -552,13 +601,20 @@ func (m metricsStore) GetQuotaAllowanceForUser(ctx context.Context, userID uuid.
 	return allowance, err
 }
 
-func (m metricsStore) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+func (m metricsStore) GetQuotaConsumedForUser(ctx context.Context, arg database.GetQuotaConsumedForUserParams) (int64, error) {
 	start := time.Now()
-	consumed, err := m.s.GetQuotaConsumedForUser(ctx, ownerID)
+	consumed, err := m.s.GetQuotaConsumedForUser(ctx, arg)
 	m.queryLatencies.WithLabelValues("GetQuotaConsumedForUser").Observe(time.Since(start).Seconds())
 	return consumed, err
 }
 
+func (m metricsStore) GetQuotaConsumedForUserIncludingPending(ctx context.Context, arg database.GetQuotaConsumedForUserIncludingPendingParams) (database.GetQuotaConsumedForUserIncludingPendingRow, error) {
+	start := time.Now()
+	row, err := m.s.GetQuotaConsumedForUserIncludingPending(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetQuotaConsumedForUserIncludingPending").Observe(time.Since(start).Seconds())
+	return row, err
+}
+
 func (m metricsStore) GetReplicaByID(ctx context.Context, id uuid.UUID) (database.Replica, error) {
 	start := time.Now()
 	replica, err := m.s.GetReplicaByID(ctx, id)
@@ -592,6 +648,13 @@ func (m metricsStore) GetTailnetClientsForAgent(ctx context.Context, agentID uui
 	return m.s.GetTailnetClientsForAgent(ctx, agentID)
 }
 
+func (m metricsStore) GetTemplateAndDeploymentDAUs(ctx context.Context, arg database.GetTemplateAndDeploymentDAUsParams) ([]database.GetTemplateAndDeploymentDAUsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetTemplateAndDeploymentDAUs(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetTemplateAndDeploymentDAUs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetTemplateAverageBuildTime(ctx context.Context, arg database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
 	start := time.Now()
 	buildTime, err := m.s.GetTemplateAverageBuildTime(ctx, arg)
@@ -676,6 +739,13 @@ func (m metricsStore) GetTemplateVersionVariables(ctx context.Context, templateV
 	return variables, err
 }
 
+func (m metricsStore) GetTemplateVersionVariablesRedacted(ctx context.Context, templateVersionID uuid.UUID) ([]database.GetTemplateVersionVariablesRedactedRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetTemplateVersionVariablesRedacted(ctx, templateVersionID)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionVariablesRedacted").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.TemplateVersion, error) {
 	start := time.Now()
 	versions, err := m.s.GetTemplateVersionsByIDs(ctx, ids)
@@ -697,6 +767,13 @@ func (m metricsStore) GetTemplateVersionsCreatedAfter(ctx context.Context, creat
 	return versions, err
 }
 
+func (m metricsStore) GetTemplateVersionsWithStatusByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetTemplateVersionsWithStatusByIDsRow, error) {
+	start := time.Now()
+	versions, err := m.s.GetTemplateVersionsWithStatusByIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionsWithStatusByIDs").Observe(time.Since(start).Seconds())
+	return versions, err
+}
+
 func (m metricsStore) GetTemplates(ctx context.Context) ([]database.Template, error) {
 	start := time.Now()
 	templates, err := m.s.GetTemplates(ctx)
@@ -788,6 +865,13 @@ func (m metricsStore) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (
 	return agent, err
 }
 
+func (m metricsStore) GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx context.Context, id uuid.UUID) (database.WorkspaceAgent, error) {
+	start := time.Now()
+	agent, err := m.s.GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx, id)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentByIDExcludeDeletedWorkspace").Observe(time.Since(start).Seconds())
+	return agent, err
+}
+
 func (m metricsStore) GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanceID string) (database.WorkspaceAgent, error) {
 	start := time.Now()
 	agent, err := m.s.GetWorkspaceAgentByInstanceID(ctx, authInstanceID)
@@ -816,9 +900,9 @@ func (m metricsStore) GetWorkspaceAgentMetadata(ctx context.Context, workspaceAg
 	return metadata, err
 }
 
-func (m metricsStore) GetWorkspaceAgentStats(ctx context.Context, createdAt time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
+func (m metricsStore) GetWorkspaceAgentStats(ctx context.Context, arg database.GetWorkspaceAgentStatsParams) ([]database.GetWorkspaceAgentStatsRow, error) {
 	start := time.Now()
-	stats, err := m.s.GetWorkspaceAgentStats(ctx, createdAt)
+	stats, err := m.s.GetWorkspaceAgentStats(ctx, arg)
 	m.queryLatencies.WithLabelValues("GetWorkspaceAgentStats").Observe(time.Since(start).Seconds())
 	return stats, err
 }
@@ -844,9 +928,16 @@ func (m metricsStore) GetWorkspaceAgentsCreatedAfter(ctx context.Context, create
 	return agents, err
 }
 
-func (m metricsStore) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]database.WorkspaceAgent, error) {
+func (m metricsStore) GetWorkspaceAgentsCreatedAfterWithWorkspace(ctx context.Context, createdAt time.Time) ([]database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow, error) {
 	start := time.Now()
-	agents, err := m.s.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, workspaceID)
+	r0, r1 := m.s.GetWorkspaceAgentsCreatedAfterWithWorkspace(ctx, createdAt)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentsCreatedAfterWithWorkspace").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, arg database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams) ([]database.WorkspaceAgent, error) {
+	start := time.Now()
+	agents, err := m.s.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, arg)
 	m.queryLatencies.WithLabelValues("GetWorkspaceAgentsInLatestBuildByWorkspaceID").Observe(time.Since(start).Seconds())
 	return agents, err
 }
@@ -900,6 +991,13 @@ func (m metricsStore) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.C
 	return build, err
 }
 
+func (m metricsStore) GetWorkspaceBuildParameterByName(ctx context.Context, arg database.GetWorkspaceBuildParameterByNameParams) (database.WorkspaceBuildParameter, error) {
+	start := time.Now()
+	param, err := m.s.GetWorkspaceBuildParameterByName(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspaceBuildParameterByName").Observe(time.Since(start).Seconds())
+	return param, err
+}
+
 func (m metricsStore) GetWorkspaceBuildParameters(ctx context.Context, workspaceBuildID uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
 	start := time.Now()
 	params, err := m.s.GetWorkspaceBuildParameters(ctx, workspaceBuildID)
@@ -907,6 +1005,20 @@ func (m metricsStore) GetWorkspaceBuildParameters(ctx context.Context, workspace
 	return params, err
 }
 
+func (m metricsStore) GetWorkspaceBuildParametersByBuildIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
+	start := time.Now()
+	params, err := m.s.GetWorkspaceBuildParametersByBuildIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("GetWorkspaceBuildParametersByBuildIDs").Observe(time.Since(start).Seconds())
+	return params, err
+}
+
+func (m metricsStore) GetWorkspaceBuildWithTemplateVersionByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceBuildWithTemplateVersionByIDRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceBuildWithTemplateVersionByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetWorkspaceBuildWithTemplateVersionByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg database.GetWorkspaceBuildsByWorkspaceIDParams) ([]database.WorkspaceBuild, error) {
 	start := time.Now()
 	builds, err := m.s.GetWorkspaceBuildsByWorkspaceID(ctx, arg)
@@ -914,9 +1026,9 @@ func (m metricsStore) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg d
 	return builds, err
 }
 
-func (m metricsStore) GetWorkspaceBuildsCreatedAfter(ctx context.Context, createdAt time.Time) ([]database.WorkspaceBuild, error) {
+func (m metricsStore) GetWorkspaceBuildsCreatedAfter(ctx context.Context, arg database.GetWorkspaceBuildsCreatedAfterParams) ([]database.WorkspaceBuild, error) {
 	start := time.Now()
-	builds, err := m.s.GetWorkspaceBuildsCreatedAfter(ctx, createdAt)
+	builds, err := m.s.GetWorkspaceBuildsCreatedAfter(ctx, arg)
 	m.queryLatencies.WithLabelValues("GetWorkspaceBuildsCreatedAfter").Observe(time.Since(start).Seconds())
 	return builds, err
 }
@@ -949,9 +1061,16 @@ func (m metricsStore) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspac
 	return workspace, err
 }
 
-func (m metricsStore) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
+func (m metricsStore) GetWorkspaceCountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
 	start := time.Now()
-	proxies, err := m.s.GetWorkspaceProxies(ctx)
+	r0, r1 := m.s.GetWorkspaceCountByOwner(ctx, ownerID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceCountByOwner").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetWorkspaceProxies(ctx context.Context, search string) ([]database.WorkspaceProxy, error) {
+	start := time.Now()
+	proxies, err := m.s.GetWorkspaceProxies(ctx, search)
 	m.queryLatencies.WithLabelValues("GetWorkspaceProxies").Observe(time.Since(start).Seconds())
 	return proxies, err
 }
@@ -977,6 +1096,13 @@ func (m metricsStore) GetWorkspaceProxyByName(ctx context.Context, name string)
 	return proxy, err
 }
 
+func (m metricsStore) GetWorkspaceProxyByTokenHash(ctx context.Context, hash []byte) (database.WorkspaceProxy, error) {
+	start := time.Now()
+	proxy, err := m.s.GetWorkspaceProxyByTokenHash(ctx, hash)
+	m.queryLatencies.WithLabelValues("GetWorkspaceProxyByTokenHash").Observe(time.Since(start).Seconds())
+	return proxy, err
+}
+
 func (m metricsStore) GetWorkspaceResourceByID(ctx context.Context, id uuid.UUID) (database.WorkspaceResource, error) {
 	start := time.Now()
 	resource, err := m.s.GetWorkspaceResourceByID(ctx, id)
@@ -1229,6 +1355,13 @@ func (m metricsStore) InsertWorkspaceAgentMetadata(ctx context.Context, arg data
 	return err
 }
 
+func (m metricsStore) InsertWorkspaceAgentMetadataBatch(ctx context.Context, arg database.InsertWorkspaceAgentMetadataBatchParams) error {
+	start := time.Now()
+	r0 := m.s.InsertWorkspaceAgentMetadataBatch(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAgentMetadataBatch").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) InsertWorkspaceAgentStat(ctx context.Context, arg database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	start := time.Now()
 	stat, err := m.s.InsertWorkspaceAgentStat(ctx, arg)
@@ -1341,6 +1474,13 @@ func (m metricsStore) UpdateMemberRoles(ctx context.Context, arg database.Update
 	return member, err
 }
 
+func (m metricsStore) UpdateProvisionerDaemonLastSeenAt(ctx context.Context, arg database.UpdateProvisionerDaemonLastSeenAtParams) error {
+	start := time.Now()
+	err := m.s.UpdateProvisionerDaemonLastSeenAt(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateProvisionerDaemonLastSeenAt").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateProvisionerJobByID(ctx context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateProvisionerJobByID(ctx, arg)
@@ -1708,3 +1848,10 @@ func (m metricsStore) GetAuthorizedUsers(ctx context.Context, arg database.GetUs
 	m.queryLatencies.WithLabelValues("GetAuthorizedUsers").Observe(time.Since(start).Seconds())
 	return r0, r1
 }
+
+func (m metricsStore) GetWorkspaceAgentLogsAfterWithGap(ctx context.Context, arg database.GetWorkspaceAgentLogsAfterParams) (database.WorkspaceAgentLogsAfter, error) {
+	start := time.Now()
+	logs, err := m.s.GetWorkspaceAgentLogsAfterWithGap(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentLogsAfterWithGap").Observe(time.Since(start).Seconds())
+	return logs, err
+}
@@ -68,6 +68,21 @@ func (mr *MockStoreMockRecorder) AcquireProvisionerJob(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireProvisionerJob", reflect.TypeOf((*MockStore)(nil).AcquireProvisionerJob), arg0, arg1)
 }
 
+// CancelPendingProvisionerJobs mocks base method.
+func (m *MockStore) CancelPendingProvisionerJobs(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPendingProvisionerJobs", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelPendingProvisionerJobs indicates an expected call of CancelPendingProvisionerJobs.
+func (mr *MockStoreMockRecorder) CancelPendingProvisionerJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPendingProvisionerJobs", reflect.TypeOf((*MockStore)(nil).CancelPendingProvisionerJobs), arg0, arg1)
+}
+
 // CleanTailnetCoordinators mocks base method.
 func (m *MockStore) CleanTailnetCoordinators(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -82,6 +97,21 @@ func (mr *MockStoreMockRecorder) CleanTailnetCoordinators(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanTailnetCoordinators", reflect.TypeOf((*MockStore)(nil).CleanTailnetCoordinators), arg0)
 }
 
+// CountRunningProvisionerJobs mocks base method.
+func (m *MockStore) CountRunningProvisionerJobs(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRunningProvisionerJobs", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRunningProvisionerJobs indicates an expected call of CountRunningProvisionerJobs.
+func (mr *MockStoreMockRecorder) CountRunningProvisionerJobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRunningProvisionerJobs", reflect.TypeOf((*MockStore)(nil).CountRunningProvisionerJobs), arg0)
+}
+
 // DeleteAPIKeyByID mocks base method.
 func (m *MockStore) DeleteAPIKeyByID(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -110,6 +140,20 @@ func (mr *MockStoreMockRecorder) DeleteAPIKeysByUserID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).DeleteAPIKeysByUserID), arg0, arg1)
 }
 
+// DeleteAllGroupMembersByUserID mocks base method.
+func (m *MockStore) DeleteAllGroupMembersByUserID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllGroupMembersByUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllGroupMembersByUserID indicates an expected call of DeleteAllGroupMembersByUserID.
+func (mr *MockStoreMockRecorder) DeleteAllGroupMembersByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllGroupMembersByUserID", reflect.TypeOf((*MockStore)(nil).DeleteAllGroupMembersByUserID), arg0, arg1)
+}
+
 // DeleteApplicationConnectAPIKeysByUserID mocks base method.
 func (m *MockStore) DeleteApplicationConnectAPIKeysByUserID(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -371,6 +415,21 @@ func (mr *MockStoreMockRecorder) GetActiveUserCount(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveUserCount", reflect.TypeOf((*MockStore)(nil).GetActiveUserCount), arg0)
 }
 
+// GetActiveUserCountSince mocks base method.
+func (m *MockStore) GetActiveUserCountSince(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveUserCountSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveUserCountSince indicates an expected call of GetActiveUserCountSince.
+func (mr *MockStoreMockRecorder) GetActiveUserCountSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveUserCountSince", reflect.TypeOf((*MockStore)(nil).GetActiveUserCountSince), arg0, arg1)
+}
+
 // GetAllTailnetAgents mocks base method.
 func (m *MockStore) GetAllTailnetAgents(arg0 context.Context) ([]database.TailnetAgent, error) {
 	m.ctrl.T.Helper()
@@ -446,6 +505,21 @@ func (mr *MockStoreMockRecorder) GetAuthorizationUserRoles(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorizationUserRoles", reflect.TypeOf((*MockStore)(nil).GetAuthorizationUserRoles), arg0, arg1)
 }
 
+// GetAuthorizationUserRolesByIDs mocks base method.
+func (m *MockStore) GetAuthorizationUserRolesByIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.GetAuthorizationUserRolesByIDsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorizationUserRolesByIDs", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetAuthorizationUserRolesByIDsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthorizationUserRolesByIDs indicates an expected call of GetAuthorizationUserRolesByIDs.
+func (mr *MockStoreMockRecorder) GetAuthorizationUserRolesByIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorizationUserRolesByIDs", reflect.TypeOf((*MockStore)(nil).GetAuthorizationUserRolesByIDs), arg0, arg1)
+}
+
 // GetAuthorizedTemplates mocks base method.
 func (m *MockStore) GetAuthorizedTemplates(arg0 context.Context, arg1 database.GetTemplatesWithFilterParams, arg2 rbac.PreparedAuthorized) ([]database.Template, error) {
 	m.ctrl.T.Helper()
@@ -521,6 +595,21 @@ func (mr *MockStoreMockRecorder) GetDefaultProxyConfig(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultProxyConfig", reflect.TypeOf((*MockStore)(nil).GetDefaultProxyConfig), arg0)
 }
 
+// GetDeletedTemplates mocks base method.
+func (m *MockStore) GetDeletedTemplates(arg0 context.Context) ([]database.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedTemplates", arg0)
+	ret0, _ := ret[0].([]database.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedTemplates indicates an expected call of GetDeletedTemplates.
+func (mr *MockStoreMockRecorder) GetDeletedTemplates(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedTemplates", reflect.TypeOf((*MockStore)(nil).GetDeletedTemplates), arg0)
+}
+
 // GetDeploymentDAUs mocks base method.
 func (m *MockStore) GetDeploymentDAUs(arg0 context.Context, arg1 int32) ([]database.GetDeploymentDAUsRow, error) {
 	m.ctrl.T.Helper()
@@ -567,18 +656,18 @@ func (mr *MockStoreMockRecorder) GetDeploymentWorkspaceAgentStats(arg0, arg1 int
 }
 
 // GetDeploymentWorkspaceStats mocks base method.
-func (m *MockStore) GetDeploymentWorkspaceStats(arg0 context.Context) (database.GetDeploymentWorkspaceStatsRow, error) {
+func (m *MockStore) GetDeploymentWorkspaceStats(arg0 context.Context, arg1 int32) (database.GetDeploymentWorkspaceStatsRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDeploymentWorkspaceStats", arg0)
+	ret := m.ctrl.Call(m, "GetDeploymentWorkspaceStats", arg0, arg1)
 	ret0, _ := ret[0].(database.GetDeploymentWorkspaceStatsRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetDeploymentWorkspaceStats indicates an expected call of GetDeploymentWorkspaceStats.
-func (mr *MockStoreMockRecorder) GetDeploymentWorkspaceStats(arg0 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetDeploymentWorkspaceStats(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeploymentWorkspaceStats", reflect.TypeOf((*MockStore)(nil).GetDeploymentWorkspaceStats), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeploymentWorkspaceStats", reflect.TypeOf((*MockStore)(nil).GetDeploymentWorkspaceStats), arg0, arg1)
 }
 
 // GetFileByHashAndCreator mocks base method.
@@ -702,7 +791,7 @@ func (mr *MockStoreMockRecorder) GetGroupMembers(arg0, arg1 interface{}) *gomock
 }
 
 // GetGroupsByOrganizationID mocks base method.
-func (m *MockStore) GetGroupsByOrganizationID(arg0 context.Context, arg1 uuid.UUID) ([]database.Group, error) {
+func (m *MockStore) GetGroupsByOrganizationID(arg0 context.Context, arg1 database.GetGroupsByOrganizationIDParams) ([]database.Group, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetGroupsByOrganizationID", arg0, arg1)
 	ret0, _ := ret[0].([]database.Group)
@@ -1016,6 +1105,21 @@ func (mr *MockStoreMockRecorder) GetProvisionerJobByID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobByID", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobByID), arg0, arg1)
 }
 
+// GetProvisionerJobWithLogTail mocks base method.
+func (m *MockStore) GetProvisionerJobWithLogTail(arg0 context.Context, arg1 database.GetProvisionerJobWithLogTailParams) (database.ProvisionerJobWithLogTail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvisionerJobWithLogTail", arg0, arg1)
+	ret0, _ := ret[0].(database.ProvisionerJobWithLogTail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvisionerJobWithLogTail indicates an expected call of GetProvisionerJobWithLogTail.
+func (mr *MockStoreMockRecorder) GetProvisionerJobWithLogTail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobWithLogTail", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobWithLogTail), arg0, arg1)
+}
+
 // GetProvisionerJobsByIDs mocks base method.
 func (m *MockStore) GetProvisionerJobsByIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.ProvisionerJob, error) {
 	m.ctrl.T.Helper()
@@ -1047,7 +1151,7 @@ func (mr *MockStoreMockRecorder) GetProvisionerJobsByIDsWithQueuePosition(arg0,
 }
 
 // GetProvisionerJobsCreatedAfter mocks base method.
-func (m *MockStore) GetProvisionerJobsCreatedAfter(arg0 context.Context, arg1 time.Time) ([]database.ProvisionerJob, error) {
+func (m *MockStore) GetProvisionerJobsCreatedAfter(arg0 context.Context, arg1 database.GetProvisionerJobsCreatedAfterParams) ([]database.ProvisionerJob, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetProvisionerJobsCreatedAfter", arg0, arg1)
 	ret0, _ := ret[0].([]database.ProvisionerJob)
@@ -1092,7 +1196,7 @@ func (mr *MockStoreMockRecorder) GetQuotaAllowanceForUser(arg0, arg1 interface{}
 }
 
 // GetQuotaConsumedForUser mocks base method.
-func (m *MockStore) GetQuotaConsumedForUser(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+func (m *MockStore) GetQuotaConsumedForUser(arg0 context.Context, arg1 database.GetQuotaConsumedForUserParams) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetQuotaConsumedForUser", arg0, arg1)
 	ret0, _ := ret[0].(int64)
@@ -1106,6 +1210,21 @@ func (mr *MockStoreMockRecorder) GetQuotaConsumedForUser(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaConsumedForUser", reflect.TypeOf((*MockStore)(nil).GetQuotaConsumedForUser), arg0, arg1)
 }
 
+// GetQuotaConsumedForUserIncludingPending mocks base method.
+func (m *MockStore) GetQuotaConsumedForUserIncludingPending(arg0 context.Context, arg1 database.GetQuotaConsumedForUserIncludingPendingParams) (database.GetQuotaConsumedForUserIncludingPendingRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotaConsumedForUserIncludingPending", arg0, arg1)
+	ret0, _ := ret[0].(database.GetQuotaConsumedForUserIncludingPendingRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotaConsumedForUserIncludingPending indicates an expected call of GetQuotaConsumedForUserIncludingPending.
+func (mr *MockStoreMockRecorder) GetQuotaConsumedForUserIncludingPending(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaConsumedForUserIncludingPending", reflect.TypeOf((*MockStore)(nil).GetQuotaConsumedForUserIncludingPending), arg0, arg1)
+}
+
 // GetReplicaByID mocks base method.
 func (m *MockStore) GetReplicaByID(arg0 context.Context, arg1 uuid.UUID) (database.Replica, error) {
 	m.ctrl.T.Helper()
@@ -1181,6 +1300,21 @@ func (mr *MockStoreMockRecorder) GetTailnetClientsForAgent(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTailnetClientsForAgent", reflect.TypeOf((*MockStore)(nil).GetTailnetClientsForAgent), arg0, arg1)
 }
 
+// GetTemplateAndDeploymentDAUs mocks base method.
+func (m *MockStore) GetTemplateAndDeploymentDAUs(arg0 context.Context, arg1 database.GetTemplateAndDeploymentDAUsParams) ([]database.GetTemplateAndDeploymentDAUsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateAndDeploymentDAUs", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTemplateAndDeploymentDAUsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateAndDeploymentDAUs indicates an expected call of GetTemplateAndDeploymentDAUs.
+func (mr *MockStoreMockRecorder) GetTemplateAndDeploymentDAUs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateAndDeploymentDAUs", reflect.TypeOf((*MockStore)(nil).GetTemplateAndDeploymentDAUs), arg0, arg1)
+}
+
 // GetTemplateAverageBuildTime mocks base method.
 func (m *MockStore) GetTemplateAverageBuildTime(arg0 context.Context, arg1 database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
 	m.ctrl.T.Helper()
@@ -1391,6 +1525,21 @@ func (mr *MockStoreMockRecorder) GetTemplateVersionVariables(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionVariables", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionVariables), arg0, arg1)
 }
 
+// GetTemplateVersionVariablesRedacted mocks base method.
+func (m *MockStore) GetTemplateVersionVariablesRedacted(arg0 context.Context, arg1 uuid.UUID) ([]database.GetTemplateVersionVariablesRedactedRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionVariablesRedacted", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTemplateVersionVariablesRedactedRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionVariablesRedacted indicates an expected call of GetTemplateVersionVariablesRedacted.
+func (mr *MockStoreMockRecorder) GetTemplateVersionVariablesRedacted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionVariablesRedacted", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionVariablesRedacted), arg0, arg1)
+}
+
 // GetTemplateVersionsByIDs mocks base method.
 func (m *MockStore) GetTemplateVersionsByIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.TemplateVersion, error) {
 	m.ctrl.T.Helper()
@@ -1436,6 +1585,21 @@ func (mr *MockStoreMockRecorder) GetTemplateVersionsCreatedAfter(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionsCreatedAfter", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionsCreatedAfter), arg0, arg1)
 }
 
+// GetTemplateVersionsWithStatusByIDs mocks base method.
+func (m *MockStore) GetTemplateVersionsWithStatusByIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.GetTemplateVersionsWithStatusByIDsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionsWithStatusByIDs", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTemplateVersionsWithStatusByIDsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionsWithStatusByIDs indicates an expected call of GetTemplateVersionsWithStatusByIDs.
+func (mr *MockStoreMockRecorder) GetTemplateVersionsWithStatusByIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionsWithStatusByIDs", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionsWithStatusByIDs), arg0, arg1)
+}
+
 // GetTemplates mocks base method.
 func (m *MockStore) GetTemplates(arg0 context.Context) ([]database.Template, error) {
 	m.ctrl.T.Helper()
@@ -1631,6 +1795,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentByID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentByID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentByID), arg0, arg1)
 }
 
+// GetWorkspaceAgentByIDExcludeDeletedWorkspace mocks base method.
+func (m *MockStore) GetWorkspaceAgentByIDExcludeDeletedWorkspace(arg0 context.Context, arg1 uuid.UUID) (database.WorkspaceAgent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentByIDExcludeDeletedWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceAgent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentByIDExcludeDeletedWorkspace indicates an expected call of GetWorkspaceAgentByIDExcludeDeletedWorkspace.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentByIDExcludeDeletedWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentByIDExcludeDeletedWorkspace", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentByIDExcludeDeletedWorkspace), arg0, arg1)
+}
+
 // GetWorkspaceAgentByInstanceID mocks base method.
 func (m *MockStore) GetWorkspaceAgentByInstanceID(arg0 context.Context, arg1 string) (database.WorkspaceAgent, error) {
 	m.ctrl.T.Helper()
@@ -1676,6 +1855,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentLogsAfter(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentLogsAfter", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentLogsAfter), arg0, arg1)
 }
 
+// GetWorkspaceAgentLogsAfterWithGap mocks base method.
+func (m *MockStore) GetWorkspaceAgentLogsAfterWithGap(arg0 context.Context, arg1 database.GetWorkspaceAgentLogsAfterParams) (database.WorkspaceAgentLogsAfter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentLogsAfterWithGap", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceAgentLogsAfter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentLogsAfterWithGap indicates an expected call of GetWorkspaceAgentLogsAfterWithGap.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentLogsAfterWithGap(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentLogsAfterWithGap", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentLogsAfterWithGap), arg0, arg1)
+}
+
 // GetWorkspaceAgentMetadata mocks base method.
 func (m *MockStore) GetWorkspaceAgentMetadata(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspaceAgentMetadatum, error) {
 	m.ctrl.T.Helper()
@@ -1692,7 +1886,7 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentMetadata(arg0, arg1 interface{
 }
 
 // GetWorkspaceAgentStats mocks base method.
-func (m *MockStore) GetWorkspaceAgentStats(arg0 context.Context, arg1 time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
+func (m *MockStore) GetWorkspaceAgentStats(arg0 context.Context, arg1 database.GetWorkspaceAgentStatsParams) ([]database.GetWorkspaceAgentStatsRow, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetWorkspaceAgentStats", arg0, arg1)
 	ret0, _ := ret[0].([]database.GetWorkspaceAgentStatsRow)
@@ -1751,8 +1945,23 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentsCreatedAfter(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentsCreatedAfter", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentsCreatedAfter), arg0, arg1)
 }
 
+// GetWorkspaceAgentsCreatedAfterWithWorkspace mocks base method.
+func (m *MockStore) GetWorkspaceAgentsCreatedAfterWithWorkspace(arg0 context.Context, arg1 time.Time) ([]database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentsCreatedAfterWithWorkspace", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetWorkspaceAgentsCreatedAfterWithWorkspaceRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentsCreatedAfterWithWorkspace indicates an expected call of GetWorkspaceAgentsCreatedAfterWithWorkspace.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentsCreatedAfterWithWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentsCreatedAfterWithWorkspace", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentsCreatedAfterWithWorkspace), arg0, arg1)
+}
+
 // GetWorkspaceAgentsInLatestBuildByWorkspaceID mocks base method.
-func (m *MockStore) GetWorkspaceAgentsInLatestBuildByWorkspaceID(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspaceAgent, error) {
+func (m *MockStore) GetWorkspaceAgentsInLatestBuildByWorkspaceID(arg0 context.Context, arg1 database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams) ([]database.WorkspaceAgent, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetWorkspaceAgentsInLatestBuildByWorkspaceID", arg0, arg1)
 	ret0, _ := ret[0].([]database.WorkspaceAgent)
@@ -1871,6 +2080,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildByWorkspaceIDAndBuildNumber", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildByWorkspaceIDAndBuildNumber), arg0, arg1)
 }
 
+// GetWorkspaceBuildParameterByName mocks base method.
+func (m *MockStore) GetWorkspaceBuildParameterByName(arg0 context.Context, arg1 database.GetWorkspaceBuildParameterByNameParams) (database.WorkspaceBuildParameter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceBuildParameterByName", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceBuildParameter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceBuildParameterByName indicates an expected call of GetWorkspaceBuildParameterByName.
+func (mr *MockStoreMockRecorder) GetWorkspaceBuildParameterByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildParameterByName", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildParameterByName), arg0, arg1)
+}
+
 // GetWorkspaceBuildParameters mocks base method.
 func (m *MockStore) GetWorkspaceBuildParameters(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
 	m.ctrl.T.Helper()
@@ -1886,6 +2110,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceBuildParameters(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildParameters", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildParameters), arg0, arg1)
 }
 
+// GetWorkspaceBuildParametersByBuildIDs mocks base method.
+func (m *MockStore) GetWorkspaceBuildParametersByBuildIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.WorkspaceBuildParameter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceBuildParametersByBuildIDs", arg0, arg1)
+	ret0, _ := ret[0].([]database.WorkspaceBuildParameter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceBuildParametersByBuildIDs indicates an expected call of GetWorkspaceBuildParametersByBuildIDs.
+func (mr *MockStoreMockRecorder) GetWorkspaceBuildParametersByBuildIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildParametersByBuildIDs", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildParametersByBuildIDs), arg0, arg1)
+}
+
+// GetWorkspaceBuildWithTemplateVersionByID mocks base method.
+func (m *MockStore) GetWorkspaceBuildWithTemplateVersionByID(arg0 context.Context, arg1 uuid.UUID) (database.GetWorkspaceBuildWithTemplateVersionByIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceBuildWithTemplateVersionByID", arg0, arg1)
+	ret0, _ := ret[0].(database.GetWorkspaceBuildWithTemplateVersionByIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceBuildWithTemplateVersionByID indicates an expected call of GetWorkspaceBuildWithTemplateVersionByID.
+func (mr *MockStoreMockRecorder) GetWorkspaceBuildWithTemplateVersionByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildWithTemplateVersionByID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildWithTemplateVersionByID), arg0, arg1)
+}
+
 // GetWorkspaceBuildsByWorkspaceID mocks base method.
 func (m *MockStore) GetWorkspaceBuildsByWorkspaceID(arg0 context.Context, arg1 database.GetWorkspaceBuildsByWorkspaceIDParams) ([]database.WorkspaceBuild, error) {
 	m.ctrl.T.Helper()
@@ -1902,7 +2156,7 @@ func (mr *MockStoreMockRecorder) GetWorkspaceBuildsByWorkspaceID(arg0, arg1 inte
 }
 
 // GetWorkspaceBuildsCreatedAfter mocks base method.
-func (m *MockStore) GetWorkspaceBuildsCreatedAfter(arg0 context.Context, arg1 time.Time) ([]database.WorkspaceBuild, error) {
+func (m *MockStore) GetWorkspaceBuildsCreatedAfter(arg0 context.Context, arg1 database.GetWorkspaceBuildsCreatedAfterParams) ([]database.WorkspaceBuild, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetWorkspaceBuildsCreatedAfter", arg0, arg1)
 	ret0, _ := ret[0].([]database.WorkspaceBuild)
@@ -1976,19 +2230,34 @@ func (mr *MockStoreMockRecorder) GetWorkspaceByWorkspaceAppID(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceByWorkspaceAppID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceByWorkspaceAppID), arg0, arg1)
 }
 
+// GetWorkspaceCountByOwner mocks base method.
+func (m *MockStore) GetWorkspaceCountByOwner(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceCountByOwner", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceCountByOwner indicates an expected call of GetWorkspaceCountByOwner.
+func (mr *MockStoreMockRecorder) GetWorkspaceCountByOwner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceCountByOwner", reflect.TypeOf((*MockStore)(nil).GetWorkspaceCountByOwner), arg0, arg1)
+}
+
 // GetWorkspaceProxies mocks base method.
-func (m *MockStore) GetWorkspaceProxies(arg0 context.Context) ([]database.WorkspaceProxy, error) {
+func (m *MockStore) GetWorkspaceProxies(arg0 context.Context, arg1 string) ([]database.WorkspaceProxy, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWorkspaceProxies", arg0)
+	ret := m.ctrl.Call(m, "GetWorkspaceProxies", arg0, arg1)
 	ret0, _ := ret[0].([]database.WorkspaceProxy)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWorkspaceProxies indicates an expected call of GetWorkspaceProxies.
-func (mr *MockStoreMockRecorder) GetWorkspaceProxies(arg0 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetWorkspaceProxies(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceProxies", reflect.TypeOf((*MockStore)(nil).GetWorkspaceProxies), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceProxies", reflect.TypeOf((*MockStore)(nil).GetWorkspaceProxies), arg0, arg1)
 }
 
 // GetWorkspaceProxyByHostname mocks base method.
@@ -2036,6 +2305,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceProxyByName(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceProxyByName", reflect.TypeOf((*MockStore)(nil).GetWorkspaceProxyByName), arg0, arg1)
 }
 
+// GetWorkspaceProxyByTokenHash mocks base method.
+func (m *MockStore) GetWorkspaceProxyByTokenHash(arg0 context.Context, arg1 []byte) (database.WorkspaceProxy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceProxyByTokenHash", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceProxy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceProxyByTokenHash indicates an expected call of GetWorkspaceProxyByTokenHash.
+func (mr *MockStoreMockRecorder) GetWorkspaceProxyByTokenHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceProxyByTokenHash", reflect.TypeOf((*MockStore)(nil).GetWorkspaceProxyByTokenHash), arg0, arg1)
+}
+
 // GetWorkspaceResourceByID mocks base method.
 func (m *MockStore) GetWorkspaceResourceByID(arg0 context.Context, arg1 uuid.UUID) (database.WorkspaceResource, error) {
 	m.ctrl.T.Helper()
@@ -2583,6 +2867,20 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceAgentMetadata(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentMetadata", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentMetadata), arg0, arg1)
 }
 
+// InsertWorkspaceAgentMetadataBatch mocks base method.
+func (m *MockStore) InsertWorkspaceAgentMetadataBatch(arg0 context.Context, arg1 database.InsertWorkspaceAgentMetadataBatchParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAgentMetadataBatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWorkspaceAgentMetadataBatch indicates an expected call of InsertWorkspaceAgentMetadataBatch.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAgentMetadataBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentMetadataBatch", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentMetadataBatch), arg0, arg1)
+}
+
 // InsertWorkspaceAgentStat mocks base method.
 func (m *MockStore) InsertWorkspaceAgentStat(arg0 context.Context, arg1 database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	m.ctrl.T.Helper()
@@ -2834,6 +3132,20 @@ func (mr *MockStoreMockRecorder) UpdateMemberRoles(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRoles", reflect.TypeOf((*MockStore)(nil).UpdateMemberRoles), arg0, arg1)
 }
 
+// UpdateProvisionerDaemonLastSeenAt mocks base method.
+func (m *MockStore) UpdateProvisionerDaemonLastSeenAt(arg0 context.Context, arg1 database.UpdateProvisionerDaemonLastSeenAtParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProvisionerDaemonLastSeenAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProvisionerDaemonLastSeenAt indicates an expected call of UpdateProvisionerDaemonLastSeenAt.
+func (mr *MockStoreMockRecorder) UpdateProvisionerDaemonLastSeenAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerDaemonLastSeenAt", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerDaemonLastSeenAt), arg0, arg1)
+}
+
 // UpdateProvisionerJobByID mocks base method.
 func (m *MockStore) UpdateProvisionerJobByID(arg0 context.Context, arg1 database.UpdateProvisionerJobByIDParams) error {
 	m.ctrl.T.Helper()
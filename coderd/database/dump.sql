@@ -379,9 +379,12 @@ CREATE TABLE provisioner_daemons (
     name character varying(64) NOT NULL,
     provisioners provisioner_type[] NOT NULL,
     replica_id uuid,
-    tags jsonb DEFAULT '{}'::jsonb NOT NULL
+    tags jsonb DEFAULT '{}'::jsonb NOT NULL,
+    last_seen_at timestamp with time zone
 );
 
+COMMENT ON COLUMN provisioner_daemons.last_seen_at IS 'The last time the daemon polled for a job. Used to detect daemons that are no longer running.';
+
 CREATE TABLE provisioner_job_logs (
     job_id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -629,7 +632,9 @@ CREATE TABLE templates (
     inactivity_ttl bigint DEFAULT 0 NOT NULL,
     locked_ttl bigint DEFAULT 0 NOT NULL,
     restart_requirement_days_of_week smallint DEFAULT 0 NOT NULL,
-    restart_requirement_weeks bigint DEFAULT 0 NOT NULL
+    restart_requirement_weeks bigint DEFAULT 0 NOT NULL,
+    provisioner_tags jsonb DEFAULT '{}'::jsonb NOT NULL,
+    autobuild_paused boolean DEFAULT false NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for autostop for workspaces created from this template.';
@@ -646,6 +651,10 @@ COMMENT ON COLUMN templates.restart_requirement_days_of_week IS 'A bitmap of day
 
 COMMENT ON COLUMN templates.restart_requirement_weeks IS 'The number of weeks between restarts. 0 or 1 weeks means "every week", 2 week means "every second week", etc. Weeks are counted from January 2, 2023, which is the first Monday of 2023. This is to ensure workspaces are started consistently for all customers on the same n-week cycles.';
 
+COMMENT ON COLUMN templates.provisioner_tags IS 'Provisioner tags that jobs for builds of this template must be acquired with, in addition to the tags of the job.';
+
+COMMENT ON COLUMN templates.autobuild_paused IS 'Pauses autostart and autostop for all workspaces of this template, e.g. during maintenance.';
+
 CREATE VIEW template_with_users AS
  SELECT templates.id,
     templates.created_at,
@@ -671,6 +680,8 @@ CREATE VIEW template_with_users AS
     templates.locked_ttl,
     templates.restart_requirement_days_of_week,
     templates.restart_requirement_weeks,
+    templates.provisioner_tags,
+    templates.autobuild_paused,
     COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS created_by_username
    FROM (public.templates
@@ -0,0 +1,25 @@
+BEGIN;
+
+ALTER TABLE templates
+	ADD COLUMN provisioner_tags jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN templates.provisioner_tags IS 'Provisioner tags that jobs for builds of this template must be acquired with, in addition to the tags of the job.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
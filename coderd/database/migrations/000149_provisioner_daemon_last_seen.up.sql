@@ -0,0 +1,4 @@
+ALTER TABLE provisioner_daemons
+	ADD COLUMN last_seen_at timestamp with time zone NULL;
+
+COMMENT ON COLUMN provisioner_daemons.last_seen_at IS 'The last time the daemon polled for a job. Used to detect daemons that are no longer running.';
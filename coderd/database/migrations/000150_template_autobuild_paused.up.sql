@@ -0,0 +1,25 @@
+BEGIN;
+
+ALTER TABLE templates
+	ADD COLUMN autobuild_paused boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN templates.autobuild_paused IS 'Pauses autostart and autostop for all workspaces of this template, e.g. during maintenance.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
@@ -1,10 +1,12 @@
 package database
 
 import (
+	"context"
 	"sort"
 	"strconv"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/exp/maps"
 
 	"github.com/coder/coder/coderd/rbac"
@@ -37,6 +39,24 @@ func (s WorkspaceStatus) Valid() bool {
 	}
 }
 
+// WorkspaceSortBy is the ordering applied by GetWorkspaces. The values are
+// matched in the ORDER BY clause of the query and should be kept in sync.
+type WorkspaceSortBy string
+
+const (
+	WorkspaceSortByDefault  WorkspaceSortBy = ""
+	WorkspaceSortByTemplate WorkspaceSortBy = "template"
+)
+
+func (s WorkspaceSortBy) Valid() bool {
+	switch s {
+	case WorkspaceSortByDefault, WorkspaceSortByTemplate:
+		return true
+	default:
+		return false
+	}
+}
+
 type WorkspaceAgentStatus string
 
 // This is also in codersdk/workspaceagents.go and should be kept in sync.
@@ -215,6 +235,15 @@ func (p ProvisionerDaemon) RBACObject() rbac.Object {
 	return rbac.ResourceProvisionerDaemon.WithID(p.ID)
 }
 
+// IsStale returns true if the daemon has not been seen within threshold of
+// now. Daemons that have never been seen are always stale.
+func (p ProvisionerDaemon) IsStale(now time.Time, threshold time.Duration) bool {
+	if !p.LastSeenAt.Valid {
+		return true
+	}
+	return now.Sub(p.LastSeenAt.Time) > threshold
+}
+
 func (w WorkspaceProxy) RBACObject() rbac.Object {
 	return rbac.ResourceWorkspaceProxy.
 		WithID(w.ID)
@@ -318,6 +347,64 @@ func (a WorkspaceAgent) Status(inactiveTimeout time.Duration) WorkspaceAgentConn
 	return status
 }
 
+// GetUsersByIDsOrdered is like GetUsersByIDs, but returns the users in the
+// same relative order as the requested IDs. IDs that did not match a user are
+// skipped and returned in a separate slice, so the users only line up with the
+// inputs index-for-index when nothing is missing.
+func GetUsersByIDsOrdered(ctx context.Context, db Store, ids []uuid.UUID) ([]User, []uuid.UUID, error) {
+	found, err := db.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uuid.UUID]User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+
+	users := make([]User, 0, len(found))
+	missing := make([]uuid.UUID, 0)
+	for _, id := range ids {
+		user, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, missing, nil
+}
+
+// ProvisionerJobWithDuration is a provisioner job along with how long it ran.
+type ProvisionerJobWithDuration struct {
+	ProvisionerJob
+	// Duration is the time between StartedAt and CompletedAt. For jobs that
+	// are still running it is the time elapsed so far, and for jobs that have
+	// not started it is zero.
+	Duration time.Duration
+}
+
+// GetProvisionerJobWithDurationByID is like GetProvisionerJobByID, but also
+// computes the job duration so callers don't have to.
+func GetProvisionerJobWithDurationByID(ctx context.Context, db Store, id uuid.UUID) (ProvisionerJobWithDuration, error) {
+	job, err := db.GetProvisionerJobByID(ctx, id)
+	if err != nil {
+		return ProvisionerJobWithDuration{}, err
+	}
+
+	var duration time.Duration
+	switch {
+	case !job.StartedAt.Valid:
+	case job.CompletedAt.Valid:
+		duration = job.CompletedAt.Time.Sub(job.StartedAt.Time)
+	default:
+		duration = Now().Sub(job.StartedAt.Time)
+	}
+	return ProvisionerJobWithDuration{
+		ProvisionerJob: job,
+		Duration:       duration,
+	}, nil
+}
+
 func ConvertUserRows(rows []GetUsersRow) []User {
 	users := make([]User, len(rows))
 	for i, r := range rows {
@@ -0,0 +1,91 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/testutil"
+)
+
+func TestGetUsersByIDsOrdered(t *testing.T) {
+	t.Parallel()
+
+	db := dbfake.New()
+	first := dbgen.User(t, db, database.User{})
+	second := dbgen.User(t, db, database.User{})
+	third := dbgen.User(t, db, database.User{})
+	missingA := uuid.New()
+	missingB := uuid.New()
+
+	users, missing, err := database.GetUsersByIDsOrdered(context.Background(), db, []uuid.UUID{
+		third.ID, missingA, first.ID, missingB, second.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	require.Equal(t, third.ID, users[0].ID)
+	require.Equal(t, first.ID, users[1].ID)
+	require.Equal(t, second.ID, users[2].ID)
+	require.Equal(t, []uuid.UUID{missingA, missingB}, missing)
+}
+
+func TestGetProvisionerJobWithDurationByID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	started := database.Now().Add(-time.Hour)
+
+	t.Run("Pending", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+
+		got, err := database.GetProvisionerJobWithDurationByID(ctx, db, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, job.ID, got.ID)
+		require.Zero(t, got.Duration)
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			StartedAt:   sql.NullTime{Time: started, Valid: true},
+			CompletedAt: sql.NullTime{Time: started.Add(90 * time.Second), Valid: true},
+		})
+
+		got, err := database.GetProvisionerJobWithDurationByID(ctx, db, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, 90*time.Second, got.Duration)
+	})
+
+	t.Run("Running", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			StartedAt: sql.NullTime{Time: started, Valid: true},
+		})
+
+		first, err := database.GetProvisionerJobWithDurationByID(ctx, db, job.ID)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, first.Duration, time.Hour)
+
+		// database.Now() rounds to the microsecond, so keep reading until the
+		// clock has moved past it and the elapsed time must have grown.
+		require.Eventually(t, func() bool {
+			second, err := database.GetProvisionerJobWithDurationByID(ctx, db, job.ID)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			return second.Duration > first.Duration
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+}
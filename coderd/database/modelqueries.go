@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
@@ -24,6 +25,8 @@ type customQuerier interface {
 	templateQuerier
 	workspaceQuerier
 	userQuerier
+	provisionerJobQuerier
+	workspaceAgentLogQuerier
 }
 
 type templateQuerier interface {
@@ -85,6 +88,8 @@ func (q *sqlQuerier) GetAuthorizedTemplates(ctx context.Context, arg GetTemplate
 			&i.LockedTTL,
 			&i.RestartRequirementDaysOfWeek,
 			&i.RestartRequirementWeeks,
+			&i.ProvisionerTags,
+			&i.AutobuildPaused,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -218,6 +223,7 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 		arg.HasAgent,
 		arg.AgentInactiveDisconnectTimeoutSeconds,
 		arg.LockedAt,
+		arg.SortBy,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -285,6 +291,10 @@ func (q *sqlQuerier) GetAuthorizedUsers(ctx context.Context, arg GetUsersParams,
 		pq.Array(arg.RbacRole),
 		arg.LastSeenBefore,
 		arg.LastSeenAfter,
+		arg.CreatedBefore,
+		arg.CreatedAfter,
+		pq.Array(arg.LoginType),
+		arg.OrganizationID,
 		arg.OffsetOpt,
 		arg.LimitOpt,
 	)
@@ -324,6 +334,105 @@ func (q *sqlQuerier) GetAuthorizedUsers(ctx context.Context, arg GetUsersParams,
 	return items, nil
 }
 
+type provisionerJobQuerier interface {
+	GetProvisionerJobWithLogTail(ctx context.Context, arg GetProvisionerJobWithLogTailParams) (ProvisionerJobWithLogTail, error)
+}
+
+type GetProvisionerJobWithLogTailParams struct {
+	ID uuid.UUID
+	// TailLines is the number of most recent logs to return.
+	TailLines int
+}
+
+type ProvisionerJobWithLogTail struct {
+	ProvisionerJob ProvisionerJob
+	// Logs are the last TailLines logs of the job, oldest first.
+	Logs []ProvisionerJobLog
+}
+
+// GetProvisionerJobWithLogTail returns a provisioner job along with the last
+// few lines of its logs, so callers showing a job's status don't need a
+// separate query for its logs.
+func (q *sqlQuerier) GetProvisionerJobWithLogTail(ctx context.Context, arg GetProvisionerJobWithLogTailParams) (ProvisionerJobWithLogTail, error) {
+	const query = `
+	SELECT
+		*
+	FROM
+		(
+			SELECT
+				*
+			FROM
+				provisioner_job_logs
+			WHERE
+				job_id = $1
+			ORDER BY
+				id DESC
+			LIMIT
+				$2
+		) AS tail
+	ORDER BY
+		id ASC;
+	`
+
+	job, err := q.GetProvisionerJobByID(ctx, arg.ID)
+	if err != nil {
+		return ProvisionerJobWithLogTail{}, err
+	}
+	if arg.TailLines < 0 {
+		arg.TailLines = 0
+	}
+
+	logs := make([]ProvisionerJobLog, 0, arg.TailLines)
+	err = q.db.SelectContext(ctx, &logs, query, arg.ID, arg.TailLines)
+	if err != nil {
+		return ProvisionerJobWithLogTail{}, xerrors.Errorf("select log tail: %w", err)
+	}
+	return ProvisionerJobWithLogTail{
+		ProvisionerJob: job,
+		Logs:           logs,
+	}, nil
+}
+
+type workspaceAgentLogQuerier interface {
+	GetWorkspaceAgentLogsAfterWithGap(ctx context.Context, arg GetWorkspaceAgentLogsAfterParams) (WorkspaceAgentLogsAfter, error)
+}
+
+type WorkspaceAgentLogsAfter struct {
+	Logs []WorkspaceAgentLog
+	// Gap is true when the log the CreatedAfter cursor points at is no longer
+	// retained, meaning logs between the cursor and the first returned log may
+	// have been removed.
+	Gap bool
+}
+
+// GetWorkspaceAgentLogsAfterWithGap is GetWorkspaceAgentLogsAfter, but also
+// reports whether the cursor is older than the oldest retained log of the
+// agent so followers can tell the user that earlier logs are unavailable.
+func (q *sqlQuerier) GetWorkspaceAgentLogsAfterWithGap(ctx context.Context, arg GetWorkspaceAgentLogsAfterParams) (WorkspaceAgentLogsAfter, error) {
+	const query = `
+	SELECT
+		MIN(id)
+	FROM
+		workspace_agent_logs
+	WHERE
+		agent_id = $1;
+	`
+
+	logs, err := q.GetWorkspaceAgentLogsAfter(ctx, arg)
+	if err != nil {
+		return WorkspaceAgentLogsAfter{}, err
+	}
+	var oldest sql.NullInt64
+	err = q.db.GetContext(ctx, &oldest, query, arg.AgentID)
+	if err != nil {
+		return WorkspaceAgentLogsAfter{}, xerrors.Errorf("get oldest log id: %w", err)
+	}
+	return WorkspaceAgentLogsAfter{
+		Logs: logs,
+		Gap:  arg.CreatedAfter > 0 && (!oldest.Valid || oldest.Int64 > arg.CreatedAfter),
+	}, nil
+}
+
 func insertAuthorizedFilter(query string, replaceWith string) (string, error) {
 	if !strings.Contains(query, authorizedQueryPlaceholder) {
 		return "", xerrors.Errorf("query does not contain authorized replace string, this is not an authorized query")
@@ -1570,6 +1570,8 @@ type ProvisionerDaemon struct {
 	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
 	ReplicaID    uuid.NullUUID     `db:"replica_id" json:"replica_id"`
 	Tags         StringMap         `db:"tags" json:"tags"`
+	// The last time the daemon polled for a job. Used to detect daemons that are no longer running.
+	LastSeenAt sql.NullTime `db:"last_seen_at" json:"last_seen_at"`
 }
 
 type ProvisionerJob struct {
@@ -1670,6 +1672,8 @@ type Template struct {
 	LockedTTL                    int64           `db:"locked_ttl" json:"locked_ttl"`
 	RestartRequirementDaysOfWeek int16           `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
 	RestartRequirementWeeks      int64           `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+	ProvisionerTags              StringMap       `db:"provisioner_tags" json:"provisioner_tags"`
+	AutobuildPaused              bool            `db:"autobuild_paused" json:"autobuild_paused"`
 	CreatedByAvatarURL           sql.NullString  `db:"created_by_avatar_url" json:"created_by_avatar_url"`
 	CreatedByUsername            string          `db:"created_by_username" json:"created_by_username"`
 }
@@ -1706,6 +1710,10 @@ type TemplateTable struct {
 	RestartRequirementDaysOfWeek int16 `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
 	// The number of weeks between restarts. 0 or 1 weeks means "every week", 2 week means "every second week", etc. Weeks are counted from January 2, 2023, which is the first Monday of 2023. This is to ensure workspaces are started consistently for all customers on the same n-week cycles.
 	RestartRequirementWeeks int64 `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+	// Provisioner tags that jobs for builds of this template must be acquired with, in addition to the tags of the job.
+	ProvisionerTags StringMap `db:"provisioner_tags" json:"provisioner_tags"`
+	// Pauses autostart and autostop for all workspaces of this template, e.g. during maintenance.
+	AutobuildPaused bool `db:"autobuild_paused" json:"autobuild_paused"`
 }
 
 // Joins in the username + avatar url of the created by user.
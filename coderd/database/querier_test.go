@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
 	"github.com/coder/coder/coderd/database/dbgen"
 	"github.com/coder/coder/coderd/database/migrations"
 	"github.com/coder/coder/testutil"
@@ -51,6 +52,7 @@ func TestGetDeploymentWorkspaceAgentStats(t *testing.T) {
 		require.Equal(t, 1.5, stats.WorkspaceConnectionLatency50)
 		require.Equal(t, 1.95, stats.WorkspaceConnectionLatency95)
 		require.Equal(t, int64(2), stats.SessionCountVSCode)
+		require.True(t, stats.HasData)
 	})
 
 	t.Run("GroupsByAgentID", func(t *testing.T) {
@@ -89,6 +91,22 @@ func TestGetDeploymentWorkspaceAgentStats(t *testing.T) {
 		require.Equal(t, 1.95, stats.WorkspaceConnectionLatency95)
 		require.Equal(t, int64(1), stats.SessionCountVSCode)
 	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		sqlDB := testSQLDB(t)
+		err := migrations.Up(sqlDB)
+		require.NoError(t, err)
+		db := database.New(sqlDB)
+		ctx := context.Background()
+		stats, err := db.GetDeploymentWorkspaceAgentStats(ctx, database.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		require.False(t, stats.HasData)
+		require.Equal(t, float64(-1), stats.WorkspaceConnectionLatency50)
+		require.Equal(t, float64(-1), stats.WorkspaceConnectionLatency95)
+	})
 }
 
 func TestInsertWorkspaceAgentLogs(t *testing.T) {
@@ -494,3 +512,46 @@ func requireUsersMatch(t testing.TB, expected []database.User, found []database.
 	t.Helper()
 	require.ElementsMatch(t, expected, database.ConvertUserRows(found), msg)
 }
+
+func TestGetWorkspaceProxiesSearch(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.SkipNow()
+	}
+	sqlDB := testSQLDB(t)
+	err := migrations.Up(sqlDB)
+	require.NoError(t, err)
+
+	// The search must behave the same in Postgres and the fake, including
+	// for characters that are wildcards in LIKE patterns.
+	for name, db := range map[string]database.Store{
+		"Postgres": database.New(sqlDB),
+		"Fake":     dbfake.New(),
+	} {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testutil.Context(t, testutil.WaitShort)
+			underscore, _ := dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{DisplayName: "Sydney_West"})
+			percent, _ := dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{DisplayName: "100% Frankfurt"})
+			_, _ = dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{DisplayName: "Paris"})
+
+			for _, tc := range []struct {
+				search string
+				want   []uuid.UUID
+			}{
+				{search: "_", want: []uuid.UUID{underscore.ID}},
+				{search: "%", want: []uuid.UUID{percent.ID}},
+				{search: "SYDNEY", want: []uuid.UUID{underscore.ID}},
+			} {
+				proxies, err := db.GetWorkspaceProxies(ctx, tc.search)
+				require.NoError(t, err)
+				got := make([]uuid.UUID, 0, len(proxies))
+				for _, p := range proxies {
+					got = append(got, p.ID)
+				}
+				require.Equal(t, tc.want, got, "search %q", tc.search)
+			}
+		})
+	}
+}
@@ -695,6 +695,9 @@ func (q *sqlQuerier) GetFileByID(ctx context.Context, id uuid.UUID) (File, error
 
 const getFileTemplates = `-- name: GetFileTemplates :many
 SELECT
+	-- A template may have several versions built from the same file, only
+	-- return each template once.
+	DISTINCT ON (templates.id)
 	files.id AS file_id,
 	files.created_by AS file_created_by,
 	templates.id AS template_id,
@@ -1028,6 +1031,19 @@ func (q *sqlQuerier) UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyPar
 	return i, err
 }
 
+const deleteAllGroupMembersByUserID = `-- name: DeleteAllGroupMembersByUserID :exec
+DELETE FROM
+	group_members
+WHERE
+	user_id = $1
+`
+
+// Removes the user from every group in every organization.
+func (q *sqlQuerier) DeleteAllGroupMembersByUserID(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteAllGroupMembersByUserID, userID)
+	return err
+}
+
 const deleteGroupMemberFromGroup = `-- name: DeleteGroupMemberFromGroup :exec
 DELETE FROM
 	group_members
@@ -1211,18 +1227,24 @@ FROM
 WHERE
 	organization_id = $1
 AND
-	name = $2
+	CASE
+		WHEN $2 :: boolean THEN
+			lower(name) = lower($3)
+		ELSE
+			name = $3
+	END
 LIMIT
 	1
 `
 
 type GetGroupByOrgAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Name           string    `db:"name" json:"name"`
+	OrganizationID  uuid.UUID `db:"organization_id" json:"organization_id"`
+	CaseInsensitive bool      `db:"case_insensitive" json:"case_insensitive"`
+	Name            string    `db:"name" json:"name"`
 }
 
 func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.Name)
+	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.CaseInsensitive, arg.Name)
 	var i Group
 	err := row.Scan(
 		&i.ID,
@@ -1243,11 +1265,18 @@ FROM
 WHERE
 	organization_id = $1
 AND
-	id != $1
+	-- The allUsers group shares its ID with the organization and is omitted
+	-- unless explicitly requested.
+	($2 :: boolean OR id != $1)
 `
 
-func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error) {
-	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, organizationID)
+type GetGroupsByOrganizationIDParams struct {
+	OrganizationID  uuid.UUID `db:"organization_id" json:"organization_id"`
+	IncludeEveryone bool      `db:"include_everyone" json:"include_everyone"`
+}
+
+func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, arg GetGroupsByOrganizationIDParams) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, arg.OrganizationID, arg.IncludeEveryone)
 	if err != nil {
 		return nil, err
 	}
@@ -1510,37 +1539,60 @@ WITH d AS (
 	SELECT array_agg(DISTINCT template_id) AS ids
 	FROM usage_by_user, unnest(template_ids) template_id
 	WHERE template_id IS NOT NULL
+), sessions AS (
+	-- Coalesce contiguous 5 minute intervals of a user into sessions.
+	-- Subtracting the row number from the interval start yields the same
+	-- value for every interval within a contiguous run.
+	SELECT
+		user_id,
+		COUNT(*) * EXTRACT(epoch FROM '5 minute'::interval) AS duration_seconds
+	FROM (
+		SELECT
+			user_id,
+			from_ - ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY from_) * '5 minute'::interval AS session_id
+		FROM usage_by_user
+	) AS user_intervals
+	GROUP BY user_id, session_id
+), active_users AS (
+	-- Only users whose total usage meets the threshold count as active.
+	SELECT user_id
+	FROM usage_by_user
+	GROUP BY user_id
+	HAVING COUNT(*) * EXTRACT(epoch FROM '5 minute'::interval) >= $4::bigint
 )
 
 SELECT
 	COALESCE((SELECT ids FROM template_ids), '{}')::uuid[] AS template_ids,
-	COUNT(DISTINCT user_id) AS active_users,
+	(SELECT COUNT(*) FROM active_users)::bigint AS active_users,
 	COALESCE(SUM(usage_vscode_seconds), 0)::bigint AS usage_vscode_seconds,
 	COALESCE(SUM(usage_jetbrains_seconds), 0)::bigint AS usage_jetbrains_seconds,
 	COALESCE(SUM(usage_reconnecting_pty_seconds), 0)::bigint AS usage_reconnecting_pty_seconds,
-	COALESCE(SUM(usage_ssh_seconds), 0)::bigint AS usage_ssh_seconds
+	COALESCE(SUM(usage_ssh_seconds), 0)::bigint AS usage_ssh_seconds,
+	COALESCE((SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_seconds) FROM sessions), 0)::bigint AS median_session_duration_seconds
 FROM usage_by_user
 `
 
 type GetTemplateInsightsParams struct {
-	StartTime   time.Time   `db:"start_time" json:"start_time"`
-	EndTime     time.Time   `db:"end_time" json:"end_time"`
-	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
+	StartTime        time.Time   `db:"start_time" json:"start_time"`
+	EndTime          time.Time   `db:"end_time" json:"end_time"`
+	TemplateIDs      []uuid.UUID `db:"template_ids" json:"template_ids"`
+	MinActiveSeconds int64       `db:"min_active_seconds" json:"min_active_seconds"`
 }
 
 type GetTemplateInsightsRow struct {
-	TemplateIDs                 []uuid.UUID `db:"template_ids" json:"template_ids"`
-	ActiveUsers                 int64       `db:"active_users" json:"active_users"`
-	UsageVscodeSeconds          int64       `db:"usage_vscode_seconds" json:"usage_vscode_seconds"`
-	UsageJetbrainsSeconds       int64       `db:"usage_jetbrains_seconds" json:"usage_jetbrains_seconds"`
-	UsageReconnectingPtySeconds int64       `db:"usage_reconnecting_pty_seconds" json:"usage_reconnecting_pty_seconds"`
-	UsageSshSeconds             int64       `db:"usage_ssh_seconds" json:"usage_ssh_seconds"`
+	TemplateIDs                  []uuid.UUID `db:"template_ids" json:"template_ids"`
+	ActiveUsers                  int64       `db:"active_users" json:"active_users"`
+	UsageVscodeSeconds           int64       `db:"usage_vscode_seconds" json:"usage_vscode_seconds"`
+	UsageJetbrainsSeconds        int64       `db:"usage_jetbrains_seconds" json:"usage_jetbrains_seconds"`
+	UsageReconnectingPtySeconds  int64       `db:"usage_reconnecting_pty_seconds" json:"usage_reconnecting_pty_seconds"`
+	UsageSshSeconds              int64       `db:"usage_ssh_seconds" json:"usage_ssh_seconds"`
+	MedianSessionDurationSeconds int64       `db:"median_session_duration_seconds" json:"median_session_duration_seconds"`
 }
 
 // GetTemplateInsights has a granularity of 5 minutes where if a session/app was
 // in use, we will add 5 minutes to the total usage for that session (per user).
 func (q *sqlQuerier) GetTemplateInsights(ctx context.Context, arg GetTemplateInsightsParams) (GetTemplateInsightsRow, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs))
+	row := q.db.QueryRowContext(ctx, getTemplateInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs), arg.MinActiveSeconds)
 	var i GetTemplateInsightsRow
 	err := row.Scan(
 		pq.Array(&i.TemplateIDs),
@@ -1549,6 +1601,7 @@ func (q *sqlQuerier) GetTemplateInsights(ctx context.Context, arg GetTemplateIns
 		&i.UsageJetbrainsSeconds,
 		&i.UsageReconnectingPtySeconds,
 		&i.UsageSshSeconds,
+		&i.MedianSessionDurationSeconds,
 	)
 	return i, err
 }
@@ -1662,40 +1715,49 @@ SELECT
 	users.avatar_url,
 	array_agg(DISTINCT template_id)::uuid[] AS template_ids,
 	coalesce((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_50,
-	coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95
+	coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95,
+	coalesce((PERCENTILE_CONT($1::float8[]) WITHIN GROUP (ORDER BY connection_median_latency_ms)), '{}')::float8[] AS workspace_connection_latency_percentiles
 FROM workspace_agent_stats
 JOIN users ON (users.id = workspace_agent_stats.user_id)
 WHERE
-	workspace_agent_stats.created_at >= $1
-	AND workspace_agent_stats.created_at < $2
+	workspace_agent_stats.created_at >= $2
+	AND workspace_agent_stats.created_at < $3
 	AND workspace_agent_stats.connection_median_latency_ms > 0
 	AND workspace_agent_stats.connection_count > 0
-	AND CASE WHEN COALESCE(array_length($3::uuid[], 1), 0) > 0 THEN template_id = ANY($3::uuid[]) ELSE TRUE END
+	AND CASE WHEN COALESCE(array_length($4::uuid[], 1), 0) > 0 THEN template_id = ANY($4::uuid[]) ELSE TRUE END
 GROUP BY workspace_agent_stats.user_id, users.username, users.avatar_url
 ORDER BY user_id ASC
 `
 
 type GetUserLatencyInsightsParams struct {
+	Percentiles []float64   `db:"percentiles" json:"percentiles"`
 	StartTime   time.Time   `db:"start_time" json:"start_time"`
 	EndTime     time.Time   `db:"end_time" json:"end_time"`
 	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
 }
 
 type GetUserLatencyInsightsRow struct {
-	UserID                       uuid.UUID      `db:"user_id" json:"user_id"`
-	Username                     string         `db:"username" json:"username"`
-	AvatarURL                    sql.NullString `db:"avatar_url" json:"avatar_url"`
-	TemplateIDs                  []uuid.UUID    `db:"template_ids" json:"template_ids"`
-	WorkspaceConnectionLatency50 float64        `db:"workspace_connection_latency_50" json:"workspace_connection_latency_50"`
-	WorkspaceConnectionLatency95 float64        `db:"workspace_connection_latency_95" json:"workspace_connection_latency_95"`
+	UserID                                uuid.UUID      `db:"user_id" json:"user_id"`
+	Username                              string         `db:"username" json:"username"`
+	AvatarURL                             sql.NullString `db:"avatar_url" json:"avatar_url"`
+	TemplateIDs                           []uuid.UUID    `db:"template_ids" json:"template_ids"`
+	WorkspaceConnectionLatency50          float64        `db:"workspace_connection_latency_50" json:"workspace_connection_latency_50"`
+	WorkspaceConnectionLatency95          float64        `db:"workspace_connection_latency_95" json:"workspace_connection_latency_95"`
+	WorkspaceConnectionLatencyPercentiles []float64      `db:"workspace_connection_latency_percentiles" json:"workspace_connection_latency_percentiles"`
 }
 
 // GetUserLatencyInsights returns the median and 95th percentile connection
 // latency that users have experienced. The result can be filtered on
 // template_ids, meaning only user data from workspaces based on those templates
-// will be included.
+// will be included. Additional percentiles (fractions between 0 and 1) can be
+// requested with percentiles and are returned in the same order.
 func (q *sqlQuerier) GetUserLatencyInsights(ctx context.Context, arg GetUserLatencyInsightsParams) ([]GetUserLatencyInsightsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getUserLatencyInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs))
+	rows, err := q.db.QueryContext(ctx, getUserLatencyInsights,
+		pq.Array(arg.Percentiles),
+		arg.StartTime,
+		arg.EndTime,
+		pq.Array(arg.TemplateIDs),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1710,6 +1772,7 @@ func (q *sqlQuerier) GetUserLatencyInsights(ctx context.Context, arg GetUserLate
 			pq.Array(&i.TemplateIDs),
 			&i.WorkspaceConnectionLatency50,
 			&i.WorkspaceConnectionLatency95,
+			pq.Array(&i.WorkspaceConnectionLatencyPercentiles),
 		); err != nil {
 			return nil, err
 		}
@@ -2290,7 +2353,7 @@ func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.
 
 const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
 SELECT
-	id, created_at, updated_at, name, provisioners, replica_id, tags
+	id, created_at, updated_at, name, provisioners, replica_id, tags, last_seen_at
 FROM
 	provisioner_daemons
 `
@@ -2312,6 +2375,7 @@ func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDa
 			pq.Array(&i.Provisioners),
 			&i.ReplicaID,
 			&i.Tags,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -2333,10 +2397,11 @@ INSERT INTO
 		created_at,
 		"name",
 		provisioners,
-		tags
+		tags,
+		last_seen_at
 	)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags
+	($1, $2, $3, $4, $5, $2) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags, last_seen_at
 `
 
 type InsertProvisionerDaemonParams struct {
@@ -2364,10 +2429,31 @@ func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProv
 		pq.Array(&i.Provisioners),
 		&i.ReplicaID,
 		&i.Tags,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
+const updateProvisionerDaemonLastSeenAt = `-- name: UpdateProvisionerDaemonLastSeenAt :exec
+UPDATE provisioner_daemons
+SET
+	last_seen_at = $1
+WHERE
+	id = $2
+	-- Never move last_seen_at backwards.
+	AND (last_seen_at IS NULL OR last_seen_at <= $1)
+`
+
+type UpdateProvisionerDaemonLastSeenAtParams struct {
+	LastSeenAt sql.NullTime `db:"last_seen_at" json:"last_seen_at"`
+	ID         uuid.UUID    `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerDaemonLastSeenAt(ctx context.Context, arg UpdateProvisionerDaemonLastSeenAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerDaemonLastSeenAt, arg.LastSeenAt, arg.ID)
+	return err
+}
+
 const getProvisionerLogsAfterID = `-- name: GetProvisionerLogsAfterID :many
 SELECT
 	job_id, created_at, source, level, stage, output, id
@@ -2378,15 +2464,19 @@ WHERE
 	AND (
 		id > $2
 	) ORDER BY id ASC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($3 :: int, 0)
 `
 
 type GetProvisionerLogsAfterIDParams struct {
 	JobID        uuid.UUID `db:"job_id" json:"job_id"`
 	CreatedAfter int64     `db:"created_after" json:"created_after"`
+	Limit        int32     `db:"limit_" json:"limit_"`
 }
 
 func (q *sqlQuerier) GetProvisionerLogsAfterID(ctx context.Context, arg GetProvisionerLogsAfterIDParams) ([]ProvisionerJobLog, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerLogsAfterID, arg.JobID, arg.CreatedAfter)
+	rows, err := q.db.QueryContext(ctx, getProvisionerLogsAfterID, arg.JobID, arg.CreatedAfter, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -2494,6 +2584,21 @@ WHERE
 			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
 			-- Ensure the caller satisfies all job tags.
 			AND nested.tags <@ $4 :: jsonb
+			-- Ensure the caller satisfies all tags required by the template
+			-- of the workspace being built, if any.
+			AND COALESCE((
+				SELECT
+					templates.provisioner_tags
+				FROM
+					workspace_builds
+				JOIN
+					template_versions ON template_versions.id = workspace_builds.template_version_id
+				JOIN
+					templates ON templates.id = template_versions.template_id
+				WHERE
+					workspace_builds.job_id = nested.id
+					AND jsonb_typeof(templates.provisioner_tags) = 'object'
+			), '{}' :: jsonb) <@ $4 :: jsonb
 		ORDER BY
 			nested.created_at
 		FOR UPDATE
@@ -2547,6 +2652,48 @@ func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvi
 	return i, err
 }
 
+const cancelPendingProvisionerJobs = `-- name: CancelPendingProvisionerJobs :execrows
+UPDATE
+	provisioner_jobs
+SET
+	canceled_at = $1 :: timestamptz,
+	completed_at = $1 :: timestamptz,
+	updated_at = $1 :: timestamptz
+WHERE
+	started_at IS NULL
+	AND canceled_at IS NULL
+`
+
+// Cancels all jobs that have not yet been acquired by a provisioner. This is
+// used when draining a deployment.
+func (q *sqlQuerier) CancelPendingProvisionerJobs(ctx context.Context, canceledAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelPendingProvisionerJobs, canceledAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countRunningProvisionerJobs = `-- name: CountRunningProvisionerJobs :one
+SELECT
+	COUNT(*)
+FROM
+	provisioner_jobs
+WHERE
+	started_at IS NOT NULL
+	AND completed_at IS NULL
+	AND canceled_at IS NULL
+`
+
+// Counts jobs that have been acquired by a provisioner but have not yet
+// completed or been canceled.
+func (q *sqlQuerier) CountRunningProvisionerJobs(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRunningProvisionerJobs)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getHungProvisionerJobs = `-- name: GetHungProvisionerJobs :many
 SELECT
 	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
@@ -2689,32 +2836,30 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 const getProvisionerJobsByIDsWithQueuePosition = `-- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, provisioner, tags
     FROM
         provisioner_jobs
     WHERE
         started_at IS NULL
 ),
+-- Jobs only compete with other jobs that can be acquired by the same
+-- daemons, so each provisioner type and tag set has its own queue.
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (PARTITION BY provisioner, tags ORDER BY created_at ASC) AS queue_position,
+        COUNT(*) OVER (PARTITION BY provisioner, tags) AS queue_size
     FROM
         unstarted_jobs
-),
-queue_size AS (
-	SELECT COUNT(*) as count FROM unstarted_jobs
 )
 SELECT
 	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata,
     COALESCE(qp.queue_position, 0) AS queue_position,
-    COALESCE(qs.count, 0) AS queue_size
+    COALESCE(qp.queue_size, 0) AS queue_size
 FROM
 	provisioner_jobs pj
 LEFT JOIN
 	queue_position qp ON qp.id = pj.id
-LEFT JOIN
-	queue_size qs ON TRUE
 WHERE
 	pj.id = ANY($1 :: uuid [ ])
 `
@@ -2770,11 +2915,49 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 }
 
 const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
-SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata FROM provisioner_jobs WHERE created_at > $1
+SELECT
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+FROM
+	provisioner_jobs
+WHERE
+	created_at > $1
+	-- Optionally filter by job type.
+	AND CASE
+		WHEN cardinality($2 :: provisioner_job_type[]) > 0 THEN
+			type = ANY($2 :: provisioner_job_type[])
+		ELSE true
+	END
+	-- Optionally filter by job status. The status values match
+	-- codersdk.ProvisionerJobStatus.
+	AND CASE
+		WHEN $3 :: text != '' THEN
+			(CASE
+				WHEN canceled_at IS NOT NULL THEN
+					CASE
+						WHEN completed_at IS NULL THEN 'canceling'
+						WHEN COALESCE(error, '') = '' THEN 'canceled'
+						ELSE 'failed'
+					END
+				WHEN started_at IS NULL THEN 'pending'
+				WHEN completed_at IS NOT NULL THEN
+					CASE
+						WHEN COALESCE(error, '') = '' THEN 'succeeded'
+						ELSE 'failed'
+					END
+				ELSE 'running'
+			END) = $3
+		ELSE true
+	END
 `
 
-func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, createdAt)
+type GetProvisionerJobsCreatedAfterParams struct {
+	CreatedAt time.Time            `db:"created_at" json:"created_at"`
+	Types     []ProvisionerJobType `db:"types" json:"types"`
+	Status    string               `db:"status" json:"status"`
+}
+
+func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, arg GetProvisionerJobsCreatedAfterParams) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, arg.CreatedAt, pq.Array(arg.Types), arg.Status)
 	if err != nil {
 		return nil, err
 	}
@@ -2967,10 +3150,19 @@ FROM
 	workspace_proxies
 WHERE
 	deleted = false
+	-- Filter by display name
+	AND CASE
+		WHEN $1 :: text != '' THEN
+			-- Match literally so wildcards in the search aren't interpreted.
+			position(lower($1 :: text) in lower(display_name)) > 0
+		ELSE true
+	END
+ORDER BY
+	name ASC
 `
 
-func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceProxies)
+func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context, search string) ([]WorkspaceProxy, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceProxies, search)
 	if err != nil {
 		return nil, err
 	}
@@ -3136,6 +3328,39 @@ func (q *sqlQuerier) GetWorkspaceProxyByName(ctx context.Context, name string) (
 	return i, err
 }
 
+const getWorkspaceProxyByTokenHash = `-- name: GetWorkspaceProxyByTokenHash :one
+SELECT
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+FROM
+	workspace_proxies
+WHERE
+	token_hashed_secret = $1
+	AND deleted = false
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetWorkspaceProxyByTokenHash(ctx context.Context, hash []byte) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceProxyByTokenHash, hash)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+		&i.RegionID,
+		&i.DerpEnabled,
+		&i.DerpOnly,
+	)
+	return i, err
+}
+
 const insertWorkspaceProxy = `-- name: InsertWorkspaceProxy :one
 INSERT INTO
 	workspace_proxies (
@@ -3349,6 +3574,7 @@ SELECT
 	DISTINCT ON
 	(workspace_id) id,
 	workspace_id,
+	transition,
 	daily_cost
 FROM
 	workspace_builds wb
@@ -3362,16 +3588,92 @@ FROM
 	workspaces
 JOIN latest_builds ON
 	latest_builds.workspace_id = workspaces.id
-WHERE NOT deleted AND workspaces.owner_id = $1
+WHERE NOT deleted
+	AND workspaces.owner_id = $1
+	AND CASE WHEN $2 :: boolean THEN latest_builds.transition = 'start' ELSE true END
 `
 
-func (q *sqlQuerier) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getQuotaConsumedForUser, ownerID)
+type GetQuotaConsumedForUserParams struct {
+	OwnerID     uuid.UUID `db:"owner_id" json:"owner_id"`
+	OnlyRunning bool      `db:"only_running" json:"only_running"`
+}
+
+// GetQuotaConsumedForUser sums the daily cost of the latest build of each of
+// the user's workspaces. When only_running is set, workspaces whose latest
+// build is not a start are excluded so stopped workspaces consume no quota.
+func (q *sqlQuerier) GetQuotaConsumedForUser(ctx context.Context, arg GetQuotaConsumedForUserParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getQuotaConsumedForUser, arg.OwnerID, arg.OnlyRunning)
 	var column_1 int64
 	err := row.Scan(&column_1)
 	return column_1, err
 }
 
+const getQuotaConsumedForUserIncludingPending = `-- name: GetQuotaConsumedForUserIncludingPending :one
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	transition,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+),
+consumed AS (
+SELECT
+	coalesce(SUM(daily_cost), 0)::BIGINT AS amount
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+WHERE NOT deleted
+	AND workspaces.owner_id = $1
+	AND CASE WHEN $2 :: boolean THEN latest_builds.transition = 'start' ELSE true END
+),
+allowance AS (
+SELECT
+	coalesce(SUM(quota_allowance), 0)::BIGINT AS amount
+FROM
+	group_members gm
+JOIN groups g ON
+	g.id = gm.group_id
+WHERE
+	gm.user_id = $1
+)
+SELECT
+	(consumed.amount + $3 :: integer)::BIGINT AS consumed,
+	allowance.amount AS allowance,
+	(consumed.amount + $3 :: integer) > allowance.amount AS exceeds_allowance
+FROM
+	consumed, allowance
+`
+
+type GetQuotaConsumedForUserIncludingPendingParams struct {
+	OwnerID          uuid.UUID `db:"owner_id" json:"owner_id"`
+	OnlyRunning      bool      `db:"only_running" json:"only_running"`
+	PendingDailyCost int32     `db:"pending_daily_cost" json:"pending_daily_cost"`
+}
+
+type GetQuotaConsumedForUserIncludingPendingRow struct {
+	Consumed         int64 `db:"consumed" json:"consumed"`
+	Allowance        int64 `db:"allowance" json:"allowance"`
+	ExceedsAllowance bool  `db:"exceeds_allowance" json:"exceeds_allowance"`
+}
+
+// GetQuotaConsumedForUserIncludingPending returns the quota a user would
+// consume if a pending build with the given daily cost was committed, and
+// whether that would exceed the user's allowance. only_running behaves as in
+// GetQuotaConsumedForUser.
+func (q *sqlQuerier) GetQuotaConsumedForUserIncludingPending(ctx context.Context, arg GetQuotaConsumedForUserIncludingPendingParams) (GetQuotaConsumedForUserIncludingPendingRow, error) {
+	row := q.db.QueryRowContext(ctx, getQuotaConsumedForUserIncludingPending, arg.OwnerID, arg.OnlyRunning, arg.PendingDailyCost)
+	var i GetQuotaConsumedForUserIncludingPendingRow
+	err := row.Scan(&i.Consumed, &i.Allowance, &i.ExceedsAllowance)
+	return i, err
+}
+
 const deleteReplicasUpdatedBefore = `-- name: DeleteReplicasUpdatedBefore :exec
 DELETE FROM replicas WHERE updated_at < $1
 `
@@ -4063,6 +4365,66 @@ func (q *sqlQuerier) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID)
 	return i, err
 }
 
+const getDeletedTemplates = `-- name: GetDeletedTemplates :many
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, provisioner_tags, autobuild_paused, created_by_avatar_url, created_by_username FROM template_with_users AS templates
+WHERE deleted = true
+ORDER BY updated_at DESC, id ASC
+`
+
+// GetDeletedTemplates returns soft-deleted templates, most recently deleted
+// first.
+func (q *sqlQuerier) GetDeletedTemplates(ctx context.Context) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Template
+	for rows.Next() {
+		var i Template
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganizationID,
+			&i.Deleted,
+			&i.Name,
+			&i.Provisioner,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.DefaultTTL,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.DisplayName,
+			&i.AllowUserCancelWorkspaceJobs,
+			&i.MaxTTL,
+			&i.AllowUserAutostart,
+			&i.AllowUserAutostop,
+			&i.FailureTTL,
+			&i.InactivityTTL,
+			&i.LockedTTL,
+			&i.RestartRequirementDaysOfWeek,
+			&i.RestartRequirementWeeks,
+			&i.ProvisionerTags,
+			&i.AutobuildPaused,
+			&i.CreatedByAvatarURL,
+			&i.CreatedByUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTemplateAverageBuildTime = `-- name: GetTemplateAverageBuildTime :one
 WITH build_times AS (
 SELECT
@@ -4091,7 +4453,11 @@ SELECT
 	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_50,
 	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_95,
 	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_95,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95,
+	-- The number of builds each percentile is computed from.
+	COUNT(*) FILTER (WHERE transition = 'start') AS start_count,
+	COUNT(*) FILTER (WHERE transition = 'stop') AS stop_count,
+	COUNT(*) FILTER (WHERE transition = 'delete') AS delete_count
 FROM build_times
 `
 
@@ -4101,12 +4467,15 @@ type GetTemplateAverageBuildTimeParams struct {
 }
 
 type GetTemplateAverageBuildTimeRow struct {
-	Start50  float64 `db:"start_50" json:"start_50"`
-	Stop50   float64 `db:"stop_50" json:"stop_50"`
-	Delete50 float64 `db:"delete_50" json:"delete_50"`
-	Start95  float64 `db:"start_95" json:"start_95"`
-	Stop95   float64 `db:"stop_95" json:"stop_95"`
-	Delete95 float64 `db:"delete_95" json:"delete_95"`
+	Start50     float64 `db:"start_50" json:"start_50"`
+	Stop50      float64 `db:"stop_50" json:"stop_50"`
+	Delete50    float64 `db:"delete_50" json:"delete_50"`
+	Start95     float64 `db:"start_95" json:"start_95"`
+	Stop95      float64 `db:"stop_95" json:"stop_95"`
+	Delete95    float64 `db:"delete_95" json:"delete_95"`
+	StartCount  int64   `db:"start_count" json:"start_count"`
+	StopCount   int64   `db:"stop_count" json:"stop_count"`
+	DeleteCount int64   `db:"delete_count" json:"delete_count"`
 }
 
 func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTemplateAverageBuildTimeParams) (GetTemplateAverageBuildTimeRow, error) {
@@ -4119,13 +4488,16 @@ func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTem
 		&i.Start95,
 		&i.Stop95,
 		&i.Delete95,
+		&i.StartCount,
+		&i.StopCount,
+		&i.DeleteCount,
 	)
 	return i, err
 }
 
 const getTemplateByID = `-- name: GetTemplateByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, provisioner_tags, autobuild_paused, created_by_avatar_url, created_by_username
 FROM
 	template_with_users
 WHERE
@@ -4162,6 +4534,8 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.LockedTTL,
 		&i.RestartRequirementDaysOfWeek,
 		&i.RestartRequirementWeeks,
+		&i.ProvisionerTags,
+		&i.AutobuildPaused,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 	)
@@ -4170,7 +4544,7 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 
 const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, provisioner_tags, autobuild_paused, created_by_avatar_url, created_by_username
 FROM
 	template_with_users AS templates
 WHERE
@@ -4215,6 +4589,8 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 		&i.LockedTTL,
 		&i.RestartRequirementDaysOfWeek,
 		&i.RestartRequirementWeeks,
+		&i.ProvisionerTags,
+		&i.AutobuildPaused,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 	)
@@ -4222,7 +4598,7 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 }
 
 const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username FROM template_with_users AS templates
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, provisioner_tags, autobuild_paused, created_by_avatar_url, created_by_username FROM template_with_users AS templates
 ORDER BY (name, id) ASC
 `
 
@@ -4260,6 +4636,8 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 			&i.LockedTTL,
 			&i.RestartRequirementDaysOfWeek,
 			&i.RestartRequirementWeeks,
+			&i.ProvisionerTags,
+			&i.AutobuildPaused,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -4278,7 +4656,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 
 const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, provisioner_tags, autobuild_paused, created_by_avatar_url, created_by_username
 FROM
 	template_with_users AS templates
 WHERE
@@ -4353,6 +4731,8 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 			&i.LockedTTL,
 			&i.RestartRequirementDaysOfWeek,
 			&i.RestartRequirementWeeks,
+			&i.ProvisionerTags,
+			&i.AutobuildPaused,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -4385,10 +4765,11 @@ INSERT INTO
 		user_acl,
 		group_acl,
 		display_name,
-		allow_user_cancel_workspace_jobs
+		allow_user_cancel_workspace_jobs,
+		provisioner_tags
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 `
 
 type InsertTemplateParams struct {
@@ -4406,6 +4787,7 @@ type InsertTemplateParams struct {
 	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
 	DisplayName                  string          `db:"display_name" json:"display_name"`
 	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+	ProvisionerTags              StringMap       `db:"provisioner_tags" json:"provisioner_tags"`
 }
 
 func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) error {
@@ -4424,6 +4806,7 @@ func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParam
 		arg.GroupACL,
 		arg.DisplayName,
 		arg.AllowUserCancelWorkspaceJobs,
+		arg.ProvisionerTags,
 	)
 	return err
 }
@@ -4541,7 +4924,8 @@ SET
 	restart_requirement_weeks = $8,
 	failure_ttl = $9,
 	inactivity_ttl = $10,
-	locked_ttl = $11
+	locked_ttl = $11,
+	autobuild_paused = $12
 WHERE
 	id = $1
 `
@@ -4558,6 +4942,7 @@ type UpdateTemplateScheduleByIDParams struct {
 	FailureTTL                   int64     `db:"failure_ttl" json:"failure_ttl"`
 	InactivityTTL                int64     `db:"inactivity_ttl" json:"inactivity_ttl"`
 	LockedTTL                    int64     `db:"locked_ttl" json:"locked_ttl"`
+	AutobuildPaused              bool      `db:"autobuild_paused" json:"autobuild_paused"`
 }
 
 func (q *sqlQuerier) UpdateTemplateScheduleByID(ctx context.Context, arg UpdateTemplateScheduleByIDParams) error {
@@ -4573,6 +4958,7 @@ func (q *sqlQuerier) UpdateTemplateScheduleByID(ctx context.Context, arg UpdateT
 		arg.FailureTTL,
 		arg.InactivityTTL,
 		arg.LockedTTL,
+		arg.AutobuildPaused,
 	)
 	return err
 }
@@ -5039,6 +5425,78 @@ func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, create
 	return items, nil
 }
 
+const getTemplateVersionsWithStatusByIDs = `-- name: GetTemplateVersionsWithStatusByIDs :many
+SELECT
+	template_versions.id, template_versions.template_id, template_versions.organization_id, template_versions.created_at, template_versions.updated_at, template_versions.name, template_versions.readme, template_versions.job_id, template_versions.created_by, template_versions.git_auth_providers, template_versions.message, template_versions.created_by_avatar_url, template_versions.created_by_username,
+	(CASE
+		WHEN provisioner_jobs.canceled_at IS NOT NULL THEN
+			CASE
+				WHEN provisioner_jobs.completed_at IS NULL THEN 'canceling'
+				WHEN COALESCE(provisioner_jobs.error, '') = '' THEN 'canceled'
+				ELSE 'failed'
+			END
+		WHEN provisioner_jobs.started_at IS NULL THEN 'pending'
+		WHEN provisioner_jobs.completed_at IS NOT NULL THEN
+			CASE
+				WHEN COALESCE(provisioner_jobs.error, '') = '' THEN 'succeeded'
+				ELSE 'failed'
+			END
+		ELSE 'running'
+	END) :: text AS job_status
+FROM
+	template_version_with_user AS template_versions
+JOIN
+	provisioner_jobs ON provisioner_jobs.id = template_versions.job_id
+WHERE
+	template_versions.id = ANY($1 :: uuid [ ])
+`
+
+type GetTemplateVersionsWithStatusByIDsRow struct {
+	TemplateVersion TemplateVersion `db:"template_version" json:"template_version"`
+	JobStatus       string          `db:"job_status" json:"job_status"`
+}
+
+// Returns the template versions along with the status of their import job.
+// The job_status values match codersdk.ProvisionerJobStatus and must be kept
+// in sync with db2sdk.ProvisionerJobStatus.
+func (q *sqlQuerier) GetTemplateVersionsWithStatusByIDs(ctx context.Context, ids []uuid.UUID) ([]GetTemplateVersionsWithStatusByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionsWithStatusByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateVersionsWithStatusByIDsRow
+	for rows.Next() {
+		var i GetTemplateVersionsWithStatusByIDsRow
+		if err := rows.Scan(
+			&i.TemplateVersion.ID,
+			&i.TemplateVersion.TemplateID,
+			&i.TemplateVersion.OrganizationID,
+			&i.TemplateVersion.CreatedAt,
+			&i.TemplateVersion.UpdatedAt,
+			&i.TemplateVersion.Name,
+			&i.TemplateVersion.Readme,
+			&i.TemplateVersion.JobID,
+			&i.TemplateVersion.CreatedBy,
+			pq.Array(&i.TemplateVersion.GitAuthProviders),
+			&i.TemplateVersion.Message,
+			&i.TemplateVersion.CreatedByAvatarURL,
+			&i.TemplateVersion.CreatedByUsername,
+			&i.JobStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertTemplateVersion = `-- name: InsertTemplateVersion :exec
 INSERT INTO
 	template_versions (
@@ -5195,22 +5653,84 @@ func (q *sqlQuerier) GetTemplateVersionVariables(ctx context.Context, templateVe
 	return items, nil
 }
 
-const insertTemplateVersionVariable = `-- name: InsertTemplateVersionVariable :one
-INSERT INTO
-    template_version_variables (
-        template_version_id,
-        name,
-        description,
-        type,
-        value,
-        default_value,
-        required,
-        sensitive
-    )
-VALUES
-    (
-        $1,
-        $2,
+const getTemplateVersionVariablesRedacted = `-- name: GetTemplateVersionVariablesRedacted :many
+SELECT
+	template_version_id,
+	name,
+	description,
+	type,
+	(CASE WHEN sensitive THEN '' ELSE value END) :: text AS value,
+	(CASE WHEN sensitive THEN '' ELSE default_value END) :: text AS default_value,
+	required,
+	sensitive
+FROM
+	template_version_variables
+WHERE
+	template_version_id = $1
+`
+
+type GetTemplateVersionVariablesRedactedRow struct {
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+	Description       string    `db:"description" json:"description"`
+	Type              string    `db:"type" json:"type"`
+	Value             string    `db:"value" json:"value"`
+	DefaultValue      string    `db:"default_value" json:"default_value"`
+	Required          bool      `db:"required" json:"required"`
+	Sensitive         bool      `db:"sensitive" json:"sensitive"`
+}
+
+// GetTemplateVersionVariablesRedacted returns the variables of a template
+// version with the values of sensitive variables cleared, for contexts that
+// must not see them.
+func (q *sqlQuerier) GetTemplateVersionVariablesRedacted(ctx context.Context, templateVersionID uuid.UUID) ([]GetTemplateVersionVariablesRedactedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionVariablesRedacted, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateVersionVariablesRedactedRow
+	for rows.Next() {
+		var i GetTemplateVersionVariablesRedactedRow
+		if err := rows.Scan(
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Description,
+			&i.Type,
+			&i.Value,
+			&i.DefaultValue,
+			&i.Required,
+			&i.Sensitive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateVersionVariable = `-- name: InsertTemplateVersionVariable :one
+INSERT INTO
+    template_version_variables (
+        template_version_id,
+        name,
+        description,
+        type,
+        value,
+        default_value,
+        required,
+        sensitive
+    )
+VALUES
+    (
+        $1,
+        $2,
         $3,
         $4,
         $5,
@@ -5435,6 +5955,23 @@ func (q *sqlQuerier) GetActiveUserCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const getActiveUserCountSince = `-- name: GetActiveUserCountSince :one
+SELECT
+	COUNT(*)
+FROM
+	users
+WHERE
+	status = 'active'::user_status AND deleted = false
+	AND last_seen_at > $1 :: timestamptz
+`
+
+func (q *sqlQuerier) GetActiveUserCountSince(ctx context.Context, since time.Time) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getActiveUserCountSince, since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getAuthorizationUserRoles = `-- name: GetAuthorizationUserRoles :one
 SELECT
 	-- username is returned just to help for logging purposes
@@ -5498,6 +6035,86 @@ func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.
 	return i, err
 }
 
+const getAuthorizationUserRolesByIDs = `-- name: GetAuthorizationUserRolesByIDs :many
+SELECT
+	-- username is returned just to help for logging purposes
+	-- status is used to enforce 'suspended' users, as all roles are ignored
+	--	when suspended.
+	id, username, status,
+	-- All user roles, including their org roles.
+	array_cat(
+		-- All users are members
+		array_append(users.rbac_roles, 'member'),
+		(
+			SELECT
+				array_agg(org_roles)
+			FROM
+				organization_members,
+				-- All org_members get the org-member role for their orgs
+				unnest(
+					array_append(roles, 'organization-member:' || organization_members.organization_id::text)
+				) AS org_roles
+			WHERE
+				user_id = users.id
+		)
+	) :: text[] AS roles,
+	-- All groups the user is in.
+	(
+		SELECT
+			array_agg(
+				group_members.group_id :: text
+			)
+		FROM
+			group_members
+		WHERE
+			user_id = users.id
+	) :: text[] AS groups
+FROM
+	users
+WHERE
+	id = ANY($1 :: uuid [ ])
+`
+
+type GetAuthorizationUserRolesByIDsRow struct {
+	ID       uuid.UUID  `db:"id" json:"id"`
+	Username string     `db:"username" json:"username"`
+	Status   UserStatus `db:"status" json:"status"`
+	Roles    []string   `db:"roles" json:"roles"`
+	Groups   []string   `db:"groups" json:"groups"`
+}
+
+// GetAuthorizationUserRolesByIDs is a batch variant of
+// GetAuthorizationUserRoles, returning roles for every given user in a single
+// query.
+func (q *sqlQuerier) GetAuthorizationUserRolesByIDs(ctx context.Context, ids []uuid.UUID) ([]GetAuthorizationUserRolesByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAuthorizationUserRolesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAuthorizationUserRolesByIDsRow
+	for rows.Next() {
+		var i GetAuthorizationUserRolesByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Status,
+			pq.Array(&i.Roles),
+			pq.Array(&i.Groups),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
 SELECT
 	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule
@@ -5646,16 +6263,47 @@ WHERE
 			last_seen_at >= $6
 		ELSE true
 	END
+	-- Filter by created_at
+	AND CASE
+		WHEN $7 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at <= $7
+		ELSE true
+	END
+	AND CASE
+		WHEN $8 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at >= $8
+		ELSE true
+	END
+	-- Filter by login_type
+	AND CASE
+		WHEN cardinality($9 :: login_type[]) > 0 THEN
+			login_type = ANY($9 :: login_type[])
+		ELSE true
+	END
+	-- Filter by organization membership
+	AND CASE
+		WHEN $10 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			EXISTS (
+				SELECT
+					1
+				FROM
+					organization_members
+				WHERE
+					organization_members.user_id = users.id
+					AND organization_members.organization_id = $10
+			)
+		ELSE true
+	END
 	-- End of filters
 
 	-- Authorize Filter clause will be injected below in GetAuthorizedUsers
 	-- @authorize_filter
 ORDER BY
 	-- Deterministic and consistent ordering of all users. This is to ensure consistent pagination.
-	LOWER(username) ASC OFFSET $7
+	LOWER(username) ASC OFFSET $11
 LIMIT
 	-- A null limit means "no limit", so 0 means return all
-	NULLIF($8 :: int, 0)
+	NULLIF($12 :: int, 0)
 `
 
 type GetUsersParams struct {
@@ -5665,6 +6313,10 @@ type GetUsersParams struct {
 	RbacRole       []string     `db:"rbac_role" json:"rbac_role"`
 	LastSeenBefore time.Time    `db:"last_seen_before" json:"last_seen_before"`
 	LastSeenAfter  time.Time    `db:"last_seen_after" json:"last_seen_after"`
+	CreatedBefore  time.Time    `db:"created_before" json:"created_before"`
+	CreatedAfter   time.Time    `db:"created_after" json:"created_after"`
+	LoginType      []LoginType  `db:"login_type" json:"login_type"`
+	OrganizationID uuid.UUID    `db:"organization_id" json:"organization_id"`
 	OffsetOpt      int32        `db:"offset_opt" json:"offset_opt"`
 	LimitOpt       int32        `db:"limit_opt" json:"limit_opt"`
 }
@@ -5695,6 +6347,10 @@ func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUse
 		pq.Array(arg.RbacRole),
 		arg.LastSeenBefore,
 		arg.LastSeenAfter,
+		arg.CreatedBefore,
+		arg.CreatedAfter,
+		pq.Array(arg.LoginType),
+		arg.OrganizationID,
 		arg.OffsetOpt,
 		arg.LimitOpt,
 	)
@@ -6175,6 +6831,20 @@ FROM
 	workspace_agents
 WHERE
 	auth_token = $1
+	-- Agents of deleted workspaces must not be able to authenticate.
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_resources
+		INNER JOIN
+			workspace_builds ON workspace_builds.job_id = workspace_resources.job_id
+		INNER JOIN
+			workspaces ON workspaces.id = workspace_builds.workspace_id
+		WHERE
+			workspace_resources.id = workspace_agents.resource_id
+			AND workspaces.deleted
+	)
 ORDER BY
 	created_at DESC
 `
@@ -6270,6 +6940,71 @@ func (q *sqlQuerier) GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (W
 	return i, err
 }
 
+const getWorkspaceAgentByIDExcludeDeletedWorkspace = `-- name: GetWorkspaceAgentByIDExcludeDeletedWorkspace :one
+SELECT
+	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, logs_length, logs_overflowed, subsystem, startup_script_behavior, started_at, ready_at
+FROM
+	workspace_agents
+WHERE
+	id = $1
+	AND EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_resources
+		JOIN
+			workspace_builds ON workspace_builds.job_id = workspace_resources.job_id
+		JOIN
+			workspaces ON workspaces.id = workspace_builds.workspace_id
+		WHERE
+			workspace_resources.id = workspace_agents.resource_id
+			AND workspaces.deleted = false
+	)
+`
+
+// GetWorkspaceAgentByIDExcludeDeletedWorkspace is like GetWorkspaceAgentByID,
+// but returns no rows if the agent does not belong to a non-deleted workspace.
+func (q *sqlQuerier) GetWorkspaceAgentByIDExcludeDeletedWorkspace(ctx context.Context, id uuid.UUID) (WorkspaceAgent, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceAgentByIDExcludeDeletedWorkspace, id)
+	var i WorkspaceAgent
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.FirstConnectedAt,
+		&i.LastConnectedAt,
+		&i.DisconnectedAt,
+		&i.ResourceID,
+		&i.AuthToken,
+		&i.AuthInstanceID,
+		&i.Architecture,
+		&i.EnvironmentVariables,
+		&i.OperatingSystem,
+		&i.StartupScript,
+		&i.InstanceMetadata,
+		&i.ResourceMetadata,
+		&i.Directory,
+		&i.Version,
+		&i.LastConnectedReplicaID,
+		&i.ConnectionTimeoutSeconds,
+		&i.TroubleshootingURL,
+		&i.MOTDFile,
+		&i.LifecycleState,
+		&i.StartupScriptTimeoutSeconds,
+		&i.ExpandedDirectory,
+		&i.ShutdownScript,
+		&i.ShutdownScriptTimeoutSeconds,
+		&i.LogsLength,
+		&i.LogsOverflowed,
+		&i.Subsystem,
+		&i.StartupScriptBehavior,
+		&i.StartedAt,
+		&i.ReadyAt,
+	)
+	return i, err
+}
+
 const getWorkspaceAgentByInstanceID = `-- name: GetWorkspaceAgentByInstanceID :one
 SELECT
 	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, logs_length, logs_overflowed, subsystem, startup_script_behavior, started_at, ready_at
@@ -6562,6 +7297,92 @@ func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfter(ctx context.Context, created
 	return items, nil
 }
 
+const getWorkspaceAgentsCreatedAfterWithWorkspace = `-- name: GetWorkspaceAgentsCreatedAfterWithWorkspace :many
+SELECT
+	workspace_agents.id, workspace_agents.created_at, workspace_agents.updated_at, workspace_agents.name, workspace_agents.first_connected_at, workspace_agents.last_connected_at, workspace_agents.disconnected_at, workspace_agents.resource_id, workspace_agents.auth_token, workspace_agents.auth_instance_id, workspace_agents.architecture, workspace_agents.environment_variables, workspace_agents.operating_system, workspace_agents.startup_script, workspace_agents.instance_metadata, workspace_agents.resource_metadata, workspace_agents.directory, workspace_agents.version, workspace_agents.last_connected_replica_id, workspace_agents.connection_timeout_seconds, workspace_agents.troubleshooting_url, workspace_agents.motd_file, workspace_agents.lifecycle_state, workspace_agents.startup_script_timeout_seconds, workspace_agents.expanded_directory, workspace_agents.shutdown_script, workspace_agents.shutdown_script_timeout_seconds, workspace_agents.logs_length, workspace_agents.logs_overflowed, workspace_agents.subsystem, workspace_agents.startup_script_behavior, workspace_agents.started_at, workspace_agents.ready_at,
+	workspace_builds.workspace_id,
+	workspaces.template_id
+FROM
+	workspace_agents
+JOIN
+	workspace_resources ON workspace_agents.resource_id = workspace_resources.id
+JOIN
+	workspace_builds ON workspace_resources.job_id = workspace_builds.job_id
+JOIN
+	workspaces ON workspace_builds.workspace_id = workspaces.id
+WHERE
+	workspace_agents.created_at > $1
+`
+
+type GetWorkspaceAgentsCreatedAfterWithWorkspaceRow struct {
+	WorkspaceAgent WorkspaceAgent `db:"workspace_agent" json:"workspace_agent"`
+	WorkspaceID    uuid.UUID      `db:"workspace_id" json:"workspace_id"`
+	TemplateID     uuid.UUID      `db:"template_id" json:"template_id"`
+}
+
+// GetWorkspaceAgentsCreatedAfterWithWorkspace returns the agents created after
+// the given time along with the workspace and template they belong to. Agents
+// that aren't part of a workspace build, such as those created by template
+// version imports, are omitted.
+func (q *sqlQuerier) GetWorkspaceAgentsCreatedAfterWithWorkspace(ctx context.Context, createdAt time.Time) ([]GetWorkspaceAgentsCreatedAfterWithWorkspaceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentsCreatedAfterWithWorkspace, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceAgentsCreatedAfterWithWorkspaceRow
+	for rows.Next() {
+		var i GetWorkspaceAgentsCreatedAfterWithWorkspaceRow
+		if err := rows.Scan(
+			&i.WorkspaceAgent.ID,
+			&i.WorkspaceAgent.CreatedAt,
+			&i.WorkspaceAgent.UpdatedAt,
+			&i.WorkspaceAgent.Name,
+			&i.WorkspaceAgent.FirstConnectedAt,
+			&i.WorkspaceAgent.LastConnectedAt,
+			&i.WorkspaceAgent.DisconnectedAt,
+			&i.WorkspaceAgent.ResourceID,
+			&i.WorkspaceAgent.AuthToken,
+			&i.WorkspaceAgent.AuthInstanceID,
+			&i.WorkspaceAgent.Architecture,
+			&i.WorkspaceAgent.EnvironmentVariables,
+			&i.WorkspaceAgent.OperatingSystem,
+			&i.WorkspaceAgent.StartupScript,
+			&i.WorkspaceAgent.InstanceMetadata,
+			&i.WorkspaceAgent.ResourceMetadata,
+			&i.WorkspaceAgent.Directory,
+			&i.WorkspaceAgent.Version,
+			&i.WorkspaceAgent.LastConnectedReplicaID,
+			&i.WorkspaceAgent.ConnectionTimeoutSeconds,
+			&i.WorkspaceAgent.TroubleshootingURL,
+			&i.WorkspaceAgent.MOTDFile,
+			&i.WorkspaceAgent.LifecycleState,
+			&i.WorkspaceAgent.StartupScriptTimeoutSeconds,
+			&i.WorkspaceAgent.ExpandedDirectory,
+			&i.WorkspaceAgent.ShutdownScript,
+			&i.WorkspaceAgent.ShutdownScriptTimeoutSeconds,
+			&i.WorkspaceAgent.LogsLength,
+			&i.WorkspaceAgent.LogsOverflowed,
+			&i.WorkspaceAgent.Subsystem,
+			&i.WorkspaceAgent.StartupScriptBehavior,
+			&i.WorkspaceAgent.StartedAt,
+			&i.WorkspaceAgent.ReadyAt,
+			&i.WorkspaceID,
+			&i.TemplateID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceAgentsInLatestBuildByWorkspaceID = `-- name: GetWorkspaceAgentsInLatestBuildByWorkspaceID :many
 SELECT
 	workspace_agents.id, workspace_agents.created_at, workspace_agents.updated_at, workspace_agents.name, workspace_agents.first_connected_at, workspace_agents.last_connected_at, workspace_agents.disconnected_at, workspace_agents.resource_id, workspace_agents.auth_token, workspace_agents.auth_instance_id, workspace_agents.architecture, workspace_agents.environment_variables, workspace_agents.operating_system, workspace_agents.startup_script, workspace_agents.instance_metadata, workspace_agents.resource_metadata, workspace_agents.directory, workspace_agents.version, workspace_agents.last_connected_replica_id, workspace_agents.connection_timeout_seconds, workspace_agents.troubleshooting_url, workspace_agents.motd_file, workspace_agents.lifecycle_state, workspace_agents.startup_script_timeout_seconds, workspace_agents.expanded_directory, workspace_agents.shutdown_script, workspace_agents.shutdown_script_timeout_seconds, workspace_agents.logs_length, workspace_agents.logs_overflowed, workspace_agents.subsystem, workspace_agents.startup_script_behavior, workspace_agents.started_at, workspace_agents.ready_at
@@ -6580,11 +7401,22 @@ WHERE
 			workspace_builds AS wb
     	WHERE
 			wb.workspace_id = $1 :: uuid
-	)
+	) AND
+	-- Optionally filter by the type of the resource the agent is on.
+	CASE
+		WHEN $2 :: text != '' THEN
+			workspace_resources.type = $2
+		ELSE true
+	END
 `
 
-func (q *sqlQuerier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) ([]WorkspaceAgent, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentsInLatestBuildByWorkspaceID, workspaceID)
+type GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams struct {
+	WorkspaceID  uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	ResourceType string    `db:"resource_type" json:"resource_type"`
+}
+
+func (q *sqlQuerier) GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx context.Context, arg GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams) ([]WorkspaceAgent, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentsInLatestBuildByWorkspaceID, arg.WorkspaceID, arg.ResourceType)
 	if err != nil {
 		return nil, err
 	}
@@ -6853,6 +7685,46 @@ func (q *sqlQuerier) InsertWorkspaceAgentMetadata(ctx context.Context, arg Inser
 	return err
 }
 
+const insertWorkspaceAgentMetadataBatch = `-- name: InsertWorkspaceAgentMetadataBatch :exec
+INSERT INTO
+	workspace_agent_metadata (
+		workspace_agent_id,
+		display_name,
+		key,
+		script,
+		timeout,
+		interval
+	)
+SELECT
+	$1 :: uuid AS workspace_agent_id,
+	unnest($2 :: text [ ]) AS display_name,
+	unnest($3 :: text [ ]) AS key,
+	unnest($4 :: text [ ]) AS script,
+	unnest($5 :: bigint [ ]) AS timeout,
+	unnest($6 :: bigint [ ]) AS interval
+`
+
+type InsertWorkspaceAgentMetadataBatchParams struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	DisplayName      []string  `db:"display_name" json:"display_name"`
+	Key              []string  `db:"key" json:"key"`
+	Script           []string  `db:"script" json:"script"`
+	Timeout          []int64   `db:"timeout" json:"timeout"`
+	Interval         []int64   `db:"interval" json:"interval"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAgentMetadataBatch(ctx context.Context, arg InsertWorkspaceAgentMetadataBatchParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceAgentMetadataBatch,
+		arg.WorkspaceAgentID,
+		pq.Array(arg.DisplayName),
+		pq.Array(arg.Key),
+		pq.Array(arg.Script),
+		pq.Array(arg.Timeout),
+		pq.Array(arg.Interval),
+	)
+	return err
+}
+
 const updateWorkspaceAgentConnectionByID = `-- name: UpdateWorkspaceAgentConnectionByID :exec
 UPDATE
 	workspace_agents
@@ -7013,7 +7885,7 @@ WHERE
 GROUP BY
 	date, user_id
 ORDER BY
-	date ASC
+	date ASC, user_id ASC
 `
 
 type GetDeploymentDAUsRow struct {
@@ -7050,7 +7922,10 @@ WITH agent_stats AS (
 		coalesce(SUM(rx_bytes), 0)::bigint AS workspace_rx_bytes,
 		coalesce(SUM(tx_bytes), 0)::bigint AS workspace_tx_bytes,
 		coalesce((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_50,
-		coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95
+		coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95,
+		-- has_data distinguishes "no stats" from real values, since the
+		-- latencies above fall back to -1.
+		(COUNT(*) > 0)::boolean AS has_data
 	 FROM workspace_agent_stats
 	 	-- The greater than 0 is to support legacy agents that don't report connection_median_latency_ms.
 		WHERE workspace_agent_stats.created_at > $1 AND connection_median_latency_ms > 0
@@ -7065,7 +7940,7 @@ WITH agent_stats AS (
 		FROM workspace_agent_stats WHERE created_at > $1
 	) AS a WHERE a.rn = 1
 )
-SELECT workspace_rx_bytes, workspace_tx_bytes, workspace_connection_latency_50, workspace_connection_latency_95, session_count_vscode, session_count_ssh, session_count_jetbrains, session_count_reconnecting_pty FROM agent_stats, latest_agent_stats
+SELECT workspace_rx_bytes, workspace_tx_bytes, workspace_connection_latency_50, workspace_connection_latency_95, has_data, session_count_vscode, session_count_ssh, session_count_jetbrains, session_count_reconnecting_pty FROM agent_stats, latest_agent_stats
 `
 
 type GetDeploymentWorkspaceAgentStatsRow struct {
@@ -7073,6 +7948,7 @@ type GetDeploymentWorkspaceAgentStatsRow struct {
 	WorkspaceTxBytes             int64   `db:"workspace_tx_bytes" json:"workspace_tx_bytes"`
 	WorkspaceConnectionLatency50 float64 `db:"workspace_connection_latency_50" json:"workspace_connection_latency_50"`
 	WorkspaceConnectionLatency95 float64 `db:"workspace_connection_latency_95" json:"workspace_connection_latency_95"`
+	HasData                      bool    `db:"has_data" json:"has_data"`
 	SessionCountVSCode           int64   `db:"session_count_vscode" json:"session_count_vscode"`
 	SessionCountSSH              int64   `db:"session_count_ssh" json:"session_count_ssh"`
 	SessionCountJetBrains        int64   `db:"session_count_jetbrains" json:"session_count_jetbrains"`
@@ -7087,6 +7963,7 @@ func (q *sqlQuerier) GetDeploymentWorkspaceAgentStats(ctx context.Context, creat
 		&i.WorkspaceTxBytes,
 		&i.WorkspaceConnectionLatency50,
 		&i.WorkspaceConnectionLatency95,
+		&i.HasData,
 		&i.SessionCountVSCode,
 		&i.SessionCountSSH,
 		&i.SessionCountJetBrains,
@@ -7095,6 +7972,58 @@ func (q *sqlQuerier) GetDeploymentWorkspaceAgentStats(ctx context.Context, creat
 	return i, err
 }
 
+const getTemplateAndDeploymentDAUs = `-- name: GetTemplateAndDeploymentDAUs :many
+SELECT
+	(created_at at TIME ZONE cast($1::integer as text))::date as date,
+	COUNT(DISTINCT user_id) FILTER (WHERE template_id = $2)::bigint AS template_active_users,
+	COUNT(DISTINCT user_id)::bigint AS deployment_active_users
+FROM
+	workspace_agent_stats
+WHERE
+	connection_count > 0
+GROUP BY
+	date
+ORDER BY
+	date ASC
+`
+
+type GetTemplateAndDeploymentDAUsParams struct {
+	TzOffset   int32     `db:"tz_offset" json:"tz_offset"`
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+}
+
+type GetTemplateAndDeploymentDAUsRow struct {
+	Date                  time.Time `db:"date" json:"date"`
+	TemplateActiveUsers   int64     `db:"template_active_users" json:"template_active_users"`
+	DeploymentActiveUsers int64     `db:"deployment_active_users" json:"deployment_active_users"`
+}
+
+// GetTemplateAndDeploymentDAUs returns, per date, the number of users active
+// on the given template alongside the number of users active in the whole
+// deployment, so the template's share of daily users can be computed.
+func (q *sqlQuerier) GetTemplateAndDeploymentDAUs(ctx context.Context, arg GetTemplateAndDeploymentDAUsParams) ([]GetTemplateAndDeploymentDAUsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateAndDeploymentDAUs, arg.TzOffset, arg.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateAndDeploymentDAUsRow
+	for rows.Next() {
+		var i GetTemplateAndDeploymentDAUsRow
+		if err := rows.Scan(&i.Date, &i.TemplateActiveUsers, &i.DeploymentActiveUsers); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTemplateDAUs = `-- name: GetTemplateDAUs :many
 SELECT
 	(created_at at TIME ZONE cast($2::integer as text))::date as date,
@@ -7157,7 +8086,10 @@ WITH agent_stats AS (
 		coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95
 	 FROM workspace_agent_stats
 	 	-- The greater than 0 is to support legacy agents that don't report connection_median_latency_ms.
-		WHERE workspace_agent_stats.created_at > $1 AND connection_median_latency_ms > 0 GROUP BY user_id, agent_id, workspace_id, template_id
+		WHERE workspace_agent_stats.created_at > $1 AND connection_median_latency_ms > 0
+		-- Optionally ignore samples without any connections, like the DAU queries.
+		AND CASE WHEN $2 :: boolean THEN connection_count > 0 ELSE true END
+		GROUP BY user_id, agent_id, workspace_id, template_id
 ), latest_agent_stats AS (
 	SELECT
 		a.agent_id,
@@ -7168,11 +8100,25 @@ WITH agent_stats AS (
 	 FROM (
 		SELECT id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
 		FROM workspace_agent_stats WHERE created_at > $1
+		AND CASE WHEN $2 :: boolean THEN connection_count > 0 ELSE true END
 	) AS a WHERE a.rn = 1 GROUP BY a.user_id, a.agent_id, a.workspace_id, a.template_id
 )
 SELECT user_id, agent_stats.agent_id, workspace_id, template_id, aggregated_from, workspace_rx_bytes, workspace_tx_bytes, workspace_connection_latency_50, workspace_connection_latency_95, latest_agent_stats.agent_id, session_count_vscode, session_count_ssh, session_count_jetbrains, session_count_reconnecting_pty FROM agent_stats JOIN latest_agent_stats ON agent_stats.agent_id = latest_agent_stats.agent_id
+WHERE
+	-- Optionally restrict aggregation to agents that are currently ready.
+	CASE
+		WHEN $3 :: boolean THEN
+			agent_stats.agent_id IN (SELECT id FROM workspace_agents WHERE lifecycle_state = 'ready')
+		ELSE true
+	END
 `
 
+type GetWorkspaceAgentStatsParams struct {
+	CreatedAt              time.Time `db:"created_at" json:"created_at"`
+	ExcludeZeroConnections bool      `db:"exclude_zero_connections" json:"exclude_zero_connections"`
+	ReadyOnly              bool      `db:"ready_only" json:"ready_only"`
+}
+
 type GetWorkspaceAgentStatsRow struct {
 	UserID                       uuid.UUID `db:"user_id" json:"user_id"`
 	AgentID                      uuid.UUID `db:"agent_id" json:"agent_id"`
@@ -7190,8 +8136,8 @@ type GetWorkspaceAgentStatsRow struct {
 	SessionCountReconnectingPTY  int64     `db:"session_count_reconnecting_pty" json:"session_count_reconnecting_pty"`
 }
 
-func (q *sqlQuerier) GetWorkspaceAgentStats(ctx context.Context, createdAt time.Time) ([]GetWorkspaceAgentStatsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentStats, createdAt)
+func (q *sqlQuerier) GetWorkspaceAgentStats(ctx context.Context, arg GetWorkspaceAgentStatsParams) ([]GetWorkspaceAgentStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentStats, arg.CreatedAt, arg.ExcludeZeroConnections, arg.ReadyOnly)
 	if err != nil {
 		return nil, err
 	}
@@ -7503,16 +8449,45 @@ func (q *sqlQuerier) InsertWorkspaceAgentStats(ctx context.Context, arg InsertWo
 }
 
 const getWorkspaceAppByAgentIDAndSlug = `-- name: GetWorkspaceAppByAgentIDAndSlug :one
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = $1 AND slug = $2
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	agent_id = $1
+	AND slug = $2
+	-- External apps are opened by the client and must never be proxied.
+	AND (NOT $3 :: boolean OR NOT external)
 `
 
 type GetWorkspaceAppByAgentIDAndSlugParams struct {
-	AgentID uuid.UUID `db:"agent_id" json:"agent_id"`
-	Slug    string    `db:"slug" json:"slug"`
+	AgentID         uuid.UUID `db:"agent_id" json:"agent_id"`
+	Slug            string    `db:"slug" json:"slug"`
+	ExcludeExternal bool      `db:"exclude_external" json:"exclude_external"`
 }
 
 func (q *sqlQuerier) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg GetWorkspaceAppByAgentIDAndSlugParams) (WorkspaceApp, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceAppByAgentIDAndSlug, arg.AgentID, arg.Slug)
+	row := q.db.QueryRowContext(ctx, getWorkspaceAppByAgentIDAndSlug, arg.AgentID, arg.Slug, arg.ExcludeExternal)
 	var i WorkspaceApp
 	err := row.Scan(
 		&i.ID,
@@ -7535,7 +8510,34 @@ func (q *sqlQuerier) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg Ge
 }
 
 const getWorkspaceAppsByAgentID = `-- name: GetWorkspaceAppsByAgentID :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	agent_id = $1
+ORDER BY
+	slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error) {
@@ -7578,7 +8580,34 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 }
 
 const getWorkspaceAppsByAgentIDs = `-- name: GetWorkspaceAppsByAgentIDs :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY slug ASC
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	agent_id = ANY($1 :: uuid [ ])
+ORDER BY
+	slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error) {
@@ -7621,7 +8650,34 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 }
 
 const getWorkspaceAppsCreatedAfter = `-- name: GetWorkspaceAppsCreatedAfter :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	created_at > $1
+ORDER BY
+	slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error) {
@@ -7762,6 +8818,28 @@ func (q *sqlQuerier) UpdateWorkspaceAppHealthByID(ctx context.Context, arg Updat
 	return err
 }
 
+const getWorkspaceBuildParameterByName = `-- name: GetWorkspaceBuildParameterByName :one
+SELECT
+    workspace_build_id, name, value
+FROM
+    workspace_build_parameters
+WHERE
+    workspace_build_id = $1
+    AND name = $2
+`
+
+type GetWorkspaceBuildParameterByNameParams struct {
+	WorkspaceBuildID uuid.UUID `db:"workspace_build_id" json:"workspace_build_id"`
+	Name             string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetWorkspaceBuildParameterByName(ctx context.Context, arg GetWorkspaceBuildParameterByNameParams) (WorkspaceBuildParameter, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceBuildParameterByName, arg.WorkspaceBuildID, arg.Name)
+	var i WorkspaceBuildParameter
+	err := row.Scan(&i.WorkspaceBuildID, &i.Name, &i.Value)
+	return i, err
+}
+
 const getWorkspaceBuildParameters = `-- name: GetWorkspaceBuildParameters :many
 SELECT
     workspace_build_id, name, value
@@ -7794,6 +8872,38 @@ func (q *sqlQuerier) GetWorkspaceBuildParameters(ctx context.Context, workspaceB
 	return items, nil
 }
 
+const getWorkspaceBuildParametersByBuildIDs = `-- name: GetWorkspaceBuildParametersByBuildIDs :many
+SELECT
+    workspace_build_id, name, value
+FROM
+    workspace_build_parameters
+WHERE
+    workspace_build_id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetWorkspaceBuildParametersByBuildIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceBuildParameter, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceBuildParametersByBuildIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceBuildParameter
+	for rows.Next() {
+		var i WorkspaceBuildParameter
+		if err := rows.Scan(&i.WorkspaceBuildID, &i.Name, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertWorkspaceBuildParameters = `-- name: InsertWorkspaceBuildParameters :exec
 INSERT INTO
     workspace_build_parameters (workspace_build_id, name, value)
@@ -7823,7 +8933,10 @@ FROM
 WHERE
 	workspace_id = $1
 ORDER BY
-    build_number desc
+    build_number desc,
+    -- Break ties deterministically should build numbers ever collide.
+    created_at desc,
+    id desc
 LIMIT
 	1
 `
@@ -8073,6 +9186,50 @@ func (q *sqlQuerier) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.Co
 	return i, err
 }
 
+const getWorkspaceBuildWithTemplateVersionByID = `-- name: GetWorkspaceBuildWithTemplateVersionByID :one
+SELECT
+	workspace_builds.id, workspace_builds.created_at, workspace_builds.updated_at, workspace_builds.workspace_id, workspace_builds.template_version_id, workspace_builds.build_number, workspace_builds.transition, workspace_builds.initiator_id, workspace_builds.provisioner_state, workspace_builds.job_id, workspace_builds.deadline, workspace_builds.reason, workspace_builds.daily_cost, workspace_builds.max_deadline, workspace_builds.initiator_by_avatar_url, workspace_builds.initiator_by_username,
+	template_versions.name AS template_version_name
+FROM
+	workspace_build_with_user AS workspace_builds
+JOIN
+	template_versions ON template_versions.id = workspace_builds.template_version_id
+WHERE
+	workspace_builds.id = $1
+LIMIT
+	1
+`
+
+type GetWorkspaceBuildWithTemplateVersionByIDRow struct {
+	WorkspaceBuild      WorkspaceBuild `db:"workspace_build" json:"workspace_build"`
+	TemplateVersionName string         `db:"template_version_name" json:"template_version_name"`
+}
+
+func (q *sqlQuerier) GetWorkspaceBuildWithTemplateVersionByID(ctx context.Context, id uuid.UUID) (GetWorkspaceBuildWithTemplateVersionByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceBuildWithTemplateVersionByID, id)
+	var i GetWorkspaceBuildWithTemplateVersionByIDRow
+	err := row.Scan(
+		&i.WorkspaceBuild.ID,
+		&i.WorkspaceBuild.CreatedAt,
+		&i.WorkspaceBuild.UpdatedAt,
+		&i.WorkspaceBuild.WorkspaceID,
+		&i.WorkspaceBuild.TemplateVersionID,
+		&i.WorkspaceBuild.BuildNumber,
+		&i.WorkspaceBuild.Transition,
+		&i.WorkspaceBuild.InitiatorID,
+		&i.WorkspaceBuild.ProvisionerState,
+		&i.WorkspaceBuild.JobID,
+		&i.WorkspaceBuild.Deadline,
+		&i.WorkspaceBuild.Reason,
+		&i.WorkspaceBuild.DailyCost,
+		&i.WorkspaceBuild.MaxDeadline,
+		&i.WorkspaceBuild.InitiatorByAvatarUrl,
+		&i.WorkspaceBuild.InitiatorByUsername,
+		&i.TemplateVersionName,
+	)
+	return i, err
+}
+
 const getWorkspaceBuildsByWorkspaceID = `-- name: GetWorkspaceBuildsByWorkspaceID :many
 SELECT
 	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username
@@ -8100,19 +9257,26 @@ WHERE
 		)
 		ELSE true
 END
+	-- Filter by the username of the user that started the build
+	AND CASE
+		WHEN $4 :: text != '' THEN
+			LOWER(workspace_builds.initiator_by_username) = LOWER($4)
+		ELSE true
+	END
 ORDER BY
-    build_number desc OFFSET $4
+    build_number desc OFFSET $5
 LIMIT
     -- A null limit means "no limit", so 0 means return all
-    NULLIF($5 :: int, 0)
+    NULLIF($6 :: int, 0)
 `
 
 type GetWorkspaceBuildsByWorkspaceIDParams struct {
-	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
-	Since       time.Time `db:"since" json:"since"`
-	AfterID     uuid.UUID `db:"after_id" json:"after_id"`
-	OffsetOpt   int32     `db:"offset_opt" json:"offset_opt"`
-	LimitOpt    int32     `db:"limit_opt" json:"limit_opt"`
+	WorkspaceID       uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	Since             time.Time `db:"since" json:"since"`
+	AfterID           uuid.UUID `db:"after_id" json:"after_id"`
+	InitiatorUsername string    `db:"initiator_username" json:"initiator_username"`
+	OffsetOpt         int32     `db:"offset_opt" json:"offset_opt"`
+	LimitOpt          int32     `db:"limit_opt" json:"limit_opt"`
 }
 
 func (q *sqlQuerier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg GetWorkspaceBuildsByWorkspaceIDParams) ([]WorkspaceBuild, error) {
@@ -8120,6 +9284,7 @@ func (q *sqlQuerier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg Ge
 		arg.WorkspaceID,
 		arg.Since,
 		arg.AfterID,
+		arg.InitiatorUsername,
 		arg.OffsetOpt,
 		arg.LimitOpt,
 	)
@@ -8162,11 +9327,34 @@ func (q *sqlQuerier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg Ge
 }
 
 const getWorkspaceBuildsCreatedAfter = `-- name: GetWorkspaceBuildsCreatedAfter :many
-SELECT id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username FROM workspace_build_with_user WHERE created_at > $1
+SELECT
+	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username
+FROM
+	workspace_build_with_user
+WHERE
+	created_at > $1
+	-- Optionally filter by transition
+	AND CASE
+		WHEN $2 :: text != '' THEN
+			transition :: text = $2
+		ELSE true
+	END
+	-- Optionally filter by build reason
+	AND CASE
+		WHEN $3 :: text != '' THEN
+			reason :: text = $3
+		ELSE true
+	END
 `
 
-func (q *sqlQuerier) GetWorkspaceBuildsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceBuild, error) {
-	rows, err := q.db.QueryContext(ctx, getWorkspaceBuildsCreatedAfter, createdAt)
+type GetWorkspaceBuildsCreatedAfterParams struct {
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	Transition string    `db:"transition" json:"transition"`
+	Reason     string    `db:"reason" json:"reason"`
+}
+
+func (q *sqlQuerier) GetWorkspaceBuildsCreatedAfter(ctx context.Context, arg GetWorkspaceBuildsCreatedAfterParams) ([]WorkspaceBuild, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceBuildsCreatedAfter, arg.CreatedAt, arg.Transition, arg.Reason)
 	if err != nil {
 		return nil, err
 	}
@@ -8418,6 +9606,9 @@ FROM
 	workspace_resources
 WHERE
 	job_id = $1
+ORDER BY
+	type ASC,
+	name ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceResourcesByJobID(ctx context.Context, jobID uuid.UUID) ([]WorkspaceResource, error) {
@@ -8669,7 +9860,9 @@ WITH workspaces_with_jobs AS (
 		started_at IS NOT NULL AND
 		canceled_at IS NULL AND
 		completed_at IS NULL AND
-		updated_at - INTERVAL '30 seconds' < NOW()
+		-- Jobs that haven't been updated within the building window are
+		-- not considered to be building. A zero window defaults to 30 seconds.
+		updated_at >= NOW() - COALESCE(NULLIF($1 :: int, 0), 30) * INTERVAL '1 second'
 ), running_workspaces AS (
 	SELECT COUNT(*) AS count FROM workspaces_with_jobs WHERE
 		completed_at IS NOT NULL AND
@@ -8706,8 +9899,8 @@ type GetDeploymentWorkspaceStatsRow struct {
 	StoppedWorkspaces  int64 `db:"stopped_workspaces" json:"stopped_workspaces"`
 }
 
-func (q *sqlQuerier) GetDeploymentWorkspaceStats(ctx context.Context) (GetDeploymentWorkspaceStatsRow, error) {
-	row := q.db.QueryRowContext(ctx, getDeploymentWorkspaceStats)
+func (q *sqlQuerier) GetDeploymentWorkspaceStats(ctx context.Context, buildingWindowSeconds int32) (GetDeploymentWorkspaceStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getDeploymentWorkspaceStats, buildingWindowSeconds)
 	var i GetDeploymentWorkspaceStatsRow
 	err := row.Scan(
 		&i.PendingWorkspaces,
@@ -8809,19 +10002,27 @@ FROM
 	workspaces
 WHERE
 	owner_id = $1
-	AND deleted = $2
-	AND LOWER("name") = LOWER($3)
+	-- Optionally ignore the deleted flag and return the newest workspace
+	-- with the given name regardless of its state.
+	AND ($2 :: boolean OR deleted = $3)
+	AND LOWER("name") = LOWER($4)
 ORDER BY created_at DESC
 `
 
 type GetWorkspaceByOwnerIDAndNameParams struct {
-	OwnerID uuid.UUID `db:"owner_id" json:"owner_id"`
-	Deleted bool      `db:"deleted" json:"deleted"`
-	Name    string    `db:"name" json:"name"`
+	OwnerID        uuid.UUID `db:"owner_id" json:"owner_id"`
+	IncludeDeleted bool      `db:"include_deleted" json:"include_deleted"`
+	Deleted        bool      `db:"deleted" json:"deleted"`
+	Name           string    `db:"name" json:"name"`
 }
 
 func (q *sqlQuerier) GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWorkspaceByOwnerIDAndNameParams) (Workspace, error) {
-	row := q.db.QueryRowContext(ctx, getWorkspaceByOwnerIDAndName, arg.OwnerID, arg.Deleted, arg.Name)
+	row := q.db.QueryRowContext(ctx, getWorkspaceByOwnerIDAndName,
+		arg.OwnerID,
+		arg.IncludeDeleted,
+		arg.Deleted,
+		arg.Name,
+	)
 	var i Workspace
 	err := row.Scan(
 		&i.ID,
@@ -8899,6 +10100,23 @@ func (q *sqlQuerier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspace
 	return i, err
 }
 
+const getWorkspaceCountByOwner = `-- name: GetWorkspaceCountByOwner :one
+SELECT
+	COUNT(*)
+FROM
+	workspaces
+WHERE
+	owner_id = $1
+	AND deleted = false
+`
+
+func (q *sqlQuerier) GetWorkspaceCountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceCountByOwner, ownerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getWorkspaces = `-- name: GetWorkspaces :many
 SELECT
 	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at,
@@ -9096,6 +10314,17 @@ WHERE
 	-- Authorize Filter clause will be injected below in GetAuthorizedWorkspaces
 	-- @authorize_filter
 ORDER BY
+	-- Optionally group workspaces by their template name. Supported values
+	-- of sort_by are '' (the default ordering) and 'template'. Keep in sync
+	-- with database.WorkspaceSortBy.
+	CASE
+		WHEN $11 :: text = 'template' THEN
+			LOWER(COALESCE(template_name.template_name, 'unknown'))
+	END ASC,
+	CASE
+		WHEN $11 = 'template' THEN
+			LOWER(workspaces.name)
+	END ASC,
 	(latest_build.completed_at IS NOT NULL AND
 		latest_build.canceled_at IS NULL AND
 		latest_build.error IS NULL AND
@@ -9104,11 +10333,11 @@ ORDER BY
 	LOWER(workspaces.name) ASC
 LIMIT
 	CASE
-		WHEN $12 :: integer > 0 THEN
-			$12
+		WHEN $13 :: integer > 0 THEN
+			$13
 	END
 OFFSET
-	$11
+	$12
 `
 
 type GetWorkspacesParams struct {
@@ -9122,6 +10351,7 @@ type GetWorkspacesParams struct {
 	HasAgent                              string      `db:"has_agent" json:"has_agent"`
 	AgentInactiveDisconnectTimeoutSeconds int64       `db:"agent_inactive_disconnect_timeout_seconds" json:"agent_inactive_disconnect_timeout_seconds"`
 	LockedAt                              time.Time   `db:"locked_at" json:"locked_at"`
+	SortBy                                string      `db:"sort_by" json:"sort_by"`
 	Offset                                int32       `db:"offset_" json:"offset_"`
 	Limit                                 int32       `db:"limit_" json:"limit_"`
 }
@@ -9158,6 +10388,7 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 		arg.HasAgent,
 		arg.AgentInactiveDisconnectTimeoutSeconds,
 		arg.LockedAt,
+		arg.SortBy,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -9263,7 +10494,11 @@ WHERE
 			templates.locked_ttl > 0 AND
 			workspaces.locked_at IS NOT NULL
 		)
-	) AND workspaces.deleted = 'false'
+	) AND
+	-- Workspaces whose latest build is still pending or running are not
+	-- eligible, otherwise we'd attempt a conflicting build.
+	provisioner_jobs.completed_at IS NOT NULL AND
+	workspaces.deleted = 'false'
 `
 
 func (q *sqlQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]Workspace, error) {
@@ -30,6 +30,9 @@ VALUES
 -- name: GetFileTemplates :many
 -- Get all templates that use a file.
 SELECT
+	-- A template may have several versions built from the same file, only
+	-- return each template once.
+	DISTINCT ON (templates.id)
 	files.id AS file_id,
 	files.created_by AS file_created_by,
 	templates.id AS template_id,
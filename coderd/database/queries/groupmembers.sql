@@ -40,6 +40,13 @@ WHERE
 	group_members.user_id = @user_id
 	AND group_id = ANY(SELECT id FROM groups WHERE organization_id = @organization_id);
 
+-- name: DeleteAllGroupMembersByUserID :exec
+-- Removes the user from every group in every organization.
+DELETE FROM
+	group_members
+WHERE
+	user_id = @user_id;
+
 -- name: InsertGroupMember :exec
 INSERT INTO
     group_members (user_id, group_id)
@@ -14,9 +14,14 @@ SELECT
 FROM
 	groups
 WHERE
-	organization_id = $1
+	organization_id = @organization_id
 AND
-	name = $2
+	CASE
+		WHEN @case_insensitive :: boolean THEN
+			lower(name) = lower(@name)
+		ELSE
+			name = @name
+	END
 LIMIT
 	1;
 
@@ -26,9 +31,11 @@ SELECT
 FROM
 	groups
 WHERE
-	organization_id = $1
+	organization_id = @organization_id
 AND
-	id != $1;
+	-- The allUsers group shares its ID with the organization and is omitted
+	-- unless explicitly requested.
+	(@include_everyone :: boolean OR id != @organization_id);
 
 -- name: InsertGroup :one
 INSERT INTO groups (
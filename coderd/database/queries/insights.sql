@@ -2,14 +2,16 @@
 -- GetUserLatencyInsights returns the median and 95th percentile connection
 -- latency that users have experienced. The result can be filtered on
 -- template_ids, meaning only user data from workspaces based on those templates
--- will be included.
+-- will be included. Additional percentiles (fractions between 0 and 1) can be
+-- requested with percentiles and are returned in the same order.
 SELECT
 	workspace_agent_stats.user_id,
 	users.username,
 	users.avatar_url,
 	array_agg(DISTINCT template_id)::uuid[] AS template_ids,
 	coalesce((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_50,
-	coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95
+	coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95,
+	coalesce((PERCENTILE_CONT(@percentiles::float8[]) WITHIN GROUP (ORDER BY connection_median_latency_ms)), '{}')::float8[] AS workspace_connection_latency_percentiles
 FROM workspace_agent_stats
 JOIN users ON (users.id = workspace_agent_stats.user_id)
 WHERE
@@ -55,15 +57,36 @@ WITH d AS (
 	SELECT array_agg(DISTINCT template_id) AS ids
 	FROM usage_by_user, unnest(template_ids) template_id
 	WHERE template_id IS NOT NULL
+), sessions AS (
+	-- Coalesce contiguous 5 minute intervals of a user into sessions.
+	-- Subtracting the row number from the interval start yields the same
+	-- value for every interval within a contiguous run.
+	SELECT
+		user_id,
+		COUNT(*) * EXTRACT(epoch FROM '5 minute'::interval) AS duration_seconds
+	FROM (
+		SELECT
+			user_id,
+			from_ - ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY from_) * '5 minute'::interval AS session_id
+		FROM usage_by_user
+	) AS user_intervals
+	GROUP BY user_id, session_id
+), active_users AS (
+	-- Only users whose total usage meets the threshold count as active.
+	SELECT user_id
+	FROM usage_by_user
+	GROUP BY user_id
+	HAVING COUNT(*) * EXTRACT(epoch FROM '5 minute'::interval) >= @min_active_seconds::bigint
 )
 
 SELECT
 	COALESCE((SELECT ids FROM template_ids), '{}')::uuid[] AS template_ids,
-	COUNT(DISTINCT user_id) AS active_users,
+	(SELECT COUNT(*) FROM active_users)::bigint AS active_users,
 	COALESCE(SUM(usage_vscode_seconds), 0)::bigint AS usage_vscode_seconds,
 	COALESCE(SUM(usage_jetbrains_seconds), 0)::bigint AS usage_jetbrains_seconds,
 	COALESCE(SUM(usage_reconnecting_pty_seconds), 0)::bigint AS usage_reconnecting_pty_seconds,
-	COALESCE(SUM(usage_ssh_seconds), 0)::bigint AS usage_ssh_seconds
+	COALESCE(SUM(usage_ssh_seconds), 0)::bigint AS usage_ssh_seconds,
+	COALESCE((SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_seconds) FROM sessions), 0)::bigint AS median_session_duration_seconds
 FROM usage_by_user;
 
 -- name: GetTemplateDailyInsights :many
@@ -7,7 +7,10 @@ WHERE
 	job_id = @job_id
 	AND (
 		id > @created_after
-	) ORDER BY id ASC;
+	) ORDER BY id ASC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_ :: int, 0);
 
 -- name: InsertProvisionerJobLogs :many
 INSERT INTO
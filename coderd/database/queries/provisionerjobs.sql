@@ -23,6 +23,21 @@ WHERE
 			AND nested.provisioner = ANY(@types :: provisioner_type [ ])
 			-- Ensure the caller satisfies all job tags.
 			AND nested.tags <@ @tags :: jsonb
+			-- Ensure the caller satisfies all tags required by the template
+			-- of the workspace being built, if any.
+			AND COALESCE((
+				SELECT
+					templates.provisioner_tags
+				FROM
+					workspace_builds
+				JOIN
+					template_versions ON template_versions.id = workspace_builds.template_version_id
+				JOIN
+					templates ON templates.id = template_versions.template_id
+				WHERE
+					workspace_builds.job_id = nested.id
+					AND jsonb_typeof(templates.provisioner_tags) = 'object'
+			), '{}' :: jsonb) <@ @tags :: jsonb
 		ORDER BY
 			nested.created_at
 		FOR UPDATE
@@ -31,6 +46,31 @@ WHERE
 			1
 	) RETURNING *;
 
+-- Cancels all jobs that have not yet been acquired by a provisioner. This is
+-- used when draining a deployment.
+-- name: CancelPendingProvisionerJobs :execrows
+UPDATE
+	provisioner_jobs
+SET
+	canceled_at = @canceled_at :: timestamptz,
+	completed_at = @canceled_at :: timestamptz,
+	updated_at = @canceled_at :: timestamptz
+WHERE
+	started_at IS NULL
+	AND canceled_at IS NULL;
+
+-- Counts jobs that have been acquired by a provisioner but have not yet
+-- completed or been canceled.
+-- name: CountRunningProvisionerJobs :one
+SELECT
+	COUNT(*)
+FROM
+	provisioner_jobs
+WHERE
+	started_at IS NOT NULL
+	AND completed_at IS NULL
+	AND canceled_at IS NULL;
+
 -- name: GetProvisionerJobByID :one
 SELECT
 	*
@@ -50,37 +90,67 @@ WHERE
 -- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, provisioner, tags
     FROM
         provisioner_jobs
     WHERE
         started_at IS NULL
 ),
+-- Jobs only compete with other jobs that can be acquired by the same
+-- daemons, so each provisioner type and tag set has its own queue.
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (PARTITION BY provisioner, tags ORDER BY created_at ASC) AS queue_position,
+        COUNT(*) OVER (PARTITION BY provisioner, tags) AS queue_size
     FROM
         unstarted_jobs
-),
-queue_size AS (
-	SELECT COUNT(*) as count FROM unstarted_jobs
 )
 SELECT
 	sqlc.embed(pj),
     COALESCE(qp.queue_position, 0) AS queue_position,
-    COALESCE(qs.count, 0) AS queue_size
+    COALESCE(qp.queue_size, 0) AS queue_size
 FROM
 	provisioner_jobs pj
 LEFT JOIN
 	queue_position qp ON qp.id = pj.id
-LEFT JOIN
-	queue_size qs ON TRUE
 WHERE
 	pj.id = ANY(@ids :: uuid [ ]);
 
 -- name: GetProvisionerJobsCreatedAfter :many
-SELECT * FROM provisioner_jobs WHERE created_at > $1;
+SELECT
+	*
+FROM
+	provisioner_jobs
+WHERE
+	created_at > @created_at
+	-- Optionally filter by job type.
+	AND CASE
+		WHEN cardinality(@types :: provisioner_job_type[]) > 0 THEN
+			type = ANY(@types :: provisioner_job_type[])
+		ELSE true
+	END
+	-- Optionally filter by job status. The status values match
+	-- codersdk.ProvisionerJobStatus.
+	AND CASE
+		WHEN @status :: text != '' THEN
+			(CASE
+				WHEN canceled_at IS NOT NULL THEN
+					CASE
+						WHEN completed_at IS NULL THEN 'canceling'
+						WHEN COALESCE(error, '') = '' THEN 'canceled'
+						ELSE 'failed'
+					END
+				WHEN started_at IS NULL THEN 'pending'
+				WHEN completed_at IS NOT NULL THEN
+					CASE
+						WHEN COALESCE(error, '') = '' THEN 'succeeded'
+						ELSE 'failed'
+					END
+				ELSE 'running'
+			END) = @status
+		ELSE true
+	END;
 
 -- name: InsertProvisionerJob :one
 INSERT INTO
@@ -83,13 +83,33 @@ WHERE
 LIMIT
 	1;
 
+-- name: GetWorkspaceProxyByTokenHash :one
+SELECT
+	*
+FROM
+	workspace_proxies
+WHERE
+	token_hashed_secret = @hash
+	AND deleted = false
+LIMIT
+	1;
+
 -- name: GetWorkspaceProxies :many
 SELECT
 	*
 FROM
 	workspace_proxies
 WHERE
-	deleted = false;
+	deleted = false
+	-- Filter by display name
+	AND CASE
+		WHEN @search :: text != '' THEN
+			-- Match literally so wildcards in the search aren't interpreted.
+			position(lower(@search :: text) in lower(display_name)) > 0
+		ELSE true
+	END
+ORDER BY
+	name ASC;
 
 -- Finds a workspace proxy that has an access URL or app hostname that matches
 -- the provided hostname. This is to check if a hostname matches any workspace
@@ -9,11 +9,15 @@ WHERE
 	user_id = $1;
 
 -- name: GetQuotaConsumedForUser :one
+-- GetQuotaConsumedForUser sums the daily cost of the latest build of each of
+-- the user's workspaces. When only_running is set, workspaces whose latest
+-- build is not a start are excluded so stopped workspaces consume no quota.
 WITH latest_builds AS (
 SELECT
 	DISTINCT ON
 	(workspace_id) id,
 	workspace_id,
+	transition,
 	daily_cost
 FROM
 	workspace_builds wb
@@ -27,4 +31,52 @@ FROM
 	workspaces
 JOIN latest_builds ON
 	latest_builds.workspace_id = workspaces.id
-WHERE NOT deleted AND workspaces.owner_id = $1;
+WHERE NOT deleted
+	AND workspaces.owner_id = @owner_id
+	AND CASE WHEN @only_running :: boolean THEN latest_builds.transition = 'start' ELSE true END;
+
+-- name: GetQuotaConsumedForUserIncludingPending :one
+-- GetQuotaConsumedForUserIncludingPending returns the quota a user would
+-- consume if a pending build with the given daily cost was committed, and
+-- whether that would exceed the user's allowance. only_running behaves as in
+-- GetQuotaConsumedForUser.
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	transition,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+),
+consumed AS (
+SELECT
+	coalesce(SUM(daily_cost), 0)::BIGINT AS amount
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+WHERE NOT deleted
+	AND workspaces.owner_id = @owner_id
+	AND CASE WHEN @only_running :: boolean THEN latest_builds.transition = 'start' ELSE true END
+),
+allowance AS (
+SELECT
+	coalesce(SUM(quota_allowance), 0)::BIGINT AS amount
+FROM
+	group_members gm
+JOIN groups g ON
+	g.id = gm.group_id
+WHERE
+	gm.user_id = @owner_id
+)
+SELECT
+	(consumed.amount + @pending_daily_cost :: integer)::BIGINT AS consumed,
+	allowance.amount AS allowance,
+	(consumed.amount + @pending_daily_cost :: integer) > allowance.amount AS exceeds_allowance
+FROM
+	consumed, allowance;
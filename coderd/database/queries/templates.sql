@@ -56,6 +56,13 @@ SELECT * FROM template_with_users AS templates
 ORDER BY (name, id) ASC
 ;
 
+-- name: GetDeletedTemplates :many
+-- GetDeletedTemplates returns soft-deleted templates, most recently deleted
+-- first.
+SELECT * FROM template_with_users AS templates
+WHERE deleted = true
+ORDER BY updated_at DESC, id ASC;
+
 -- name: InsertTemplate :exec
 INSERT INTO
 	templates (
@@ -72,10 +79,11 @@ INSERT INTO
 		user_acl,
 		group_acl,
 		display_name,
-		allow_user_cancel_workspace_jobs
+		allow_user_cancel_workspace_jobs,
+		provisioner_tags
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15);
 
 -- name: UpdateTemplateActiveVersionByID :exec
 UPDATE
@@ -122,7 +130,8 @@ SET
 	restart_requirement_weeks = $8,
 	failure_ttl = $9,
 	inactivity_ttl = $10,
-	locked_ttl = $11
+	locked_ttl = $11,
+	autobuild_paused = $12
 WHERE
 	id = $1
 ;
@@ -165,6 +174,10 @@ SELECT
 	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_50,
 	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_95,
 	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_95,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95,
+	-- The number of builds each percentile is computed from.
+	COUNT(*) FILTER (WHERE transition = 'start') AS start_count,
+	COUNT(*) FILTER (WHERE transition = 'stop') AS stop_count,
+	COUNT(*) FILTER (WHERE transition = 'delete') AS delete_count
 FROM build_times
 ;
@@ -68,6 +68,34 @@ FROM
 WHERE
 	id = ANY(@ids :: uuid [ ]);
 
+-- name: GetTemplateVersionsWithStatusByIDs :many
+-- Returns the template versions along with the status of their import job.
+-- The job_status values match codersdk.ProvisionerJobStatus and must be kept
+-- in sync with db2sdk.ProvisionerJobStatus.
+SELECT
+	sqlc.embed(template_versions),
+	(CASE
+		WHEN provisioner_jobs.canceled_at IS NOT NULL THEN
+			CASE
+				WHEN provisioner_jobs.completed_at IS NULL THEN 'canceling'
+				WHEN COALESCE(provisioner_jobs.error, '') = '' THEN 'canceled'
+				ELSE 'failed'
+			END
+		WHEN provisioner_jobs.started_at IS NULL THEN 'pending'
+		WHEN provisioner_jobs.completed_at IS NOT NULL THEN
+			CASE
+				WHEN COALESCE(provisioner_jobs.error, '') = '' THEN 'succeeded'
+				ELSE 'failed'
+			END
+		ELSE 'running'
+	END) :: text AS job_status
+FROM
+	template_version_with_user AS template_versions
+JOIN
+	provisioner_jobs ON provisioner_jobs.id = template_versions.job_id
+WHERE
+	template_versions.id = ANY(@ids :: uuid [ ]);
+
 -- name: InsertTemplateVersion :exec
 INSERT INTO
 	template_versions (
@@ -24,3 +24,21 @@ VALUES
 
 -- name: GetTemplateVersionVariables :many
 SELECT * FROM template_version_variables WHERE template_version_id = $1;
+
+-- name: GetTemplateVersionVariablesRedacted :many
+-- GetTemplateVersionVariablesRedacted returns the variables of a template
+-- version with the values of sensitive variables cleared, for contexts that
+-- must not see them.
+SELECT
+	template_version_id,
+	name,
+	description,
+	type,
+	(CASE WHEN sensitive THEN '' ELSE value END) :: text AS value,
+	(CASE WHEN sensitive THEN '' ELSE default_value END) :: text AS default_value,
+	required,
+	sensitive
+FROM
+	template_version_variables
+WHERE
+	template_version_id = $1;
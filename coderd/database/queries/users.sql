@@ -56,6 +56,15 @@ FROM
 WHERE
 	status = 'active'::user_status AND deleted = false;
 
+-- name: GetActiveUserCountSince :one
+SELECT
+	COUNT(*)
+FROM
+	users
+WHERE
+	status = 'active'::user_status AND deleted = false
+	AND last_seen_at > @since :: timestamptz;
+
 -- name: InsertUser :one
 INSERT INTO
 	users (
@@ -171,6 +180,37 @@ WHERE
 			last_seen_at >= @last_seen_after
 		ELSE true
 	END
+	-- Filter by created_at
+	AND CASE
+		WHEN @created_before :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at <= @created_before
+		ELSE true
+	END
+	AND CASE
+		WHEN @created_after :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at >= @created_after
+		ELSE true
+	END
+	-- Filter by login_type
+	AND CASE
+		WHEN cardinality(@login_type :: login_type[]) > 0 THEN
+			login_type = ANY(@login_type :: login_type[])
+		ELSE true
+	END
+	-- Filter by organization membership
+	AND CASE
+		WHEN @organization_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			EXISTS (
+				SELECT
+					1
+				FROM
+					organization_members
+				WHERE
+					organization_members.user_id = users.id
+					AND organization_members.organization_id = @organization_id
+			)
+		ELSE true
+	END
 	-- End of filters
 
 	-- Authorize Filter clause will be injected below in GetAuthorizedUsers
@@ -242,6 +282,48 @@ FROM
 WHERE
 	id = @user_id;
 
+-- name: GetAuthorizationUserRolesByIDs :many
+-- GetAuthorizationUserRolesByIDs is a batch variant of
+-- GetAuthorizationUserRoles, returning roles for every given user in a single
+-- query.
+SELECT
+	-- username is returned just to help for logging purposes
+	-- status is used to enforce 'suspended' users, as all roles are ignored
+	--	when suspended.
+	id, username, status,
+	-- All user roles, including their org roles.
+	array_cat(
+		-- All users are members
+		array_append(users.rbac_roles, 'member'),
+		(
+			SELECT
+				array_agg(org_roles)
+			FROM
+				organization_members,
+				-- All org_members get the org-member role for their orgs
+				unnest(
+					array_append(roles, 'organization-member:' || organization_members.organization_id::text)
+				) AS org_roles
+			WHERE
+				user_id = users.id
+		)
+	) :: text[] AS roles,
+	-- All groups the user is in.
+	(
+		SELECT
+			array_agg(
+				group_members.group_id :: text
+			)
+		FROM
+			group_members
+		WHERE
+			user_id = users.id
+	) :: text[] AS groups
+FROM
+	users
+WHERE
+	id = ANY(@ids :: uuid [ ]);
+
 -- name: UpdateUserQuietHoursSchedule :one
 UPDATE
 	users
@@ -5,6 +5,20 @@ FROM
 	workspace_agents
 WHERE
 	auth_token = $1
+	-- Agents of deleted workspaces must not be able to authenticate.
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_resources
+		INNER JOIN
+			workspace_builds ON workspace_builds.job_id = workspace_resources.job_id
+		INNER JOIN
+			workspaces ON workspaces.id = workspace_builds.workspace_id
+		WHERE
+			workspace_resources.id = workspace_agents.resource_id
+			AND workspaces.deleted
+	)
 ORDER BY
 	created_at DESC;
 
@@ -16,6 +30,29 @@ FROM
 WHERE
 	id = $1;
 
+-- GetWorkspaceAgentByIDExcludeDeletedWorkspace is like GetWorkspaceAgentByID,
+-- but returns no rows if the agent does not belong to a non-deleted workspace.
+-- name: GetWorkspaceAgentByIDExcludeDeletedWorkspace :one
+SELECT
+	*
+FROM
+	workspace_agents
+WHERE
+	id = $1
+	AND EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_resources
+		JOIN
+			workspace_builds ON workspace_builds.job_id = workspace_resources.job_id
+		JOIN
+			workspaces ON workspaces.id = workspace_builds.workspace_id
+		WHERE
+			workspace_resources.id = workspace_agents.resource_id
+			AND workspaces.deleted = false
+	);
+
 -- name: GetWorkspaceAgentByInstanceID :one
 SELECT
 	*
@@ -37,6 +74,26 @@ WHERE
 -- name: GetWorkspaceAgentsCreatedAfter :many
 SELECT * FROM workspace_agents WHERE created_at > $1;
 
+-- name: GetWorkspaceAgentsCreatedAfterWithWorkspace :many
+-- GetWorkspaceAgentsCreatedAfterWithWorkspace returns the agents created after
+-- the given time along with the workspace and template they belong to. Agents
+-- that aren't part of a workspace build, such as those created by template
+-- version imports, are omitted.
+SELECT
+	sqlc.embed(workspace_agents),
+	workspace_builds.workspace_id,
+	workspaces.template_id
+FROM
+	workspace_agents
+JOIN
+	workspace_resources ON workspace_agents.resource_id = workspace_resources.id
+JOIN
+	workspace_builds ON workspace_resources.job_id = workspace_builds.job_id
+JOIN
+	workspaces ON workspace_builds.workspace_id = workspaces.id
+WHERE
+	workspace_agents.created_at > $1;
+
 -- name: InsertWorkspaceAgent :one
 INSERT INTO
 	workspace_agents (
@@ -121,6 +178,24 @@ INSERT INTO
 VALUES
 	($1, $2, $3, $4, $5, $6);
 
+-- name: InsertWorkspaceAgentMetadataBatch :exec
+INSERT INTO
+	workspace_agent_metadata (
+		workspace_agent_id,
+		display_name,
+		key,
+		script,
+		timeout,
+		interval
+	)
+SELECT
+	@workspace_agent_id :: uuid AS workspace_agent_id,
+	unnest(@display_name :: text [ ]) AS display_name,
+	unnest(@key :: text [ ]) AS key,
+	unnest(@script :: text [ ]) AS script,
+	unnest(@timeout :: bigint [ ]) AS timeout,
+	unnest(@interval :: bigint [ ]) AS interval;
+
 -- name: UpdateWorkspaceAgentMetadata :exec
 UPDATE
 	workspace_agent_metadata
@@ -199,4 +274,10 @@ WHERE
 			workspace_builds AS wb
     	WHERE
 			wb.workspace_id = @workspace_id :: uuid
-	);
+	) AND
+	-- Optionally filter by the type of the resource the agent is on.
+	CASE
+		WHEN @resource_type :: text != '' THEN
+			workspace_resources.type = @resource_type
+		ELSE true
+	END;
@@ -86,6 +86,23 @@ WHERE
 	connection_count > 0
 GROUP BY
 	date, user_id
+ORDER BY
+	date ASC, user_id ASC;
+
+-- name: GetTemplateAndDeploymentDAUs :many
+-- GetTemplateAndDeploymentDAUs returns, per date, the number of users active
+-- on the given template alongside the number of users active in the whole
+-- deployment, so the template's share of daily users can be computed.
+SELECT
+	(created_at at TIME ZONE cast(@tz_offset::integer as text))::date as date,
+	COUNT(DISTINCT user_id) FILTER (WHERE template_id = @template_id)::bigint AS template_active_users,
+	COUNT(DISTINCT user_id)::bigint AS deployment_active_users
+FROM
+	workspace_agent_stats
+WHERE
+	connection_count > 0
+GROUP BY
+	date
 ORDER BY
 	date ASC;
 
@@ -98,7 +115,10 @@ WITH agent_stats AS (
 		coalesce(SUM(rx_bytes), 0)::bigint AS workspace_rx_bytes,
 		coalesce(SUM(tx_bytes), 0)::bigint AS workspace_tx_bytes,
 		coalesce((PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_50,
-		coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95
+		coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95,
+		-- has_data distinguishes "no stats" from real values, since the
+		-- latencies above fall back to -1.
+		(COUNT(*) > 0)::boolean AS has_data
 	 FROM workspace_agent_stats
 	 	-- The greater than 0 is to support legacy agents that don't report connection_median_latency_ms.
 		WHERE workspace_agent_stats.created_at > $1 AND connection_median_latency_ms > 0
@@ -129,7 +149,10 @@ WITH agent_stats AS (
 		coalesce((PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY connection_median_latency_ms)), -1)::FLOAT AS workspace_connection_latency_95
 	 FROM workspace_agent_stats
 	 	-- The greater than 0 is to support legacy agents that don't report connection_median_latency_ms.
-		WHERE workspace_agent_stats.created_at > $1 AND connection_median_latency_ms > 0 GROUP BY user_id, agent_id, workspace_id, template_id
+		WHERE workspace_agent_stats.created_at > @created_at AND connection_median_latency_ms > 0
+		-- Optionally ignore samples without any connections, like the DAU queries.
+		AND CASE WHEN @exclude_zero_connections :: boolean THEN connection_count > 0 ELSE true END
+		GROUP BY user_id, agent_id, workspace_id, template_id
 ), latest_agent_stats AS (
 	SELECT
 		a.agent_id,
@@ -139,10 +162,18 @@ WITH agent_stats AS (
 		coalesce(SUM(session_count_reconnecting_pty), 0)::bigint AS session_count_reconnecting_pty
 	 FROM (
 		SELECT *, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
-		FROM workspace_agent_stats WHERE created_at > $1
+		FROM workspace_agent_stats WHERE created_at > @created_at
+		AND CASE WHEN @exclude_zero_connections :: boolean THEN connection_count > 0 ELSE true END
 	) AS a WHERE a.rn = 1 GROUP BY a.user_id, a.agent_id, a.workspace_id, a.template_id
 )
-SELECT * FROM agent_stats JOIN latest_agent_stats ON agent_stats.agent_id = latest_agent_stats.agent_id;
+SELECT * FROM agent_stats JOIN latest_agent_stats ON agent_stats.agent_id = latest_agent_stats.agent_id
+WHERE
+	-- Optionally restrict aggregation to agents that are currently ready.
+	CASE
+		WHEN @ready_only :: boolean THEN
+			agent_stats.agent_id IN (SELECT id FROM workspace_agents WHERE lifecycle_state = 'ready')
+		ELSE true
+	END;
 
 -- name: GetWorkspaceAgentStatsAndLabels :many
 WITH agent_stats AS (
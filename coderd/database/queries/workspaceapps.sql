@@ -1,14 +1,123 @@
 -- name: GetWorkspaceAppsByAgentID :many
-SELECT * FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC;
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	agent_id = $1
+ORDER BY
+	slug ASC;
 
 -- name: GetWorkspaceAppsByAgentIDs :many
-SELECT * FROM workspace_apps WHERE agent_id = ANY(@ids :: uuid [ ]) ORDER BY slug ASC;
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	agent_id = ANY(@ids :: uuid [ ])
+ORDER BY
+	slug ASC;
 
 -- name: GetWorkspaceAppByAgentIDAndSlug :one
-SELECT * FROM workspace_apps WHERE agent_id = $1 AND slug = $2;
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	agent_id = @agent_id
+	AND slug = @slug
+	-- External apps are opened by the client and must never be proxied.
+	AND (NOT @exclude_external :: boolean OR NOT external);
 
 -- name: GetWorkspaceAppsCreatedAfter :many
-SELECT * FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC;
+SELECT
+	id,
+	created_at,
+	agent_id,
+	display_name,
+	icon,
+	command,
+	url,
+	healthcheck_url,
+	healthcheck_interval,
+	healthcheck_threshold,
+	-- Keep in sync with mapAppHealth in dbfake.
+	CASE
+		WHEN healthcheck_url = '' THEN 'disabled' :: workspace_app_health
+		-- The agent had enough time to report a healthcheck result but never did.
+		WHEN health = 'initializing' AND created_at + (healthcheck_interval * healthcheck_threshold) * INTERVAL '1 second' <= NOW() THEN 'unhealthy' :: workspace_app_health
+		ELSE health
+	END AS health,
+	subdomain,
+	sharing_level,
+	slug,
+	external
+FROM
+	workspace_apps
+WHERE
+	created_at > $1
+ORDER BY
+	slug ASC;
 
 -- name: InsertWorkspaceApp :one
 INSERT INTO
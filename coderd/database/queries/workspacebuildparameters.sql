@@ -14,3 +14,20 @@ FROM
     workspace_build_parameters
 WHERE
     workspace_build_id = $1;
+
+-- name: GetWorkspaceBuildParametersByBuildIDs :many
+SELECT
+    *
+FROM
+    workspace_build_parameters
+WHERE
+    workspace_build_id = ANY(@ids :: uuid [ ]);
+
+-- name: GetWorkspaceBuildParameterByName :one
+SELECT
+    *
+FROM
+    workspace_build_parameters
+WHERE
+    workspace_build_id = @workspace_build_id
+    AND name = @name;
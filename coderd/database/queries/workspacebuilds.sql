@@ -8,6 +8,19 @@ WHERE
 LIMIT
 	1;
 
+-- name: GetWorkspaceBuildWithTemplateVersionByID :one
+SELECT
+	sqlc.embed(workspace_builds),
+	template_versions.name AS template_version_name
+FROM
+	workspace_build_with_user AS workspace_builds
+JOIN
+	template_versions ON template_versions.id = workspace_builds.template_version_id
+WHERE
+	workspace_builds.id = $1
+LIMIT
+	1;
+
 -- name: GetWorkspaceBuildByJobID :one
 SELECT
 	*
@@ -19,7 +32,24 @@ LIMIT
 	1;
 
 -- name: GetWorkspaceBuildsCreatedAfter :many
-SELECT * FROM workspace_build_with_user WHERE created_at > $1;
+SELECT
+	*
+FROM
+	workspace_build_with_user
+WHERE
+	created_at > @created_at
+	-- Optionally filter by transition
+	AND CASE
+		WHEN @transition :: text != '' THEN
+			transition :: text = @transition
+		ELSE true
+	END
+	-- Optionally filter by build reason
+	AND CASE
+		WHEN @reason :: text != '' THEN
+			reason :: text = @reason
+		ELSE true
+	END;
 
 -- name: GetWorkspaceBuildByWorkspaceIDAndBuildNumber :one
 SELECT
@@ -57,6 +87,12 @@ WHERE
 		)
 		ELSE true
 END
+	-- Filter by the username of the user that started the build
+	AND CASE
+		WHEN @initiator_username :: text != '' THEN
+			LOWER(workspace_builds.initiator_by_username) = LOWER(@initiator_username)
+		ELSE true
+	END
 ORDER BY
     build_number desc OFFSET @offset_opt
 LIMIT
@@ -71,7 +107,10 @@ FROM
 WHERE
 	workspace_id = $1
 ORDER BY
-    build_number desc
+    build_number desc,
+    -- Break ties deterministically should build numbers ever collide.
+    created_at desc,
+    id desc
 LIMIT
 	1;
 
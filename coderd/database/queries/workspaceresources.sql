@@ -12,7 +12,10 @@ SELECT
 FROM
 	workspace_resources
 WHERE
-	job_id = $1;
+	job_id = $1
+ORDER BY
+	type ASC,
+	name ASC;
 
 -- name: GetWorkspaceResourcesByJobIDs :many
 SELECT
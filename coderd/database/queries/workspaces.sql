@@ -270,6 +270,17 @@ WHERE
 	-- Authorize Filter clause will be injected below in GetAuthorizedWorkspaces
 	-- @authorize_filter
 ORDER BY
+	-- Optionally group workspaces by their template name. Supported values
+	-- of sort_by are '' (the default ordering) and 'template'. Keep in sync
+	-- with database.WorkspaceSortBy.
+	CASE
+		WHEN @sort_by :: text = 'template' THEN
+			LOWER(COALESCE(template_name.template_name, 'unknown'))
+	END ASC,
+	CASE
+		WHEN @sort_by = 'template' THEN
+			LOWER(workspaces.name)
+	END ASC,
 	(latest_build.completed_at IS NOT NULL AND
 		latest_build.canceled_at IS NULL AND
 		latest_build.error IS NULL AND
@@ -285,6 +296,15 @@ OFFSET
 	@offset_
 ;
 
+-- name: GetWorkspaceCountByOwner :one
+SELECT
+	COUNT(*)
+FROM
+	workspaces
+WHERE
+	owner_id = @owner_id
+	AND deleted = false;
+
 -- name: GetWorkspaceByOwnerIDAndName :one
 SELECT
 	*
@@ -292,7 +312,9 @@ FROM
 	workspaces
 WHERE
 	owner_id = @owner_id
-	AND deleted = @deleted
+	-- Optionally ignore the deleted flag and return the newest workspace
+	-- with the given name regardless of its state.
+	AND (@include_deleted :: boolean OR deleted = @deleted)
 	AND LOWER("name") = LOWER(@name)
 ORDER BY created_at DESC;
 
@@ -389,7 +411,9 @@ WITH workspaces_with_jobs AS (
 		started_at IS NOT NULL AND
 		canceled_at IS NULL AND
 		completed_at IS NULL AND
-		updated_at - INTERVAL '30 seconds' < NOW()
+		-- Jobs that haven't been updated within the building window are
+		-- not considered to be building. A zero window defaults to 30 seconds.
+		updated_at >= NOW() - COALESCE(NULLIF(@building_window_seconds :: int, 0), 30) * INTERVAL '1 second'
 ), running_workspaces AS (
 	SELECT COUNT(*) AS count FROM workspaces_with_jobs WHERE
 		completed_at IS NOT NULL AND
@@ -480,7 +504,11 @@ WHERE
 			templates.locked_ttl > 0 AND
 			workspaces.locked_at IS NOT NULL
 		)
-	) AND workspaces.deleted = 'false';
+	) AND
+	-- Workspaces whose latest build is still pending or running are not
+	-- eligible, otherwise we'd attempt a conflicting build.
+	provisioner_jobs.completed_at IS NOT NULL AND
+	workspaces.deleted = 'false';
 
 -- name: UpdateWorkspaceLockedDeletingAt :one
 UPDATE
@@ -3,6 +3,7 @@ package coderd
 import (
 	"context"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/coder/coder/coderd/healthcheck"
@@ -64,6 +65,41 @@ func (api *API) debugDeploymentHealth(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// @Summary Debug Info Git Auth Health
+// @ID debug-info-git-auth-health
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Debug
+// @Success 200 {array} codersdk.GitAuthHealth
+// @Router /debug/gitauth [get]
+func (api *API) debugGitAuthHealth(rw http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), api.HealthcheckTimeout)
+	defer cancel()
+
+	health := make([]codersdk.GitAuthHealth, len(api.GitAuthConfigs))
+	var wg sync.WaitGroup
+	for i, config := range api.GitAuthConfigs {
+		i, config := i, config
+		health[i] = codersdk.GitAuthHealth{
+			ID:   config.ID,
+			Type: string(config.Type),
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := config.CheckReachable(ctx)
+			if err != nil {
+				health[i].Error = err.Error()
+				return
+			}
+			health[i].Reachable = true
+		}()
+	}
+	wg.Wait()
+
+	httpapi.Write(ctx, rw, http.StatusOK, health)
+}
+
 // For some reason the swagger docs need to be attached to a function.
 //
 // @Summary Debug Info Websocket Test
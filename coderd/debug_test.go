@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -11,7 +12,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/gitauth"
 	"github.com/coder/coder/coderd/healthcheck"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
 
@@ -115,3 +118,43 @@ func TestDebugWebsocket(t *testing.T) {
 		t.Parallel()
 	})
 }
+
+func TestDebugGitAuthHealth(t *testing.T) {
+	t.Parallel()
+
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	defer reachable.Close()
+	// Closing the server immediately leaves a URL that refuses connections.
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		GitAuthConfigs: []*gitauth.Config{{
+			ID:           "reachable",
+			OAuth2Config: &testutil.OAuth2Config{},
+			ValidateURL:  reachable.URL,
+			Type:         codersdk.GitProviderGitHub,
+		}, {
+			ID:           "unreachable",
+			OAuth2Config: &testutil.OAuth2Config{},
+			ValidateURL:  unreachable.URL,
+			Type:         codersdk.GitProviderGitLab,
+		}},
+	})
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	health, err := client.DebugGitAuthHealth(ctx)
+	require.NoError(t, err)
+	require.Len(t, health, 2)
+	require.Equal(t, "reachable", health[0].ID)
+	require.True(t, health[0].Reachable)
+	require.Empty(t, health[0].Error)
+	require.Equal(t, "unreachable", health[1].ID)
+	require.False(t, health[1].Reachable)
+	require.NotEmpty(t, health[1].Error)
+}
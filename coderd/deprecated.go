@@ -1,19 +1,56 @@
 package coderd
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
 )
 
+// writeDeprecationHeaders marks the response as coming from a deprecated
+// endpoint so clients can surface it, pointing them at the replacement.
+// No Sunset header is sent as removal of these endpoints isn't scheduled.
+func writeDeprecationHeaders(rw http.ResponseWriter, replacement string) {
+	rw.Header().Set("Deprecation", "true")
+	rw.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", replacement))
+	rw.Header().Set("Warning", fmt.Sprintf("299 - \"Deprecated API: use %s instead\"", replacement))
+}
+
+// redirectToRichParameters redirects clients that opted in with the "redirect"
+// query parameter to the rich parameters of the template version, instead of
+// returning the empty response old clients expect. It reports whether the
+// response was written.
+func redirectToRichParameters(rw http.ResponseWriter, r *http.Request) bool {
+	redirect, _ := strconv.ParseBool(r.URL.Query().Get("redirect"))
+	if !redirect {
+		return false
+	}
+	http.Redirect(rw, r, templateVersionRichParametersPath(r), http.StatusPermanentRedirect)
+	return true
+}
+
+// templateVersionRichParametersPath is the path of the rich parameters of the
+// template version in the request.
+func templateVersionRichParametersPath(r *http.Request) string {
+	return fmt.Sprintf("/api/v2/templateversions/%s/rich-parameters", httpmw.TemplateVersionParam(r).ID)
+}
+
 // @Summary Removed: Get parameters by template version
 // @ID removed-get-parameters-by-template-version
 // @Security CoderSessionToken
 // @Tags Templates
 // @Param templateversion path string true "Template version ID" format(uuid)
+// @Param redirect query bool false "Redirect to the rich parameters endpoint"
 // @Success 200
+// @Success 308
 // @Router /templateversions/{templateversion}/parameters [get]
 func templateVersionParametersDeprecated(rw http.ResponseWriter, r *http.Request) {
+	writeDeprecationHeaders(rw, templateVersionRichParametersPath(r))
+	if redirectToRichParameters(rw, r) {
+		return
+	}
 	httpapi.Write(r.Context(), rw, http.StatusOK, []struct{}{})
 }
 
@@ -22,9 +59,15 @@ func templateVersionParametersDeprecated(rw http.ResponseWriter, r *http.Request
 // @Security CoderSessionToken
 // @Tags Templates
 // @Param templateversion path string true "Template version ID" format(uuid)
+// @Param redirect query bool false "Redirect to the rich parameters endpoint"
 // @Success 200
+// @Success 308
 // @Router /templateversions/{templateversion}/schema [get]
 func templateVersionSchemaDeprecated(rw http.ResponseWriter, r *http.Request) {
+	writeDeprecationHeaders(rw, templateVersionRichParametersPath(r))
+	if redirectToRichParameters(rw, r) {
+		return
+	}
 	httpapi.Write(r.Context(), rw, http.StatusOK, []struct{}{})
 }
 
@@ -38,6 +81,7 @@ func templateVersionSchemaDeprecated(rw http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} codersdk.Response
 // @Router /workspaceagents/me/startup-logs [patch]
 func (api *API) patchWorkspaceAgentLogsDeprecated(rw http.ResponseWriter, r *http.Request) {
+	writeDeprecationHeaders(rw, "/api/v2/workspaceagents/me/logs")
 	api.patchWorkspaceAgentLogs(rw, r)
 }
 
@@ -54,5 +98,6 @@ func (api *API) patchWorkspaceAgentLogsDeprecated(rw http.ResponseWriter, r *htt
 // @Success 200 {array} codersdk.WorkspaceAgentLog
 // @Router /workspaceagents/{workspaceagent}/startup-logs [get]
 func (api *API) workspaceAgentLogsDeprecated(rw http.ResponseWriter, r *http.Request) {
+	writeDeprecationHeaders(rw, fmt.Sprintf("/api/v2/workspaceagents/%s/logs", httpmw.WorkspaceAgentParam(r).ID))
 	api.workspaceAgentLogs(rw, r)
 }
@@ -0,0 +1,153 @@
+package coderd_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/testutil"
+)
+
+func TestDeprecatedEndpointHeaders(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionPlan:  echo.ProvisionComplete,
+		ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	agentID := build.Resources[0].Agents[0].ID
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		path        string
+		body        interface{}
+		agent       bool
+		replacement string
+	}{
+		{
+			name:        "TemplateVersionSchema",
+			method:      http.MethodGet,
+			path:        fmt.Sprintf("/api/v2/templateversions/%s/schema", version.ID),
+			replacement: fmt.Sprintf("/api/v2/templateversions/%s/rich-parameters", version.ID),
+		},
+		{
+			name:        "TemplateVersionParameters",
+			method:      http.MethodGet,
+			path:        fmt.Sprintf("/api/v2/templateversions/%s/parameters", version.ID),
+			replacement: fmt.Sprintf("/api/v2/templateversions/%s/rich-parameters", version.ID),
+		},
+		{
+			name:   "PatchWorkspaceAgentLogs",
+			method: http.MethodPatch,
+			path:   "/api/v2/workspaceagents/me/startup-logs",
+			body: agentsdk.PatchLogs{
+				Logs: []agentsdk.Log{{CreatedAt: database.Now(), Output: "testing"}},
+			},
+			agent:       true,
+			replacement: "/api/v2/workspaceagents/me/logs",
+		},
+		{
+			name:        "WorkspaceAgentLogs",
+			method:      http.MethodGet,
+			path:        fmt.Sprintf("/api/v2/workspaceagents/%s/startup-logs", agentID),
+			replacement: fmt.Sprintf("/api/v2/workspaceagents/%s/logs", agentID),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			requester := client
+			if tc.agent {
+				requester = agentClient.SDK
+			}
+			res, err := requester.Request(ctx, tc.method, tc.path, tc.body)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, "true", res.Header.Get("Deprecation"))
+			require.Contains(t, res.Header.Get("Link"), tc.replacement)
+			require.Contains(t, res.Header.Get("Warning"), tc.replacement)
+		})
+	}
+}
+
+func TestDeprecatedTemplateVersionParametersRedirect(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+	for _, endpoint := range []string{"parameters", "schema"} {
+		endpoint := endpoint
+		t.Run(endpoint, func(t *testing.T) {
+			t.Parallel()
+
+			path := fmt.Sprintf("/api/v2/templateversions/%s/%s", version.ID, endpoint)
+
+			t.Run("Empty", func(t *testing.T) {
+				t.Parallel()
+
+				res, err := client.Request(ctx, http.MethodGet, path, nil)
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				var body []struct{}
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+				require.Empty(t, body)
+			})
+
+			t.Run("Redirect", func(t *testing.T) {
+				t.Parallel()
+
+				res, err := client.Request(ctx, http.MethodGet, path+"?redirect=true", nil)
+				require.NoError(t, err)
+				defer res.Body.Close()
+				// The SDK follows redirects, so the response comes from the
+				// rich parameters endpoint.
+				require.Equal(t, http.StatusOK, res.StatusCode)
+				require.Equal(t, fmt.Sprintf("/api/v2/templateversions/%s/rich-parameters", version.ID), res.Request.URL.Path)
+
+				// Clients that don't follow redirects see where to go.
+				httpClient := &http.Client{
+					CheckRedirect: func(*http.Request, []*http.Request) error {
+						return http.ErrUseLastResponse
+					},
+				}
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.URL.String()+path+"?redirect=true", nil)
+				require.NoError(t, err)
+				req.Header.Set(codersdk.SessionTokenHeader, client.SessionToken())
+				res, err = httpClient.Do(req)
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusPermanentRedirect, res.StatusCode)
+				require.Equal(t, fmt.Sprintf("/api/v2/templateversions/%s/rich-parameters", version.ID), res.Header.Get("Location"))
+			})
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"golang.org/x/sync/errgroup"
 
@@ -101,6 +102,11 @@ func (api *API) postGitAuthDeviceByID(rw http.ResponseWriter, r *http.Request) {
 
 	token, err := config.DeviceAuth.ExchangeDeviceCode(ctx, req.DeviceCode)
 	if err != nil {
+		var exchangeErr *gitauth.DeviceExchangeError
+		if errors.As(err, &exchangeErr) && exchangeErr.Interval > 0 {
+			// Pass along the polling interval the provider asked for.
+			rw.Header().Set("Retry-After", strconv.Itoa(exchangeErr.Interval))
+		}
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "Failed to exchange device code.",
 			Detail:  err.Error(),
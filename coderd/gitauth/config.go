@@ -145,6 +145,42 @@ func (c *Config) ValidateToken(ctx context.Context, token string) (bool, *coders
 	return true, user, nil
 }
 
+// CheckReachable sends a HEAD request to the token and validate URLs of the
+// provider. Any HTTP response counts as reachable, since these endpoints
+// commonly reject unauthenticated requests.
+func (c *Config) CheckReachable(ctx context.Context) error {
+	urls := []string{}
+	if tokenURL := c.tokenURL(); tokenURL != "" {
+		urls = append(urls, tokenURL)
+	}
+	if c.ValidateURL != "" {
+		urls = append(urls, c.ValidateURL)
+	}
+	for _, u := range urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+		if err != nil {
+			return xerrors.Errorf("create request for %q: %w", u, err)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return xerrors.Errorf("reach %q: %w", u, err)
+		}
+		_ = res.Body.Close()
+	}
+	return nil
+}
+
+// tokenURL returns the OAuth2 token endpoint if it's known.
+func (c *Config) tokenURL() string {
+	switch oc := c.OAuth2Config.(type) {
+	case *oauth2.Config:
+		return oc.Endpoint.TokenURL
+	case *jwtConfig:
+		return oc.Endpoint.TokenURL
+	}
+	return ""
+}
+
 type AppInstallation struct {
 	ID int
 	// Login is the username of the installation.
@@ -209,6 +245,16 @@ func ConvertConfig(entries []codersdk.GitAuthConfig, accessURL *url.URL) ([]*Con
 	ids := map[string]struct{}{}
 	configs := []*Config{}
 	for _, entry := range entries {
+		if entry.Type == "" && entry.Regex != "" {
+			// Infer the type for well-known hosts so it doesn't need to be
+			// declared explicitly. A regex that only matches paths on the
+			// host still has to declare its type.
+			compiled, err := regexp.Compile(entry.Regex)
+			if err != nil {
+				return nil, xerrors.Errorf("compile regex for git auth provider %q: %w", entry.ID, err)
+			}
+			entry.Type = string(inferType(compiled))
+		}
 		var typ codersdk.GitProvider
 		switch codersdk.GitProvider(entry.Type) {
 		case codersdk.GitProviderAzureDevops:
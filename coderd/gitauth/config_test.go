@@ -198,4 +198,37 @@ func TestConvertYAML(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "https://auth.com?client_id=id&redirect_uri=%2Fgitauth%2Fgitlab%2Fcallback&response_type=code&scope=read", config[0].AuthCodeURL(""))
 	})
+	t.Run("InferTypeFromRegex", func(t *testing.T) {
+		t.Parallel()
+		config, err := gitauth.ConvertConfig([]codersdk.GitAuthConfig{{
+			ClientID:     "id",
+			ClientSecret: "secret",
+			Regex:        `^https://gitlab\.com(/.*)?$`,
+		}}, &url.URL{})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.GitProviderGitLab, config[0].Type)
+		require.Equal(t, "gitlab", config[0].ID)
+	})
+	t.Run("InferTypeUnknownHost", func(t *testing.T) {
+		t.Parallel()
+		_, err := gitauth.ConvertConfig([]codersdk.GitAuthConfig{{
+			ClientID:     "id",
+			ClientSecret: "secret",
+			Regex:        `^https://git\.example\.com(/.*)?$`,
+		}}, &url.URL{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown git provider type")
+	})
+	t.Run("InferTypeAmbiguous", func(t *testing.T) {
+		t.Parallel()
+		for _, re := range []string{`.*`, `.*\.com`} {
+			_, err := gitauth.ConvertConfig([]codersdk.GitAuthConfig{{
+				ClientID:     "id",
+				ClientSecret: "secret",
+				Regex:        re,
+			}}, &url.URL{})
+			require.Error(t, err, re)
+			require.Contains(t, err.Error(), "unknown git provider type", re)
+		}
+	})
 }
@@ -66,6 +66,34 @@ var regex = map[codersdk.GitProvider]*regexp.Regexp{
 	codersdk.GitProviderGitHub:      regexp.MustCompile(`^(https?://)?github\.com(/.*)?$`),
 }
 
+// hostType maps the canonical URL of well-known Git hosts to
+// their provider type.
+var hostType = []struct {
+	URL  string
+	Type codersdk.GitProvider
+}{
+	{URL: "https://github.com/", Type: codersdk.GitProviderGitHub},
+	{URL: "https://gitlab.com/", Type: codersdk.GitProviderGitLab},
+	{URL: "https://dev.azure.com/", Type: codersdk.GitProviderAzureDevops},
+}
+
+// inferType returns the provider type of the well-known host whose canonical
+// URL is matched by the regex. Permissive regexes that match several hosts
+// are ambiguous, so an empty type is returned unless exactly one matches.
+func inferType(re *regexp.Regexp) codersdk.GitProvider {
+	var typ codersdk.GitProvider
+	for _, known := range hostType {
+		if !re.MatchString(known.URL) {
+			continue
+		}
+		if typ != "" {
+			return ""
+		}
+		typ = known.Type
+	}
+	return typ
+}
+
 // jwtConfig is a new OAuth2 config that uses a custom
 // assertion method that works with Azure Devops. See:
 // https://learn.microsoft.com/en-us/azure/devops/integrate/get-started/authentication/oauth?view=azure-devops
@@ -145,6 +173,23 @@ type ExchangeDeviceCodeResponse struct {
 	ExpiresIn        int    `json:"expires_in"`
 	Error            string `json:"error"`
 	ErrorDescription string `json:"error_description"`
+	// Interval is sent alongside a slow_down error by some providers
+	// to indicate the new polling interval in seconds.
+	Interval int `json:"interval"`
+}
+
+// DeviceExchangeError is returned when a provider rejects a device code
+// exchange, e.g. with authorization_pending or slow_down.
+// See: https://tools.ietf.org/html/rfc8628#section-3.5
+type DeviceExchangeError struct {
+	Code string
+	// Interval is the polling interval in seconds requested by the
+	// provider. It's zero if the provider didn't specify one.
+	Interval int
+}
+
+func (e *DeviceExchangeError) Error() string {
+	return e.Code
 }
 
 // ExchangeDeviceCode exchanges a device code for an access token.
@@ -177,7 +222,10 @@ func (c *DeviceAuth) ExchangeDeviceCode(ctx context.Context, deviceCode string)
 		return nil, err
 	}
 	if body.Error != "" {
-		return nil, xerrors.New(body.Error)
+		return nil, &DeviceExchangeError{
+			Code:     body.Error,
+			Interval: body.Interval,
+		}
 	}
 	return &oauth2.Token{
 		AccessToken:  body.AccessToken,
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -88,6 +90,39 @@ func TestGitAuthByID(t *testing.T) {
 		require.NotNil(t, auth.User)
 		require.Equal(t, "kyle", auth.User.Login)
 	})
+	t.Run("AuthenticatedWithInferredType", func(t *testing.T) {
+		t.Parallel()
+		validateSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			httpapi.Write(r.Context(), w, http.StatusOK, github.User{
+				Login:     github.String("kyle"),
+				AvatarURL: github.String("https://avatars.githubusercontent.com/u/12345678?v=4"),
+			})
+		}))
+		defer validateSrv.Close()
+		// No type is set, so it's inferred from github.com.
+		configs, err := gitauth.ConvertConfig([]codersdk.GitAuthConfig{{
+			ID:          "test",
+			ClientID:    "id",
+			Regex:       `^https://github\.com(/.*)?$`,
+			ValidateURL: validateSrv.URL,
+		}}, &url.URL{})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.GitProviderGitHub, configs[0].Type)
+		configs[0].OAuth2Config = &testutil.OAuth2Config{}
+		// Don't reach out to the real GitHub for app installations.
+		configs[0].AppInstallationsURL = ""
+		client := coderdtest.New(t, &coderdtest.Options{
+			GitAuthConfigs: configs,
+		})
+		coderdtest.CreateFirstUser(t, client)
+		resp := coderdtest.RequestGitAuthCallback(t, "test", client)
+		_ = resp.Body.Close()
+		auth, err := client.GitAuthByID(context.Background(), "test")
+		require.NoError(t, err)
+		require.True(t, auth.Authenticated)
+		require.NotNil(t, auth.User)
+		require.Equal(t, "kyle", auth.User.Login)
+	})
 	t.Run("AuthenticatedWithInstalls", func(t *testing.T) {
 		t.Parallel()
 		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -212,6 +247,59 @@ func TestGitAuthDevice(t *testing.T) {
 		require.NoError(t, err)
 		require.True(t, auth.Authenticated)
 	})
+	t.Run("ExchangeCodeSlowDown", func(t *testing.T) {
+		t.Parallel()
+		var (
+			mu       sync.Mutex
+			attempts []time.Time
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			attempts = append(attempts, time.Now())
+			first := len(attempts) == 1
+			mu.Unlock()
+			if first {
+				httpapi.Write(r.Context(), w, http.StatusOK, gitauth.ExchangeDeviceCodeResponse{
+					Error:    "slow_down",
+					Interval: 2,
+				})
+				return
+			}
+			httpapi.Write(r.Context(), w, http.StatusOK, gitauth.ExchangeDeviceCodeResponse{
+				AccessToken: "hey",
+			})
+		}))
+		defer srv.Close()
+		client := coderdtest.New(t, &coderdtest.Options{
+			GitAuthConfigs: []*gitauth.Config{{
+				ID: "test",
+				DeviceAuth: &gitauth.DeviceAuth{
+					ClientID: "test",
+					TokenURL: srv.URL,
+					Scopes:   []string{"repo"},
+				},
+			}},
+		})
+		coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		err := client.GitAuthDeviceExchangeWait(ctx, "test", codersdk.GitAuthDevice{
+			DeviceCode: "hey",
+			Interval:   1,
+		})
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, attempts, 2)
+		// The client must wait for the increased interval after slow_down.
+		require.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), 2*time.Second)
+
+		auth, err := client.GitAuthByID(ctx, "test")
+		require.NoError(t, err)
+		require.True(t, auth.Authenticated)
+	})
 }
 
 // nolint:bodyclose
@@ -461,7 +549,7 @@ func TestGitAuthCallback(t *testing.T) {
 		// Start waiting for the token callback...
 		tokenChan := make(chan agentsdk.GitAuthResponse, 1)
 		go func() {
-			token, err := agentClient.GitAuth(context.Background(), "github.com/asd/asd", true)
+			token, err := agentClient.WaitForGitAuth(context.Background(), "github.com/asd/asd", testutil.WaitLong)
 			assert.NoError(t, err)
 			tokenChan <- token
 		}()
@@ -20,6 +20,13 @@ func RequestID(r *http.Request) uuid.UUID {
 	return rid
 }
 
+// RequestIDOptional returns the ID of the request if the request ID middleware
+// was provided.
+func RequestIDOptional(r *http.Request) (uuid.UUID, bool) {
+	rid, ok := r.Context().Value(requestIDContextKey{}).(uuid.UUID)
+	return rid, ok
+}
+
 // AttachRequestID adds a request ID to each HTTP request.
 func AttachRequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -255,7 +255,7 @@ func (c *Cache) refreshDeploymentStats(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	workspaceStats, err := c.database.GetDeploymentWorkspaceStats(ctx)
+	workspaceStats, err := c.database.GetDeploymentWorkspaceStats(ctx, 0)
 	if err != nil {
 		return err
 	}
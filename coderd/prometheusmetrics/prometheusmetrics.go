@@ -247,7 +247,9 @@ func Agents(ctx context.Context, logger slog.Logger, registerer prometheus.Regis
 					continue
 				}
 
-				agents, err := db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, workspace.ID)
+				agents, err := db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams{
+					WorkspaceID: workspace.ID,
+				})
 				if err != nil {
 					logger.Error(ctx, "can't get workspace agents", slog.F("workspace_id", workspace.ID), slog.Error(err))
 					agentsGauge.WithLabelValues(VectorOperationAdd, 0, user.Username, workspace.Name, templateName, templateVersionName)
@@ -95,6 +95,17 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		return &proto.AcquiredJob{}, nil
 	}
 	lastAcquireMutex.RUnlock()
+	// Record that the daemon is still alive so stale daemons can be detected.
+	err := server.Database.UpdateProvisionerDaemonLastSeenAt(ctx, database.UpdateProvisionerDaemonLastSeenAtParams{
+		ID: server.ID,
+		LastSeenAt: sql.NullTime{
+			Time:  database.Now(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		server.Logger.Warn(ctx, "update provisioner daemon last seen", slog.Error(err))
+	}
 	// This marks the job as locked in the database.
 	job, err := server.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
 		StartedAt: sql.NullTime{
@@ -1218,16 +1229,18 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 		}
 		snapshot.WorkspaceAgents = append(snapshot.WorkspaceAgents, telemetry.ConvertWorkspaceAgent(dbAgent))
 
-		for _, md := range prAgent.Metadata {
-			p := database.InsertWorkspaceAgentMetadataParams{
+		if len(prAgent.Metadata) > 0 {
+			p := database.InsertWorkspaceAgentMetadataBatchParams{
 				WorkspaceAgentID: agentID,
-				DisplayName:      md.DisplayName,
-				Script:           md.Script,
-				Key:              md.Key,
-				Timeout:          md.Timeout,
-				Interval:         md.Interval,
 			}
-			err := db.InsertWorkspaceAgentMetadata(ctx, p)
+			for _, md := range prAgent.Metadata {
+				p.DisplayName = append(p.DisplayName, md.DisplayName)
+				p.Script = append(p.Script, md.Script)
+				p.Key = append(p.Key, md.Key)
+				p.Timeout = append(p.Timeout, md.Timeout)
+				p.Interval = append(p.Interval, md.Interval)
+			}
+			err := db.InsertWorkspaceAgentMetadataBatch(ctx, p)
 			if err != nil {
 				return xerrors.Errorf("insert agent metadata: %w, params: %+v", err, p)
 			}
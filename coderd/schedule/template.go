@@ -104,6 +104,9 @@ type TemplateScheduleOptions struct {
 	// LockedTTL dictates the duration after which locked workspaces will be
 	// permanently deleted.
 	LockedTTL time.Duration `json:"locked_ttl"`
+	// AutobuildPaused stops all autostart and autostop transitions for
+	// workspaces of the template without editing their schedules.
+	AutobuildPaused bool `json:"autobuild_paused"`
 }
 
 // TemplateScheduleStore provides an interface for retrieving template
@@ -141,14 +144,16 @@ func (*agplTemplateScheduleStore) Get(ctx context.Context, db database.Store, te
 			DaysOfWeek: 0,
 			Weeks:      0,
 		},
-		FailureTTL:    0,
-		InactivityTTL: 0,
-		LockedTTL:     0,
+		FailureTTL:      0,
+		InactivityTTL:   0,
+		LockedTTL:       0,
+		AutobuildPaused: tpl.AutobuildPaused,
 	}, nil
 }
 
 func (*agplTemplateScheduleStore) Set(ctx context.Context, db database.Store, tpl database.Template, opts TemplateScheduleOptions) (database.Template, error) {
-	if int64(opts.DefaultTTL) == tpl.DefaultTTL {
+	if int64(opts.DefaultTTL) == tpl.DefaultTTL &&
+		opts.AutobuildPaused == tpl.AutobuildPaused {
 		// Avoid updating the UpdatedAt timestamp if nothing will be changed.
 		return tpl, nil
 	}
@@ -169,6 +174,7 @@ func (*agplTemplateScheduleStore) Set(ctx context.Context, db database.Store, tp
 			FailureTTL:                   tpl.FailureTTL,
 			InactivityTTL:                tpl.InactivityTTL,
 			LockedTTL:                    tpl.LockedTTL,
+			AutobuildPaused:              opts.AutobuildPaused,
 		})
 		if err != nil {
 			return xerrors.Errorf("update template schedule: %w", err)
@@ -117,6 +117,13 @@ func TestSearchWorkspace(t *testing.T) {
 				OwnerUsername: "foo",
 			},
 		},
+		{
+			Name:  "SortByTemplate",
+			Query: `sort_by:template`,
+			Expected: database.GetWorkspacesParams{
+				SortBy: string(database.WorkspaceSortByTemplate),
+			},
+		},
 
 		// Failures
 		{
@@ -144,6 +151,11 @@ func TestSearchWorkspace(t *testing.T) {
 			Query:                 `foo:bar`,
 			ExpectedErrorContains: `Query param "foo" is not a valid query param`,
 		},
+		{
+			Name:                  "InvalidSortBy",
+			Query:                 `sort_by:owner`,
+			ExpectedErrorContains: "not a valid value",
+		},
 	}
 
 	for _, c := range testCases {
@@ -309,7 +309,9 @@ func (r *remoteReporter) createSnapshot() (*Snapshot, error) {
 		return nil
 	})
 	eg.Go(func() error {
-		jobs, err := r.options.Database.GetProvisionerJobsCreatedAfter(ctx, createdAfter)
+		jobs, err := r.options.Database.GetProvisionerJobsCreatedAfter(ctx, database.GetProvisionerJobsCreatedAfterParams{
+			CreatedAt: createdAfter,
+		})
 		if err != nil {
 			return xerrors.Errorf("get provisioner jobs: %w", err)
 		}
@@ -406,7 +408,9 @@ func (r *remoteReporter) createSnapshot() (*Snapshot, error) {
 		return nil
 	})
 	eg.Go(func() error {
-		workspaceBuilds, err := r.options.Database.GetWorkspaceBuildsCreatedAfter(ctx, createdAfter)
+		workspaceBuilds, err := r.options.Database.GetWorkspaceBuildsCreatedAfter(ctx, database.GetWorkspaceBuildsCreatedAfterParams{
+			CreatedAt: createdAfter,
+		})
 		if err != nil {
 			return xerrors.Errorf("get workspace builds: %w", err)
 		}
@@ -450,7 +454,9 @@ func (r *remoteReporter) createSnapshot() (*Snapshot, error) {
 		return nil
 	})
 	eg.Go(func() error {
-		stats, err := r.options.Database.GetWorkspaceAgentStats(ctx, createdAfter)
+		stats, err := r.options.Database.GetWorkspaceAgentStats(ctx, database.GetWorkspaceAgentStatsParams{
+			CreatedAt: createdAfter,
+		})
 		if err != nil {
 			return xerrors.Errorf("get workspace agent stats: %w", err)
 		}
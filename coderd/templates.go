@@ -318,6 +318,7 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 			DisplayName:                  createTemplate.DisplayName,
 			Icon:                         createTemplate.Icon,
 			AllowUserCancelWorkspaceJobs: allowUserCancelWorkspaceJobs,
+			ProvisionerTags:              database.StringMap{},
 		})
 		if err != nil {
 			return xerrors.Errorf("insert template: %s", err)
@@ -625,6 +626,9 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 				FailureTTL:    failureTTL,
 				InactivityTTL: inactivityTTL,
 				LockedTTL:     lockedTTL,
+				// Pausing isn't exposed by this endpoint, so keep the
+				// current value.
+				AutobuildPaused: scheduleOpts.AutobuildPaused,
 			})
 			if err != nil {
 				return xerrors.Errorf("set template schedule options: %w", err)
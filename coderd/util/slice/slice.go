@@ -67,3 +67,17 @@ func OverlapCompare[T any](a []T, b []T, equal func(a, b T) bool) bool {
 func New[T any](items ...T) []T {
 	return items
 }
+
+// Percentile returns the p-th percentile (0-100) of sorted using the
+// nearest-rank method. The index is clamped so p=100 returns the maximum.
+// sorted must be non-empty and in ascending order.
+func Percentile(sorted []float64, p float64) float64 {
+	i := int(float64(len(sorted)) * p / 100)
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
@@ -83,6 +83,19 @@ func TestOverlap(t *testing.T) {
 	)
 }
 
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	require.Equal(t, float64(1), slice.Percentile(sorted, 0))
+	require.Equal(t, float64(6), slice.Percentile(sorted, 50))
+	require.Equal(t, float64(10), slice.Percentile(sorted, 95))
+	require.Equal(t, float64(10), slice.Percentile(sorted, 100))
+
+	require.Equal(t, float64(42), slice.Percentile([]float64{42}, 50))
+	require.Equal(t, float64(42), slice.Percentile([]float64{42}, 100))
+}
+
 func assertSetOverlaps[T comparable](t *testing.T, overlap bool, a []T, b []T) {
 	t.Helper()
 	for _, e := range a {
@@ -432,7 +432,7 @@ func (api *API) workspaceAgentLogs(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logs, err := api.Database.GetWorkspaceAgentLogsAfter(ctx, database.GetWorkspaceAgentLogsAfterParams{
+	result, err := api.Database.GetWorkspaceAgentLogsAfterWithGap(ctx, database.GetWorkspaceAgentLogsAfterParams{
 		AgentID:      workspaceAgent.ID,
 		CreatedAfter: after,
 	})
@@ -446,9 +446,15 @@ func (api *API) workspaceAgentLogs(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	logs := result.Logs
 	if logs == nil {
 		logs = []database.WorkspaceAgentLog{}
 	}
+	if result.Gap {
+		// Headers are sent before the body and the websocket upgrade, so
+		// this is the only point the gap can be reported.
+		rw.Header().Set(codersdk.WorkspaceAgentLogsGapHeader, "true")
+	}
 
 	if !follow {
 		httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceAgentLogs(logs))
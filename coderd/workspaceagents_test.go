@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -23,6 +24,7 @@ import (
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/provisioner/echo"
@@ -242,6 +244,93 @@ func TestWorkspaceAgentStartupLogs(t *testing.T) {
 		require.Equal(t, "testing", logChunk[0].Output)
 		require.Equal(t, "testing2", logChunk[1].Output)
 	})
+	t.Run("Gap", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:         echo.ParseComplete,
+			ProvisionPlan: echo.ProvisionComplete,
+			ProvisionApply: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "example",
+							Type: "aws_instance",
+							Agents: []*proto.Agent{{
+								Id: uuid.NewString(),
+								Auth: &proto.Agent_Token{
+									Token: authToken,
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		agentID := build.Resources[0].Agents[0].ID
+
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(authToken)
+		patchLogs := func(output string) {
+			err := agentClient.PatchLogs(ctx, agentsdk.PatchLogs{
+				Logs: []agentsdk.Log{{
+					CreatedAt: database.Now(),
+					Output:    output,
+				}},
+			})
+			require.NoError(t, err)
+		}
+		fetchLogs := func(after int64, follow bool) []codersdk.WorkspaceAgentLog {
+			logs, closer, err := client.WorkspaceAgentLogsAfter(ctx, agentID, after, follow)
+			require.NoError(t, err)
+			defer closer.Close()
+			var logChunk []codersdk.WorkspaceAgentLog
+			select {
+			case <-ctx.Done():
+			case logChunk = <-logs:
+			}
+			require.NoError(t, ctx.Err())
+			return logChunk
+		}
+
+		patchLogs("before")
+		before := fetchLogs(0, false)
+		require.Len(t, before, 1)
+
+		// Truncate the logs, as happens when the agent hasn't connected in a
+		// while, then have it log again.
+		sysCtx := dbauthz.AsSystemRestricted(ctx)
+		err := api.Database.UpdateWorkspaceAgentConnectionByID(sysCtx, database.UpdateWorkspaceAgentConnectionByIDParams{
+			ID:              agentID,
+			LastConnectedAt: sql.NullTime{Time: database.Now().Add(-8 * 24 * time.Hour), Valid: true},
+			UpdatedAt:       database.Now(),
+		})
+		require.NoError(t, err)
+		err = api.Database.DeleteOldWorkspaceAgentLogs(sysCtx)
+		require.NoError(t, err)
+		patchLogs("after")
+
+		for _, follow := range []bool{false, true} {
+			logs := fetchLogs(before[0].ID, follow)
+			require.Len(t, logs, 2, "follow=%t", follow)
+			require.Equal(t, codersdk.WorkspaceAgentLogGap, logs[0], "follow=%t", follow)
+			require.Equal(t, "after", logs[1].Output, "follow=%t", follow)
+		}
+
+		// Reading from the start never reports a gap.
+		logs := fetchLogs(0, false)
+		require.Len(t, logs, 1)
+		require.Equal(t, "after", logs[0].Output)
+	})
 	t.Run("PublishesOnOverflow", func(t *testing.T) {
 		t.Parallel()
 		ctx := testutil.Context(t, testutil.WaitMedium)
@@ -34,6 +34,8 @@ type DBTokenProvider struct {
 	OAuth2Configs                 *httpmw.OAuth2Configs
 	WorkspaceAgentInactiveTimeout time.Duration
 	SigningKey                    SecurityKey
+	// App404Logs rate limits the logs of requests for missing apps.
+	App404Logs *App404LogLimiter
 }
 
 var _ SignedTokenProvider = &DBTokenProvider{}
@@ -52,6 +54,7 @@ func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authoriz
 		OAuth2Configs:                 oauth2Cfgs,
 		WorkspaceAgentInactiveTimeout: workspaceAgentInactiveTimeout,
 		SigningKey:                    signingKey,
+		App404Logs:                    NewApp404LogLimiter(0, 0, nil),
 	}
 }
 
@@ -70,7 +73,7 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	appReq := issueReq.AppRequest.Normalize()
 	err := appReq.Validate()
 	if err != nil {
-		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "invalid app request")
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, err, "invalid app request")
 		return nil, "", false
 	}
 
@@ -100,10 +103,10 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	// Lookup workspace app details from DB.
 	dbReq, err := appReq.getDatabase(dangerousSystemCtx, p.Database)
 	if xerrors.Is(err, sql.ErrNoRows) {
-		WriteWorkspaceApp404(p.Logger, p.DashboardURL, rw, r, &appReq, err.Error())
+		WriteWorkspaceApp404(p.Logger, p.App404Logs, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, err.Error())
 		return nil, "", false
 	} else if err != nil {
-		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "get app details from database")
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, err, "get app details from database")
 		return nil, "", false
 	}
 	token.UserID = dbReq.User.ID
@@ -116,13 +119,13 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	// Verify the user has access to the app.
 	authed, err := p.authorizeRequest(r.Context(), authz, dbReq)
 	if err != nil {
-		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "verify authz")
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, err, "verify authz")
 		return nil, "", false
 	}
 	if !authed {
 		if apiKey != nil {
 			// The request has a valid API key but insufficient permissions.
-			WriteWorkspaceApp404(p.Logger, p.DashboardURL, rw, r, &appReq, "insufficient permissions")
+			WriteWorkspaceApp404(p.Logger, p.App404Logs, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, "insufficient permissions")
 			return nil, "", false
 		}
 
@@ -139,7 +142,7 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 
 		appBaseURL, err := issueReq.AppBaseURL()
 		if err != nil {
-			WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "get app base URL")
+			WriteWorkspaceApp500(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, err, "get app base URL")
 			return nil, "", false
 		}
 
@@ -190,20 +193,20 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	// Check that the agent is online.
 	agentStatus := dbReq.Agent.Status(p.WorkspaceAgentInactiveTimeout)
 	if agentStatus.Status != database.WorkspaceAgentStatusConnected {
-		WriteWorkspaceAppOffline(p.Logger, p.DashboardURL, rw, r, &appReq, fmt.Sprintf("Agent state is %q, not %q", agentStatus.Status, database.WorkspaceAgentStatusConnected))
+		WriteWorkspaceAppOffline(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, fmt.Sprintf("Agent state is %q, not %q", agentStatus.Status, database.WorkspaceAgentStatusConnected))
 		return nil, "", false
 	}
 
 	// Check that the app is healthy.
 	if dbReq.AppHealth != "" && dbReq.AppHealth != database.WorkspaceAppHealthDisabled && dbReq.AppHealth != database.WorkspaceAppHealthHealthy {
-		WriteWorkspaceAppOffline(p.Logger, p.DashboardURL, rw, r, &appReq, fmt.Sprintf("App health is %q, not %q", dbReq.AppHealth, database.WorkspaceAppHealthHealthy))
+		WriteWorkspaceAppOffline(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, fmt.Sprintf("App health is %q, not %q", dbReq.AppHealth, database.WorkspaceAppHealthHealthy))
 		return nil, "", false
 	}
 
 	// As a sanity check, ensure the token we just made is valid for this
 	// request.
 	if !token.MatchesRequest(appReq) {
-		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, nil, "fresh token does not match request")
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, nil, "fresh token does not match request")
 		return nil, "", false
 	}
 
@@ -211,13 +214,29 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	token.Expiry = time.Now().Add(DefaultTokenExpiry)
 	tokenStr, err := p.SigningKey.SignToken(token)
 	if err != nil {
-		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "generate token")
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, p.errorPageBranding(dangerousSystemCtx), rw, r, &appReq, err, "generate token")
 		return nil, "", false
 	}
 
 	return &token, tokenStr, true
 }
 
+// errorPageBranding returns the deployment's custom branding for error pages.
+// Failing to fetch the logo falls back to the default branding rather than
+// masking the original error.
+func (p *DBTokenProvider) errorPageBranding(ctx context.Context) ErrorPageBranding {
+	var branding ErrorPageBranding
+	logoURL, err := p.Database.GetLogoURL(ctx)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		p.Logger.Warn(ctx, "get logo url for error page", slog.Error(err))
+	}
+	branding.LogoURL = logoURL
+	if p.DeploymentValues != nil && len(p.DeploymentValues.Support.Links.Value) > 0 {
+		branding.SupportURL = p.DeploymentValues.Support.Links.Value[0].Target
+	}
+	return branding
+}
+
 func (p *DBTokenProvider) authorizeRequest(ctx context.Context, roles *httpmw.Authorization, dbReq *databaseRequest) (bool, error) {
 	accessMethod := dbReq.AccessMethod
 	if accessMethod == "" {
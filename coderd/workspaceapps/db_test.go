@@ -20,7 +20,9 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
@@ -716,6 +718,52 @@ func Test_ResolveRequest(t *testing.T) {
 		require.Nil(t, token)
 	})
 
+	t.Run("CustomLogo", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		const logoURL = "https://example.com/custom-logo.png"
+		//nolint:gocritic // Setting the logo requires owner permissions.
+		err := api.Database.UpsertLogoURL(dbauthz.As(ctx, rbac.Subject{
+			ID:    firstUser.UserID.String(),
+			Roles: rbac.RoleNames{rbac.RoleOwner()},
+			Scope: rbac.ScopeAll,
+		}), logoURL)
+		require.NoError(t, err)
+
+		req := workspaceapps.Request{
+			AccessMethod:      workspaceapps.AccessMethodPath,
+			BasePath:          "/app",
+			UsernameOrID:      "thisuserdoesnotexist",
+			WorkspaceNameOrID: workspace.Name,
+			AgentNameOrID:     agentName,
+			AppSlugOrPort:     appNameOwner,
+		}
+
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/app", nil)
+		r.Header.Set(codersdk.SessionTokenHeader, client.SessionToken())
+
+		token, ok := workspaceapps.ResolveRequest(rw, r, workspaceapps.ResolveRequestOptions{
+			Logger:              api.Logger,
+			SignedTokenProvider: api.WorkspaceAppsProvider,
+			DashboardURL:        api.AccessURL,
+			PathAppBaseURL:      api.AccessURL,
+			AppHostname:         api.AppHostname,
+			AppRequest:          req,
+		})
+		require.False(t, ok)
+		require.Nil(t, token)
+
+		w := rw.Result()
+		defer w.Body.Close()
+		require.Equal(t, http.StatusNotFound, w.StatusCode)
+		body, err := io.ReadAll(w.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), logoURL)
+	})
+
 	t.Run("RedirectSubdomainAuth", func(t *testing.T) {
 		t.Parallel()
 
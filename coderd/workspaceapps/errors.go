@@ -3,39 +3,180 @@ package workspaceapps
 import (
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/site"
 )
 
-// WriteWorkspaceApp404 writes a HTML 404 error page for a workspace app. If
-// appReq is not nil, it will be used to log the request details at debug level.
-func WriteWorkspaceApp404(log slog.Logger, accessURL *url.URL, rw http.ResponseWriter, r *http.Request, appReq *Request, msg string) {
-	if appReq != nil {
-		slog.Helper()
-		log.Debug(r.Context(),
-			"workspace app 404: "+msg,
-			slog.F("username_or_id", appReq.UsernameOrID),
-			slog.F("workspace_and_agent", appReq.WorkspaceAndAgent),
-			slog.F("workspace_name_or_id", appReq.WorkspaceNameOrID),
-			slog.F("agent_name_or_id", appReq.AgentNameOrID),
-			slog.F("app_slug_or_port", appReq.AppSlugOrPort),
+// ErrorPageBranding customizes workspace app error pages. The zero value
+// renders the default Coder branding.
+type ErrorPageBranding struct {
+	LogoURL    string
+	SupportURL string
+}
+
+const (
+	// app404LogWindow is how long repeated 404s for the same app request are
+	// suppressed from the logs after the first one is logged.
+	app404LogWindow = time.Minute
+	// app404LogMaxEntries bounds the number of app requests tracked at once.
+	app404LogMaxEntries = 1024
+)
+
+// App404LogLimiter logs each app request passed to WriteWorkspaceApp404 at
+// most once per window, so a client repeatedly requesting a missing app
+// cannot flood the logs. The number of suppressed log lines is reported when
+// the request is next logged, or when it stops being tracked.
+type App404LogLimiter struct {
+	window     time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu        sync.Mutex
+	entries   map[Request]*logLimiterEntry
+	lastSweep time.Time
+}
+
+type logLimiterEntry struct {
+	loggedAt   time.Time
+	suppressed int
+}
+
+// suppressedLogs is the number of log lines suppressed for an app request
+// that is no longer tracked.
+type suppressedLogs struct {
+	req   Request
+	count int
+}
+
+// NewApp404LogLimiter returns a limiter that logs each app request at most
+// once per window and tracks at most maxEntries app requests. Zero values use
+// the defaults. If now is nil, time.Now is used.
+func NewApp404LogLimiter(window time.Duration, maxEntries int, now func() time.Time) *App404LogLimiter {
+	if window <= 0 {
+		window = app404LogWindow
+	}
+	if maxEntries <= 0 {
+		maxEntries = app404LogMaxEntries
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &App404LogLimiter{
+		window:     window,
+		maxEntries: maxEntries,
+		now:        now,
+		entries:    make(map[Request]*logLimiterEntry),
+	}
+}
+
+// allow returns true if req should be logged, along with the number of log
+// lines suppressed for req since it was last logged. It also returns the
+// suppressed counts of app requests that were evicted, which must be logged
+// by the caller so they are not lost.
+func (l *App404LogLimiter) allow(req Request) (ok bool, suppressed int, evicted []suppressedLogs) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	entry, tracked := l.entries[req]
+	if tracked && now.Sub(entry.loggedAt) < l.window {
+		entry.suppressed++
+		return false, 0, nil
+	}
+
+	// Expired entries are swept at most once per window to keep the cost
+	// of logging amortized constant.
+	if now.Sub(l.lastSweep) >= l.window {
+		l.lastSweep = now
+		for k, e := range l.entries {
+			if k != req && now.Sub(e.loggedAt) >= l.window {
+				evicted = l.evictLocked(k, evicted)
+			}
+		}
+	}
+	if !tracked && len(l.entries) >= l.maxEntries {
+		var (
+			oldest   Request
+			oldestAt time.Time
 		)
+		for k, e := range l.entries {
+			if oldestAt.IsZero() || e.loggedAt.Before(oldestAt) {
+				oldest, oldestAt = k, e.loggedAt
+			}
+		}
+		evicted = l.evictLocked(oldest, evicted)
 	}
 
-	site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+	if tracked {
+		suppressed = entry.suppressed
+	}
+	l.entries[req] = &logLimiterEntry{loggedAt: now}
+	return true, suppressed, evicted
+}
+
+func (l *App404LogLimiter) evictLocked(req Request, evicted []suppressedLogs) []suppressedLogs {
+	if entry := l.entries[req]; entry.suppressed > 0 {
+		evicted = append(evicted, suppressedLogs{req: req, count: entry.suppressed})
+	}
+	delete(l.entries, req)
+	return evicted
+}
+
+func app404LogFields(appReq Request, suppressed int) []any {
+	return []any{
+		slog.F("username_or_id", appReq.UsernameOrID),
+		slog.F("workspace_and_agent", appReq.WorkspaceAndAgent),
+		slog.F("workspace_name_or_id", appReq.WorkspaceNameOrID),
+		slog.F("agent_name_or_id", appReq.AgentNameOrID),
+		slog.F("app_slug_or_port", appReq.AppSlugOrPort),
+		slog.F("suppressed_count", suppressed),
+	}
+}
+
+// WriteWorkspaceApp404 writes a 404 error page for a workspace app. If appReq
+// is not nil, it will be used to log the request details at debug level. If
+// limiter is not nil, repeated 404s for the same appReq are rate limited by it.
+func WriteWorkspaceApp404(log slog.Logger, limiter *App404LogLimiter, accessURL *url.URL, branding ErrorPageBranding, rw http.ResponseWriter, r *http.Request, appReq *Request, msg string) {
+	if appReq != nil {
+		ok, suppressed := true, 0
+		if limiter != nil {
+			var evicted []suppressedLogs
+			ok, suppressed, evicted = limiter.allow(*appReq)
+			for _, e := range evicted {
+				log.Debug(r.Context(), "workspace app 404 logs suppressed", app404LogFields(e.req, e.count)...)
+			}
+		}
+		if ok {
+			slog.Helper()
+			log.Debug(r.Context(), "workspace app 404: "+msg, app404LogFields(*appReq, suppressed)...)
+		}
+	}
+
+	site.WriteError(rw, r, site.ErrorPageData{
 		Status:       http.StatusNotFound,
 		Title:        "Application Not Found",
 		Description:  "The application or workspace you are trying to access does not exist or you do not have permission to access it.",
 		RetryEnabled: false,
 		DashboardURL: accessURL.String(),
+		LogoURL:      branding.LogoURL,
+		SupportURL:   branding.SupportURL,
 	})
 }
 
-// WriteWorkspaceApp500 writes a HTML 500 error page for a workspace app. If
-// appReq is not nil, it's fields will be added to the logged error message.
-func WriteWorkspaceApp500(log slog.Logger, accessURL *url.URL, rw http.ResponseWriter, r *http.Request, appReq *Request, err error, msg string) {
+// WriteWorkspaceApp500 writes a 500 error page for a workspace app. If appReq
+// is not nil, it's fields will be added to the logged error message. The
+// request ID is already attached to the log context by httpmw.AttachRequestID,
+// so it is shown on the page to allow correlating the two.
+func WriteWorkspaceApp500(log slog.Logger, accessURL *url.URL, branding ErrorPageBranding, rw http.ResponseWriter, r *http.Request, appReq *Request, err error, msg string) {
 	ctx := r.Context()
+	var requestID string
+	if rid, ok := httpmw.RequestIDOptional(r); ok {
+		requestID = rid.String()
+	}
 	if appReq != nil {
 		slog.Helper()
 		ctx = slog.With(ctx,
@@ -51,18 +192,21 @@ func WriteWorkspaceApp500(log slog.Logger, accessURL *url.URL, rw http.ResponseW
 		slog.Error(err),
 	)
 
-	site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+	site.WriteError(rw, r, site.ErrorPageData{
 		Status:       http.StatusInternalServerError,
 		Title:        "Internal Server Error",
 		Description:  "An internal server error occurred.",
 		RetryEnabled: false,
 		DashboardURL: accessURL.String(),
+		LogoURL:      branding.LogoURL,
+		SupportURL:   branding.SupportURL,
+		RequestID:    requestID,
 	})
 }
 
-// WriteWorkspaceAppOffline writes a HTML 502 error page for a workspace app. If
+// WriteWorkspaceAppOffline writes a 502 error page for a workspace app. If
 // appReq is not nil, it will be used to log the request details at debug level.
-func WriteWorkspaceAppOffline(log slog.Logger, accessURL *url.URL, rw http.ResponseWriter, r *http.Request, appReq *Request, msg string) {
+func WriteWorkspaceAppOffline(log slog.Logger, accessURL *url.URL, branding ErrorPageBranding, rw http.ResponseWriter, r *http.Request, appReq *Request, msg string) {
 	if appReq != nil {
 		slog.Helper()
 		log.Debug(r.Context(),
@@ -75,11 +219,13 @@ func WriteWorkspaceAppOffline(log slog.Logger, accessURL *url.URL, rw http.Respo
 		)
 	}
 
-	site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+	site.WriteError(rw, r, site.ErrorPageData{
 		Status:       http.StatusBadGateway,
 		Title:        "Application Unavailable",
 		Description:  msg,
 		RetryEnabled: true,
 		DashboardURL: accessURL.String(),
+		LogoURL:      branding.LogoURL,
+		SupportURL:   branding.SupportURL,
 	})
 }
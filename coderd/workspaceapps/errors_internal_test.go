@@ -0,0 +1,79 @@
+package workspaceapps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp404LogLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Window", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		l := NewApp404LogLimiter(time.Minute, 0, func() time.Time { return now })
+		key := Request{AppSlugOrPort: "app"}
+
+		ok, suppressed, _ := l.allow(key)
+		require.True(t, ok)
+		require.Zero(t, suppressed)
+
+		now = now.Add(time.Second)
+		for i := 0; i < 5; i++ {
+			ok, _, _ = l.allow(key)
+			require.False(t, ok)
+		}
+
+		// Once the window has passed the key is logged again with the number
+		// of suppressed log lines.
+		now = now.Add(time.Minute)
+		ok, suppressed, evicted := l.allow(key)
+		require.True(t, ok)
+		require.Equal(t, 5, suppressed)
+		require.Empty(t, evicted)
+	})
+
+	t.Run("FlushOnExpiry", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		l := NewApp404LogLimiter(time.Minute, 0, func() time.Time { return now })
+		key := Request{AppSlugOrPort: "app"}
+		other := Request{AppSlugOrPort: "other"}
+
+		_, _, _ = l.allow(key)
+		_, _, _ = l.allow(key)
+		_, _, _ = l.allow(key)
+
+		// The expired key is swept when another key is logged, and its
+		// suppressed count is reported rather than dropped.
+		now = now.Add(time.Minute)
+		ok, _, evicted := l.allow(other)
+		require.True(t, ok)
+		require.Equal(t, []suppressedLogs{{req: key, count: 2}}, evicted)
+		require.Len(t, l.entries, 1)
+	})
+
+	t.Run("MaxEntries", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now()
+		l := NewApp404LogLimiter(time.Minute, 2, func() time.Time { return now })
+		first := Request{AppSlugOrPort: "first"}
+
+		_, _, _ = l.allow(first)
+		_, _, _ = l.allow(first)
+		now = now.Add(time.Second)
+		_, _, _ = l.allow(Request{AppSlugOrPort: "second"})
+		now = now.Add(time.Second)
+
+		// Tracking a third key evicts the oldest one, flushing its count.
+		ok, _, evicted := l.allow(Request{AppSlugOrPort: "third"})
+		require.True(t, ok)
+		require.Equal(t, []suppressedLogs{{req: first, count: 1}}, evicted)
+		require.Len(t, l.entries, 2)
+	})
+}
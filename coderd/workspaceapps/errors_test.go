@@ -0,0 +1,137 @@
+package workspaceapps_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/workspaceapps"
+)
+
+func TestWriteWorkspaceApp500RequestID(t *testing.T) {
+	t.Parallel()
+
+	sink := &fakeSink{}
+	logger := slog.Make(sink)
+	accessURL, err := url.Parse("https://coder.example.com")
+	require.NoError(t, err)
+
+	handler := httpmw.AttachRequestID(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		workspaceapps.WriteWorkspaceApp500(logger, accessURL, workspaceapps.ErrorPageBranding{}, rw, r, nil, xerrors.New("boom"), "something broke")
+	}))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(rw, r)
+
+	resp := rw.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	requestID := resp.Header.Get("X-Coder-Request-Id")
+	require.NotEmpty(t, requestID)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), requestID)
+
+	entries := sink.Entries()
+	require.Len(t, entries, 1)
+	var logged string
+	for _, field := range entries[0].Fields {
+		if field.Name == "request_id" {
+			logged = fmt.Sprint(field.Value)
+		}
+	}
+	require.Equal(t, requestID, logged)
+}
+
+func TestWriteWorkspaceApp404RateLimitedLogs(t *testing.T) {
+	t.Parallel()
+
+	sink := &fakeSink{}
+	logger := slog.Make(sink).Leveled(slog.LevelDebug)
+	accessURL, err := url.Parse("https://coder.example.com")
+	require.NoError(t, err)
+
+	now := time.Now()
+	limiter := workspaceapps.NewApp404LogLimiter(time.Minute, 0, func() time.Time { return now })
+	write404 := func(appReq workspaceapps.Request) {
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		workspaceapps.WriteWorkspaceApp404(logger, limiter, accessURL, workspaceapps.ErrorPageBranding{}, rw, r, &appReq, "app not found")
+
+		// The page must always be rendered, even if logging is suppressed.
+		resp := rw.Result()
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	appReq := workspaceapps.Request{
+		AccessMethod:      workspaceapps.AccessMethodPath,
+		BasePath:          "/app",
+		UsernameOrID:      uuid.NewString(),
+		WorkspaceNameOrID: "workspace",
+		AgentNameOrID:     "agent",
+		AppSlugOrPort:     "app",
+	}
+	for i := 0; i < 100; i++ {
+		write404(appReq)
+	}
+	require.Len(t, sink.Entries(), 1)
+
+	// A different app request is logged separately.
+	otherReq := appReq
+	otherReq.AppSlugOrPort = "other-app"
+	for i := 0; i < 100; i++ {
+		write404(otherReq)
+	}
+	require.Len(t, sink.Entries(), 2)
+
+	// After the window, the suppressed lines of both requests are reported.
+	now = now.Add(time.Minute)
+	write404(appReq)
+	entries := sink.Entries()
+	require.Len(t, entries, 4)
+	require.Equal(t, "workspace app 404 logs suppressed", entries[2].Message)
+	require.Equal(t, "workspace app 404: app not found", entries[3].Message)
+	for _, entry := range entries[2:] {
+		var suppressed any
+		for _, field := range entry.Fields {
+			if field.Name == "suppressed_count" {
+				suppressed = field.Value
+			}
+		}
+		require.Equal(t, 99, suppressed)
+	}
+}
+
+type fakeSink struct {
+	mu      sync.Mutex
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+}
+
+func (s *fakeSink) Entries() []slog.SinkEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]slog.SinkEntry(nil), s.entries...)
+}
+
+func (*fakeSink) Sync() {}
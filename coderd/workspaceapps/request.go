@@ -234,7 +234,9 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 	}
 
 	// Get workspace agents.
-	agents, err := db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, workspace.ID)
+	agents, err := db.GetWorkspaceAgentsInLatestBuildByWorkspaceID(ctx, database.GetWorkspaceAgentsInLatestBuildByWorkspaceIDParams{
+		WorkspaceID: workspace.ID,
+	})
 	if err != nil {
 		return nil, xerrors.Errorf("get workspace agents: %w", err)
 	}
@@ -699,6 +699,35 @@ func (c *Client) GitAuth(ctx context.Context, gitURL string, listen bool) (GitAu
 	return authResp, json.NewDecoder(res.Body).Decode(&authResp)
 }
 
+// GitAuthWaitTimeout is how long WaitForGitAuth waits for the user to
+// authenticate with a Git provider before giving up.
+const GitAuthWaitTimeout = 10 * time.Minute
+
+// ErrGitAuthTimeout is returned by WaitForGitAuth when no credentials are
+// issued before the timeout elapses.
+var ErrGitAuthTimeout = xerrors.New("timed out waiting for git authentication")
+
+// WaitForGitAuth blocks until credentials for the URL are issued. Listen
+// requests are retried with a bounded backoff, and ErrGitAuthTimeout is
+// returned once timeout elapses so a misbehaving server can't hang the
+// caller indefinitely.
+func (c *Client) WaitForGitAuth(ctx context.Context, gitURL string, timeout time.Duration) (GitAuthResponse, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for r := retry.New(250*time.Millisecond, 10*time.Second); r.Wait(waitCtx); {
+		resp, err := c.GitAuth(waitCtx, gitURL, true)
+		if err == nil {
+			return resp, nil
+		}
+	}
+	// Only report a timeout if the parent context is still alive.
+	if ctx.Err() != nil {
+		return GitAuthResponse{}, ctx.Err()
+	}
+	return GitAuthResponse{}, ErrGitAuthTimeout
+}
+
 type closeFunc func() error
 
 func (c closeFunc) Close() error {
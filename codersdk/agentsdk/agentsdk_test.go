@@ -0,0 +1,38 @@
+package agentsdk_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWaitForGitAuth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+		// The server never issues credentials, so every listen request
+		// blocks until the client gives up.
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		t.Cleanup(srv.Close)
+		serverURL, err := url.Parse(srv.URL)
+		require.NoError(t, err)
+		client := agentsdk.New(serverURL)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		_, err = client.WaitForGitAuth(ctx, "github.com/coder/coder", 100*time.Millisecond)
+		require.ErrorIs(t, err, agentsdk.ErrGitAuthTimeout)
+	})
+}
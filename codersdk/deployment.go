@@ -323,12 +323,13 @@ type GitAuthConfig struct {
 }
 
 type ProvisionerConfig struct {
-	Daemons             clibase.Int64    `json:"daemons" typescript:",notnull"`
-	DaemonsEcho         clibase.Bool     `json:"daemons_echo" typescript:",notnull"`
-	DaemonPollInterval  clibase.Duration `json:"daemon_poll_interval" typescript:",notnull"`
-	DaemonPollJitter    clibase.Duration `json:"daemon_poll_jitter" typescript:",notnull"`
-	ForceCancelInterval clibase.Duration `json:"force_cancel_interval" typescript:",notnull"`
-	DaemonPSK           clibase.String   `json:"daemon_psk" typescript:",notnull"`
+	Daemons                   clibase.Int64    `json:"daemons" typescript:",notnull"`
+	DaemonsEcho               clibase.Bool     `json:"daemons_echo" typescript:",notnull"`
+	DaemonPollInterval        clibase.Duration `json:"daemon_poll_interval" typescript:",notnull"`
+	DaemonPollJitter          clibase.Duration `json:"daemon_poll_jitter" typescript:",notnull"`
+	ForceCancelInterval       clibase.Duration `json:"force_cancel_interval" typescript:",notnull"`
+	DaemonPSK                 clibase.String   `json:"daemon_psk" typescript:",notnull"`
+	TerraformReadOnlyLockfile clibase.Bool     `json:"terraform_readonly_lockfile" typescript:",notnull"`
 }
 
 type RateLimitConfig struct {
@@ -1240,6 +1241,16 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupProvisioning,
 			YAML:        "daemonPSK",
 		},
+		{
+			Name:        "Terraform Read-Only Lockfile",
+			Description: "Run terraform init with a read-only dependency lock file. Templates must include a complete .terraform.lock.hcl or provisioning will fail.",
+			Flag:        "provisioner-terraform-readonly-lockfile",
+			Env:         "CODER_PROVISIONER_TERRAFORM_READONLY_LOCKFILE",
+			Default:     "false",
+			Value:       &c.Provisioner.TerraformReadOnlyLockfile,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "terraformReadOnlyLockfile",
+		},
 		// RateLimit settings
 		{
 			Name:        "Disable All Rate Limits",
@@ -3,8 +3,11 @@ package codersdk
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type GitAuth struct {
@@ -35,6 +38,14 @@ type GitAuthUser struct {
 	Name       string `json:"name"`
 }
 
+// GitAuthHealth is the reachability of a configured git auth provider.
+type GitAuthHealth struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
 // GitAuthDevice is the response from the device authorization endpoint.
 // See: https://tools.ietf.org/html/rfc8628#section-3.2
 type GitAuthDevice struct {
@@ -75,6 +86,58 @@ func (c *Client) GitAuthDeviceExchange(ctx context.Context, provider string, req
 	return nil
 }
 
+// GitAuthDeviceExchangeWait exchanges a device code, polling at the device's
+// interval until the user authorizes it. A slow_down response increases the
+// interval to the one requested by the server, or by five seconds if none
+// was given. See: https://tools.ietf.org/html/rfc8628#section-3.5
+func (c *Client) GitAuthDeviceExchangeWait(ctx context.Context, provider string, device GitAuthDevice) error {
+	interval := time.Duration(device.Interval) * time.Second
+	if interval <= 0 {
+		// The default interval defined by the spec.
+		interval = 5 * time.Second
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/gitauth/%s/device", provider), GitAuthDeviceExchange{
+			DeviceCode: device.DeviceCode,
+		})
+		if err != nil {
+			return err
+		}
+		if res.StatusCode == http.StatusNoContent {
+			_ = res.Body.Close()
+			return nil
+		}
+		retryAfter, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		err = ReadBodyAsError(res)
+		_ = res.Body.Close()
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) {
+			return err
+		}
+		switch apiErr.Detail {
+		case "authorization_pending":
+		case "slow_down":
+			next := time.Duration(retryAfter) * time.Second
+			if next <= interval {
+				next = interval + 5*time.Second
+			}
+			interval = next
+		default:
+			return err
+		}
+		timer.Reset(interval)
+	}
+}
+
 // GitAuthByID returns the git auth for the given provider by ID.
 func (c *Client) GitAuthByID(ctx context.Context, provider string) (GitAuth, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/gitauth/%s", provider), nil)
@@ -88,3 +151,18 @@ func (c *Client) GitAuthByID(ctx context.Context, provider string) (GitAuth, err
 	var gitauth GitAuth
 	return gitauth, json.NewDecoder(res.Body).Decode(&gitauth)
 }
+
+// DebugGitAuthHealth checks whether each configured git auth provider is
+// reachable from the deployment.
+func (c *Client) DebugGitAuthHealth(ctx context.Context) ([]GitAuthHealth, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/debug/gitauth", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var health []GitAuthHealth
+	return health, json.NewDecoder(res.Body).Decode(&health)
+}
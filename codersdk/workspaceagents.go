@@ -661,6 +661,9 @@ func (c *Client) WorkspaceAgentLogsAfter(ctx context.Context, agentID uuid.UUID,
 		if err != nil {
 			return nil, nil, xerrors.Errorf("decode startup logs: %w", err)
 		}
+		if resp.Header.Get(WorkspaceAgentLogsGapHeader) == "true" {
+			logs = append([]WorkspaceAgentLog{WorkspaceAgentLogGap}, logs...)
+		}
 
 		ch := make(chan []WorkspaceAgentLog, 1)
 		ch <- logs
@@ -694,6 +697,7 @@ func (c *Client) WorkspaceAgentLogsAfter(ctx context.Context, agentID uuid.UUID,
 	closed := make(chan struct{})
 	ctx, wsNetConn := websocketNetConn(ctx, conn, websocket.MessageText)
 	decoder := json.NewDecoder(wsNetConn)
+	gap := res.Header.Get(WorkspaceAgentLogsGapHeader) == "true"
 	go func() {
 		defer close(closed)
 		defer close(logChunks)
@@ -704,6 +708,11 @@ func (c *Client) WorkspaceAgentLogsAfter(ctx context.Context, agentID uuid.UUID,
 			if err != nil {
 				return
 			}
+			if gap {
+				// The gap precedes the first chunk of logs.
+				logs = append([]WorkspaceAgentLog{WorkspaceAgentLogGap}, logs...)
+				gap = false
+			}
 			select {
 			case <-ctx.Done():
 				return
@@ -751,6 +760,19 @@ type WorkspaceAgentLog struct {
 	Level     LogLevel  `json:"level"`
 }
 
+// WorkspaceAgentLogsGapHeader is set on agent log responses when the
+// requested "after" cursor is older than the oldest retained log, meaning
+// some logs were removed before they could be read.
+const WorkspaceAgentLogsGapHeader = "Coder-Agent-Logs-Gap"
+
+// WorkspaceAgentLogGap is sent by WorkspaceAgentLogsAfter ahead of the logs
+// when the server reports a gap. It is never stored, so it has no ID.
+var WorkspaceAgentLogGap = WorkspaceAgentLog{
+	ID:     -1,
+	Output: "(earlier logs unavailable)",
+	Level:  LogLevelWarn,
+}
+
 type AgentSubsystem string
 
 const (
@@ -90,10 +90,26 @@ func (c *Client) CreateWorkspaceProxy(ctx context.Context, req CreateWorkspacePr
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
-func (c *Client) WorkspaceProxies(ctx context.Context) (RegionsResponse[WorkspaceProxy], error) {
+// WorkspaceProxiesRequest filters the workspace proxies returned by
+// WorkspaceProxies.
+type WorkspaceProxiesRequest struct {
+	// Search matches proxies whose display name contains it,
+	// case-insensitively.
+	Search string `json:"q,omitempty"`
+}
+
+func (c *Client) WorkspaceProxies(ctx context.Context, req WorkspaceProxiesRequest) (RegionsResponse[WorkspaceProxy], error) {
 	res, err := c.Request(ctx, http.MethodGet,
 		"/api/v2/workspaceproxies",
 		nil,
+		func(r *http.Request) {
+			if req.Search == "" {
+				return
+			}
+			q := r.URL.Query()
+			q.Set("q", req.Search)
+			r.URL.RawQuery = q.Encode()
+		},
 	)
 	if err != nil {
 		return RegionsResponse[WorkspaceProxy]{}, xerrors.Errorf("make request: %w", err)
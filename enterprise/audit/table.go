@@ -84,6 +84,8 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"failure_ttl":                      ActionTrack,
 		"inactivity_ttl":                   ActionTrack,
 		"locked_ttl":                       ActionTrack,
+		"provisioner_tags":                 ActionTrack,
+		"autobuild_paused":                 ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                    ActionTrack,
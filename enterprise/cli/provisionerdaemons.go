@@ -45,6 +45,8 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 		pollInterval time.Duration
 		pollJitter   time.Duration
 		preSharedKey string
+
+		readOnlyLockfile bool
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -86,8 +88,9 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 					ServeOptions: &provisionersdk.ServeOptions{
 						Listener: terraformServer,
 					},
-					CachePath: cacheDir,
-					Logger:    logger.Named("terraform"),
+					CachePath:        cacheDir,
+					Logger:           logger.Named("terraform"),
+					ReadOnlyLockfile: readOnlyLockfile,
 				})
 				if err != nil && !xerrors.Is(err, context.Canceled) {
 					select {
@@ -188,6 +191,13 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 			Description: "Pre-shared key to authenticate with Coder server.",
 			Value:       clibase.StringOf(&preSharedKey),
 		},
+		{
+			Flag:        "terraform-readonly-lockfile",
+			Env:         "CODER_PROVISIONERD_TERRAFORM_READONLY_LOCKFILE",
+			Description: "Run terraform init with a read-only dependency lock file.",
+			Default:     "false",
+			Value:       clibase.BoolOf(&readOnlyLockfile),
+		},
 	}
 
 	return cmd
@@ -371,7 +371,7 @@ func (r *RootCmd) listProxies() *clibase.Cmd {
 		),
 		Handler: func(inv *clibase.Invocation) error {
 			ctx := inv.Context()
-			proxies, err := client.WorkspaceProxies(ctx)
+			proxies, err := client.WorkspaceProxies(ctx, codersdk.WorkspaceProxiesRequest{})
 			if err != nil {
 				return xerrors.Errorf("list workspace proxies: %w", err)
 			}
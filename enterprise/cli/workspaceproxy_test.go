@@ -79,7 +79,7 @@ func Test_ProxyCRUD(t *testing.T) {
 		pty.ExpectMatch(expectedName)
 
 		// Also check via the api
-		proxies, err := client.WorkspaceProxies(ctx)
+		proxies, err := client.WorkspaceProxies(ctx, codersdk.WorkspaceProxiesRequest{})
 		require.NoError(t, err, "failed to get workspace proxies")
 		// Include primary
 		require.Len(t, proxies.Regions, 2, "expected 1 proxy")
@@ -133,7 +133,7 @@ func Test_ProxyCRUD(t *testing.T) {
 		err = inv.WithContext(ctx).Run()
 		require.NoError(t, err)
 
-		proxies, err := client.WorkspaceProxies(ctx)
+		proxies, err := client.WorkspaceProxies(ctx, codersdk.WorkspaceProxiesRequest{})
 		require.NoError(t, err, "failed to get workspace proxies")
 		require.Len(t, proxies.Regions, 1, "expected only primary proxy")
 	})
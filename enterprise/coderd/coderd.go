@@ -47,6 +47,9 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 	if options.EntitlementsUpdateInterval == 0 {
 		options.EntitlementsUpdateInterval = 10 * time.Minute
 	}
+	if options.ProxyCoordinateIdleTimeout == 0 {
+		options.ProxyCoordinateIdleTimeout = time.Minute
+	}
 	if options.Keys == nil {
 		options.Keys = Keys
 	}
@@ -86,7 +89,7 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 		// If not, always default to the regions.
 		actor, ok := dbauthz.ActorFromContext(ctx)
 		if ok && api.Authorizer.Authorize(ctx, actor, rbac.ActionRead, rbac.ResourceWorkspaceProxy) == nil {
-			return api.fetchWorkspaceProxies(ctx)
+			return api.fetchWorkspaceProxies(ctx, "")
 		}
 		return api.fetchRegions(ctx)
 	}
@@ -372,6 +375,9 @@ type Options struct {
 
 	EntitlementsUpdateInterval time.Duration
 	ProxyHealthInterval        time.Duration
+	// ProxyCoordinateIdleTimeout is how long a workspace proxy coordinate
+	// connection may go without answering a ping before it's closed.
+	ProxyCoordinateIdleTimeout time.Duration
 	Keys                       map[string]ed25519.PublicKey
 
 	// optional pre-shared key for authentication of external provisioner daemons
@@ -361,7 +361,9 @@ func (api *API) groups(rw http.ResponseWriter, r *http.Request) {
 		org = httpmw.OrganizationParam(r)
 	)
 
-	groups, err := api.Database.GetGroupsByOrganizationID(ctx, org.ID)
+	groups, err := api.Database.GetGroupsByOrganizationID(ctx, database.GetGroupsByOrganizationIDParams{
+		OrganizationID: org.ID,
+	})
 	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 		httpapi.InternalServerError(rw, err)
 		return
@@ -218,7 +218,7 @@ func (p *ProxyHealth) runOnce(ctx context.Context, now time.Time) (map[uuid.UUID
 	defer p.healthCheckDuration.Observe(time.Since(now).Seconds())
 
 	//nolint:gocritic // Proxy health is a system service.
-	proxies, err := p.db.GetWorkspaceProxies(dbauthz.AsSystemRestricted(ctx))
+	proxies, err := p.db.GetWorkspaceProxies(dbauthz.AsSystemRestricted(ctx), "")
 	if err != nil {
 		return nil, xerrors.Errorf("get workspace proxies: %w", err)
 	}
@@ -58,9 +58,10 @@ func (s *EnterpriseTemplateScheduleStore) Get(ctx context.Context, db database.S
 			DaysOfWeek: uint8(tpl.RestartRequirementDaysOfWeek),
 			Weeks:      tpl.RestartRequirementWeeks,
 		},
-		FailureTTL:    time.Duration(tpl.FailureTTL),
-		InactivityTTL: time.Duration(tpl.InactivityTTL),
-		LockedTTL:     time.Duration(tpl.LockedTTL),
+		FailureTTL:      time.Duration(tpl.FailureTTL),
+		InactivityTTL:   time.Duration(tpl.InactivityTTL),
+		LockedTTL:       time.Duration(tpl.LockedTTL),
+		AutobuildPaused: tpl.AutobuildPaused,
 	}, nil
 }
 
@@ -74,7 +75,8 @@ func (*EnterpriseTemplateScheduleStore) Set(ctx context.Context, db database.Sto
 		int64(opts.InactivityTTL) == tpl.InactivityTTL &&
 		int64(opts.LockedTTL) == tpl.LockedTTL &&
 		opts.UserAutostartEnabled == tpl.AllowUserAutostart &&
-		opts.UserAutostopEnabled == tpl.AllowUserAutostop {
+		opts.UserAutostopEnabled == tpl.AllowUserAutostop &&
+		opts.AutobuildPaused == tpl.AutobuildPaused {
 		// Avoid updating the UpdatedAt timestamp if nothing will be changed.
 		return tpl, nil
 	}
@@ -98,6 +100,7 @@ func (*EnterpriseTemplateScheduleStore) Set(ctx context.Context, db database.Sto
 			FailureTTL:                   int64(opts.FailureTTL),
 			InactivityTTL:                int64(opts.InactivityTTL),
 			LockedTTL:                    int64(opts.LockedTTL),
+			AutobuildPaused:              opts.AutobuildPaused,
 		})
 		if err != nil {
 			return xerrors.Errorf("update template schedule: %w", err)
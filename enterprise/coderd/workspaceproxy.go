@@ -56,7 +56,7 @@ func (api *API) fetchRegions(ctx context.Context) (codersdk.RegionsResponse[code
 	// cannot usually access in order to give them a full list of available
 	// regions. Regions are just a data subset of proxies.
 	ctx = dbauthz.AsSystemRestricted(ctx)
-	proxies, err := api.fetchWorkspaceProxies(ctx)
+	proxies, err := api.fetchWorkspaceProxies(ctx, "")
 	if err != nil {
 		return codersdk.RegionsResponse[codersdk.Region]{}, err
 	}
@@ -403,11 +403,12 @@ func validateProxyURL(u string) error {
 // @Security CoderSessionToken
 // @Produce json
 // @Tags Enterprise
+// @Param q query string false "Search by display name"
 // @Success 200 {array} codersdk.RegionsResponse[codersdk.WorkspaceProxy]
 // @Router /workspaceproxies [get]
 func (api *API) workspaceProxies(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	proxies, err := api.fetchWorkspaceProxies(r.Context())
+	proxies, err := api.fetchWorkspaceProxies(r.Context(), r.URL.Query().Get("q"))
 	if err != nil {
 		if dbauthz.IsNotAuthorizedError(err) {
 			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
@@ -421,18 +422,23 @@ func (api *API) workspaceProxies(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(ctx, rw, http.StatusOK, proxies)
 }
 
-func (api *API) fetchWorkspaceProxies(ctx context.Context) (codersdk.RegionsResponse[codersdk.WorkspaceProxy], error) {
-	proxies, err := api.Database.GetWorkspaceProxies(ctx)
+// fetchWorkspaceProxies returns the primary proxy and all workspace proxies.
+// If search is set, only proxies whose display name contains it are returned.
+func (api *API) fetchWorkspaceProxies(ctx context.Context, search string) (codersdk.RegionsResponse[codersdk.WorkspaceProxy], error) {
+	proxies, err := api.Database.GetWorkspaceProxies(ctx, search)
 	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 		return codersdk.RegionsResponse[codersdk.WorkspaceProxy]{}, err
 	}
 
-	// Add the primary as well
+	// Add the primary as well. It is not stored in the database, so it has to
+	// be matched against the search here.
 	primaryProxy, err := api.AGPL.PrimaryWorkspaceProxy(ctx)
 	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 		return codersdk.RegionsResponse[codersdk.WorkspaceProxy]{}, err
 	}
-	proxies = append([]database.WorkspaceProxy{primaryProxy}, proxies...)
+	if search == "" || strings.Contains(strings.ToLower(primaryProxy.DisplayName), strings.ToLower(search)) {
+		proxies = append([]database.WorkspaceProxy{primaryProxy}, proxies...)
+	}
 
 	statues := api.ProxyHealth.HealthStatus()
 	return codersdk.RegionsResponse[codersdk.WorkspaceProxy]{
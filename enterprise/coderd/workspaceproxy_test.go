@@ -253,11 +253,57 @@ func TestWorkspaceProxyCRUD(t *testing.T) {
 		err = client.DeleteWorkspaceProxyByID(ctx, proxyRes.Proxy.ID)
 		require.NoError(t, err, "failed to delete workspace proxy")
 
-		proxies, err := client.WorkspaceProxies(ctx)
+		proxies, err := client.WorkspaceProxies(ctx, codersdk.WorkspaceProxiesRequest{})
 		require.NoError(t, err)
 		// Default proxy is always there
 		require.Len(t, proxies.Regions, 1)
 	})
+
+	t.Run("SortAndSearch", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments = []string{
+			string(codersdk.ExperimentMoons),
+			"*",
+		}
+		client, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureWorkspaceProxy: 1,
+				},
+			},
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+		for _, req := range []codersdk.CreateWorkspaceProxyRequest{
+			{Name: "sydney", DisplayName: "Australia East"},
+			{Name: "amsterdam", DisplayName: "Europe West"},
+			{Name: "frankfurt", DisplayName: "Europe Central"},
+		} {
+			_, err := client.CreateWorkspaceProxy(ctx, req)
+			require.NoError(t, err)
+		}
+
+		proxies, err := client.WorkspaceProxies(ctx, codersdk.WorkspaceProxiesRequest{})
+		require.NoError(t, err)
+		require.Len(t, proxies.Regions, 4)
+		// The primary proxy is always first, followed by the others by name.
+		require.Equal(t, "primary", proxies.Regions[0].Name)
+		require.Equal(t, "amsterdam", proxies.Regions[1].Name)
+		require.Equal(t, "frankfurt", proxies.Regions[2].Name)
+		require.Equal(t, "sydney", proxies.Regions[3].Name)
+
+		proxies, err = client.WorkspaceProxies(ctx, codersdk.WorkspaceProxiesRequest{
+			Search: "europe",
+		})
+		require.NoError(t, err)
+		require.Len(t, proxies.Regions, 2)
+		require.Equal(t, "amsterdam", proxies.Regions[0].Name)
+		require.Equal(t, "frankfurt", proxies.Regions[1].Name)
+	})
 }
 
 func TestProxyRegisterDeregister(t *testing.T) {
@@ -1,9 +1,14 @@
 package coderd
 
 import (
+	"context"
+	"io"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/xerrors"
 	"nhooyr.io/websocket"
 
 	"github.com/coder/coder/coderd/httpapi"
@@ -11,6 +16,7 @@ import (
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/tailnet"
 	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
+	agpltailnet "github.com/coder/coder/tailnet"
 )
 
 // @Summary Agent is legacy
@@ -66,13 +72,82 @@ func (api *API) workspaceProxyCoordinate(rw http.ResponseWriter, r *http.Request
 		return
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	id := uuid.New()
 	sub := (*api.AGPL.TailnetCoordinator.Load()).ServeMultiAgent(id)
 	nc := websocket.NetConn(ctx, conn, websocket.MessageText)
 	defer nc.Close()
 
+	// The proxy must answer the first ping before the deadline, and every
+	// answered ping pushes it back.
+	_ = nc.SetReadDeadline(time.Now().Add(proxyCoordinateReadTimeout(api.ProxyCoordinateIdleTimeout)))
+	go proxyCoordinateKeepalive(ctx, conn, nc, api.ProxyCoordinateIdleTimeout)
+
 	err = tailnet.ServeWorkspaceProxy(ctx, nc, sub)
 	if err != nil {
-		_ = conn.Close(websocket.StatusInternalError, err.Error())
+		_ = conn.Close(proxyCoordinateCloseStatus(ctx, err), err.Error())
+	}
+}
+
+// proxyCoordinateCloseStatus classifies an error from ServeWorkspaceProxy
+// into the status the connection is closed with.
+func proxyCoordinateCloseStatus(ctx context.Context, err error) websocket.StatusCode {
+	switch {
+	case xerrors.Is(err, tailnet.ErrInvalidCoordinateMessage):
+		return websocket.StatusPolicyViolation
+	case ctx.Err() != nil,
+		xerrors.Is(err, agpltailnet.ErrMultiAgentClosed),
+		xerrors.Is(err, io.EOF),
+		xerrors.Is(err, net.ErrClosed):
+		return websocket.StatusGoingAway
+	default:
+		return websocket.StatusInternalError
+	}
+}
+
+// proxyCoordinateReadTimeout is the read deadline of a coordinate
+// connection. It's longer than it takes for a ping to time out, so a silent
+// proxy is closed by the keepalive with CoordinateStatusIdleTimeout, and
+// the deadline only catches a connection the keepalive failed to close.
+func proxyCoordinateReadTimeout(idleTimeout time.Duration) time.Duration {
+	return 2 * idleTimeout
+}
+
+// proxyCoordinateKeepalive pings the proxy at half the idle timeout and
+// closes the connection if a ping isn't answered within the timeout, so a
+// silent proxy can't hold the connection open forever.
+func proxyCoordinateKeepalive(ctx context.Context, conn *websocket.Conn, nc net.Conn, timeout time.Duration) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// Ping with the connection's context, since a ping whose context
+		// expires drops the connection without sending a close frame.
+		pong := make(chan error, 1)
+		go func() {
+			pong <- conn.Ping(ctx)
+		}()
+		timer := time.NewTimer(timeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			_ = conn.Close(wsproxysdk.CoordinateStatusIdleTimeout, "ping timed out")
+			return
+		case err := <-pong:
+			timer.Stop()
+			if err != nil {
+				return
+			}
+		}
+		_ = nc.SetReadDeadline(time.Now().Add(proxyCoordinateReadTimeout(timeout)))
 	}
 }
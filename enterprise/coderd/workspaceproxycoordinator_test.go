@@ -1,7 +1,12 @@
 package coderd_test
 
 import (
+	"bufio"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
 	"net/netip"
 	"testing"
 	"time"
@@ -10,6 +15,7 @@ import (
 	"github.com/moby/moby/pkg/namesgenerator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
 	"tailscale.com/types/key"
 
 	"cdr.dev/slog/sloggers/slogtest"
@@ -156,3 +162,145 @@ func Test_agentIsLegacy(t *testing.T) {
 		assert.False(t, legacyRes.Legacy)
 	})
 }
+
+func Test_workspaceProxyCoordinate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("IdleTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		coordinateURL, headers := proxyCoordinateRequest(ctx, t, 100*time.Millisecond)
+
+		// Upgrade by hand so nothing answers the server's pings, as the
+		// websocket library does while reading.
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, coordinateURL, nil)
+		require.NoError(t, err)
+		req.Header = headers
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+		// Then: the server closes the silent connection with the idle
+		// timeout status.
+		status := make(chan websocket.StatusCode, 1)
+		go func() {
+			status <- readCloseStatus(t, res.Body)
+		}()
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the connection to be closed")
+		case s := <-status:
+			require.Equal(t, wsproxysdk.CoordinateStatusIdleTimeout, s)
+		}
+	})
+
+	t.Run("InvalidMessage", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		conn := dialProxyCoordinate(ctx, t, 0)
+
+		err := conn.Write(ctx, websocket.MessageText, []byte(`{"type": 99}`))
+		require.NoError(t, err)
+
+		_, _, err = conn.Read(ctx)
+		require.Equal(t, websocket.StatusPolicyViolation, websocket.CloseStatus(err), err)
+	})
+}
+
+// dialProxyCoordinate dials the coordinate endpoint as a new workspace proxy.
+func dialProxyCoordinate(ctx context.Context, t *testing.T, idleTimeout time.Duration) *websocket.Conn {
+	t.Helper()
+
+	coordinateURL, headers := proxyCoordinateRequest(ctx, t, idleTimeout)
+	//nolint:bodyclose
+	conn, _, err := websocket.Dial(ctx, coordinateURL, &websocket.DialOptions{
+		HTTPHeader: headers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+	})
+	return conn
+}
+
+// proxyCoordinateRequest creates a new workspace proxy and returns the URL
+// and headers to dial the coordinate endpoint as it.
+func proxyCoordinateRequest(ctx context.Context, t *testing.T, idleTimeout time.Duration) (string, http.Header) {
+	t.Helper()
+
+	dv := coderdtest.DeploymentValues(t)
+	dv.Experiments = []string{
+		string(codersdk.ExperimentMoons),
+		"*",
+	}
+	client, _ := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			DeploymentValues: dv,
+		},
+		ProxyCoordinateIdleTimeout: idleTimeout,
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+
+	proxyRes, err := client.CreateWorkspaceProxy(ctx, codersdk.CreateWorkspaceProxyRequest{
+		Name: namesgenerator.GetRandomName(1),
+		Icon: "/emojis/flag.png",
+	})
+	require.NoError(t, err)
+	proxyClient := wsproxysdk.New(client.URL)
+	proxyClient.SetSessionToken(proxyRes.ProxyToken)
+
+	coordinateURL, err := client.URL.Parse("/api/v2/workspaceproxies/me/coordinate")
+	require.NoError(t, err)
+	headers := http.Header{}
+	headers.Set(proxyClient.SDKClient.SessionTokenHeader, proxyClient.SessionToken())
+	return coordinateURL.String(), headers
+}
+
+// readCloseStatus reads unmasked server frames off a raw websocket
+// connection, ignoring pings, and returns the status of the close frame.
+func readCloseStatus(t *testing.T, r io.Reader) websocket.StatusCode {
+	br := bufio.NewReader(r)
+	for {
+		var header [2]byte
+		_, err := io.ReadFull(br, header[:])
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		length := uint64(header[1] & 0x7f)
+		switch length {
+		case 126:
+			var ext [2]byte
+			_, err = io.ReadFull(br, ext[:])
+			length = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			_, err = io.ReadFull(br, ext[:])
+			length = binary.BigEndian.Uint64(ext[:])
+		}
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		payload := make([]byte, length)
+		_, err = io.ReadFull(br, payload)
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		// 0x8 is the close opcode.
+		if header[0]&0x0f == 0x8 && len(payload) >= 2 {
+			return websocket.StatusCode(binary.BigEndian.Uint16(payload))
+		}
+	}
+}
@@ -45,7 +45,9 @@ func (c *committer) CommitQuota(
 	)
 	err = c.Database.InTx(func(s database.Store) error {
 		var err error
-		consumed, err = s.GetQuotaConsumedForUser(ctx, workspace.OwnerID)
+		consumed, err = s.GetQuotaConsumedForUser(ctx, database.GetQuotaConsumedForUserParams{
+			OwnerID: workspace.OwnerID,
+		})
 		if err != nil {
 			return err
 		}
@@ -133,7 +135,9 @@ func (api *API) workspaceQuota(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	quotaConsumed, err := api.Database.GetQuotaConsumedForUser(r.Context(), user.ID)
+	quotaConsumed, err := api.Database.GetQuotaConsumedForUser(r.Context(), database.GetQuotaConsumedForUserParams{
+		OwnerID: user.ID,
+	})
 	if err != nil {
 		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Failed to get consumed",
@@ -13,6 +13,10 @@ import (
 	agpl "github.com/coder/coder/tailnet"
 )
 
+// ErrInvalidCoordinateMessage is returned by ServeWorkspaceProxy when the
+// proxy sends a message that can't be decoded or has an unknown type.
+var ErrInvalidCoordinateMessage = xerrors.New("invalid coordinate message")
+
 func ServeWorkspaceProxy(ctx context.Context, conn net.Conn, ma agpl.MultiAgentConn) error {
 	go func() {
 		err := forwardNodesToWorkspaceProxy(ctx, conn, ma)
@@ -26,6 +30,13 @@ func ServeWorkspaceProxy(ctx context.Context, conn net.Conn, ma agpl.MultiAgentC
 		var msg wsproxysdk.CoordinateMessage
 		err := decoder.Decode(&msg)
 		if err != nil {
+			var (
+				syntaxErr *json.SyntaxError
+				typeErr   *json.UnmarshalTypeError
+			)
+			if xerrors.As(err, &syntaxErr) || xerrors.As(err, &typeErr) {
+				return xerrors.Errorf("read json: %s: %w", err, ErrInvalidCoordinateMessage)
+			}
 			return xerrors.Errorf("read json: %w", err)
 		}
 
@@ -47,7 +58,7 @@ func ServeWorkspaceProxy(ctx context.Context, conn net.Conn, ma agpl.MultiAgentC
 			}
 
 		default:
-			return xerrors.Errorf("unknown message type %q", msg.Type)
+			return xerrors.Errorf("unknown message type %q: %w", msg.Type, ErrInvalidCoordinateMessage)
 		}
 	}
 }
@@ -31,7 +31,7 @@ func (p *TokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *ht
 	appReq := issueReq.AppRequest.Normalize()
 	err := appReq.Validate()
 	if err != nil {
-		workspaceapps.WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "invalid app request")
+		workspaceapps.WriteWorkspaceApp500(p.Logger, p.DashboardURL, workspaceapps.ErrorPageBranding{}, rw, r, &appReq, err, "invalid app request")
 		return nil, "", false
 	}
 	issueReq.AppRequest = appReq
@@ -44,13 +44,13 @@ func (p *TokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *ht
 	// Check that it verifies properly and matches the string.
 	token, err := p.SecurityKey.VerifySignedToken(resp.SignedTokenStr)
 	if err != nil {
-		workspaceapps.WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "failed to verify newly generated signed token")
+		workspaceapps.WriteWorkspaceApp500(p.Logger, p.DashboardURL, workspaceapps.ErrorPageBranding{}, rw, r, &appReq, err, "failed to verify newly generated signed token")
 		return nil, "", false
 	}
 
 	// Check that it matches the request.
 	if !token.MatchesRequest(appReq) {
-		workspaceapps.WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "newly generated signed token does not match request")
+		workspaceapps.WriteWorkspaceApp500(p.Logger, p.DashboardURL, workspaceapps.ErrorPageBranding{}, rw, r, &appReq, err, "newly generated signed token does not match request")
 		return nil, "", false
 	}
 
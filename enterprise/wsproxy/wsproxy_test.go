@@ -150,15 +150,17 @@ func TestDERP(t *testing.T) {
 			return false
 		}
 
-		// The first 3 regions should be healthy.
-		for _, r := range regions[:4] {
+		// Proxies are sorted by name, so look up the one that is never
+		// started rather than relying on its position.
+		for _, r := range regions {
+			if r.Name == "never-started-proxy" {
+				assert.False(t, r.Healthy)
+				continue
+			}
 			if !r.Healthy {
 				return false
 			}
 		}
-
-		// The last region should never be healthy.
-		assert.False(t, regions[4].Healthy)
 		return true
 	}, testutil.WaitLong, testutil.IntervalMedium)
 
@@ -407,6 +407,15 @@ type CoordinateNodes struct {
 	Nodes []*tailnet.Node
 }
 
+// CoordinateStatusIdleTimeout is the close status of a coordinate
+// connection that stopped answering pings. It's in the range reserved for
+// applications.
+const CoordinateStatusIdleTimeout websocket.StatusCode = 4000
+
+// updateSelfCoalesceWindow is how long self node updates are held so that
+// bursts of updates are sent to the coordinator once.
+const updateSelfCoalesceWindow = 100 * time.Millisecond
+
 func (c *Client) DialCoordinator(ctx context.Context) (tailnet.MultiAgentConn, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
@@ -448,6 +457,9 @@ func (c *Client) DialCoordinator(ctx context.Context) (tailnet.MultiAgentConn, e
 		OnUnsubscribe:     rma.OnUnsubscribe,
 		OnNodeUpdate:      rma.OnNodeUpdate,
 		OnRemove:          func(uuid.UUID) { conn.Close(websocket.StatusGoingAway, "closed") },
+		// Nodes change in bursts while connecting, so only send the latest
+		// one to save round trips to the coordinator.
+		UpdateSelfCoalesceWindow: updateSelfCoalesceWindow,
 	}).Init()
 
 	go func() {
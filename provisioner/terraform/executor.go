@@ -36,7 +36,7 @@ type executor struct {
 func (e *executor) basicEnv() []string {
 	// Required for "terraform init" to find "git" to
 	// clone Terraform modules.
-	env := safeEnviron()
+	env := filterEnv(safeEnviron(), e.server.envAllow)
 	// Only Linux reliably works with the Terraform plugin
 	// cache directory. It's unknown why this is.
 	if e.cachePath != "" && runtime.GOOS == "linux" {
@@ -194,13 +194,19 @@ func (e *executor) init(ctx, killCtx context.Context, logr logSink) error {
 		<-doneErr
 	}()
 
+	return e.execWriteOutput(ctx, killCtx, e.initArgs(), e.basicEnv(), outWriter, errWriter)
+}
+
+func (e *executor) initArgs() []string {
 	args := []string{
 		"init",
 		"-no-color",
 		"-input=false",
 	}
-
-	return e.execWriteOutput(ctx, killCtx, args, e.basicEnv(), outWriter, errWriter)
+	if e.server.roLockfile {
+		args = append(args, "-lockfile=readonly")
+	}
+	return args
 }
 
 // revive:disable-next-line:flag-parameter
@@ -227,7 +233,7 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 		args = append(args, "-var", variable)
 	}
 
-	outWriter, doneOut := provisionLogWriter(logr)
+	outWriter, doneOut := provisionLogWriter(logr, nil)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
 		_ = outWriter.Close()
@@ -357,7 +363,8 @@ func (e *executor) apply(
 		planFile.Name(),
 	}
 
-	outWriter, doneOut := provisionLogWriter(logr)
+	changes := &proto.ResourceChanges{}
+	outWriter, doneOut := provisionLogWriter(logr, changes)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
 		_ = outWriter.Close()
@@ -370,14 +377,16 @@ func (e *executor) apply(
 	if err != nil {
 		return nil, xerrors.Errorf("terraform apply: %w", err)
 	}
+	// Wait for the logs to be processed so the change summary is complete.
+	<-doneOut
 	state, err := e.stateResources(ctx, killCtx)
 	if err != nil {
 		return nil, err
 	}
 	statefilePath := filepath.Join(e.workdir, "terraform.tfstate")
-	stateContent, err := os.ReadFile(statefilePath)
+	stateContent, err := e.readStateFile(statefilePath)
 	if err != nil {
-		return nil, xerrors.Errorf("read statefile %q: %w", statefilePath, err)
+		return nil, err
 	}
 	return &proto.Provision_Response{
 		Type: &proto.Provision_Response_Complete{
@@ -386,11 +395,41 @@ func (e *executor) apply(
 				Resources:        state.Resources,
 				GitAuthProviders: state.GitAuthProviders,
 				State:            stateContent,
+				ResourceChanges:  changes,
 			},
 		},
 	}, nil
 }
 
+// errStateTooLarge is returned when the state file exceeds the configured
+// maximum size.
+var errStateTooLarge = xerrors.New("terraform state exceeds maximum size")
+
+// readStateFile reads the state file at path, refusing to load it if it's
+// larger than the server's maximum state size.
+func (e *executor) readStateFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, xerrors.Errorf("open statefile %q: %w", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if e.server.maxState > 0 {
+		// Read one byte past the limit so an oversized file is detected
+		// without loading all of it.
+		r = io.LimitReader(f, e.server.maxState+1)
+	}
+	stateContent, err := io.ReadAll(r)
+	if err != nil {
+		return nil, xerrors.Errorf("read statefile %q: %w", path, err)
+	}
+	if e.server.maxState > 0 && int64(len(stateContent)) > e.server.maxState {
+		return nil, xerrors.Errorf("statefile %q exceeds limit of %d bytes: %w", path, e.server.maxState, errStateTooLarge)
+	}
+	return stateContent, nil
+}
+
 // stateResources must only be called while the lock is held.
 func (e *executor) stateResources(ctx, killCtx context.Context) (*State, error) {
 	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
@@ -520,15 +559,16 @@ func readAndLog(sink logSink, r io.Reader, done chan<- any, level proto.LogLevel
 
 // provisionLogWriter creates a WriteCloser that will log each JSON formatted terraform log.  The WriteCloser must be
 // closed by the caller to end logging, after which the returned channel will be closed to indicate that logging of the
-// written data has finished.  Failure to close the WriteCloser will leak a goroutine.
-func provisionLogWriter(sink logSink) (io.WriteCloser, <-chan any) {
+// written data has finished.  Failure to close the WriteCloser will leak a goroutine.  If changes is not nil, it's
+// populated from the change summary Terraform logs and must not be read until the channel is closed.
+func provisionLogWriter(sink logSink, changes *proto.ResourceChanges) (io.WriteCloser, <-chan any) {
 	r, w := io.Pipe()
 	done := make(chan any)
-	go provisionReadAndLog(sink, r, done)
+	go provisionReadAndLog(sink, r, done, changes)
 	return w, done
 }
 
-func provisionReadAndLog(sink logSink, r io.Reader, done chan<- any) {
+func provisionReadAndLog(sink logSink, r io.Reader, done chan<- any, changes *proto.ResourceChanges) {
 	defer close(done)
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
@@ -561,6 +601,12 @@ func provisionReadAndLog(sink logSink, r io.Reader, done chan<- any) {
 		logLevel := convertTerraformLogLevel(log.Level, sink)
 		sink.Log(&proto.Log{Level: logLevel, Output: log.Message})
 
+		if log.Type == "change_summary" && log.Changes != nil && changes != nil {
+			changes.Added = log.Changes.Add
+			changes.Changed = log.Changes.Change
+			changes.Destroyed = log.Changes.Remove
+		}
+
 		// If the diagnostic is provided, let's provide a bit more info!
 		if log.Diagnostic == nil {
 			continue
@@ -596,8 +642,17 @@ func convertTerraformLogLevel(logLevel string, sink logSink) proto.LogLevel {
 type terraformProvisionLog struct {
 	Level   string `json:"@level"`
 	Message string `json:"@message"`
+	Type    string `json:"type"`
+
+	Changes    *terraformChangeSummary `json:"changes,omitempty"`
+	Diagnostic *tfjson.Diagnostic      `json:"diagnostic,omitempty"`
+}
 
-	Diagnostic *tfjson.Diagnostic `json:"diagnostic,omitempty"`
+// terraformChangeSummary is logged by Terraform once an apply completes.
+type terraformChangeSummary struct {
+	Add    int32 `json:"add"`
+	Change int32 `json:"change"`
+	Remove int32 `json:"remove"`
 }
 
 // syncWriter wraps an io.Writer in a sync.Mutex.
@@ -1,6 +1,8 @@
 package terraform
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -41,3 +43,66 @@ From standing in the English rain`))
 	}
 	require.Equal(t, expected, logr.logs)
 }
+
+func TestReadStateFile_MaxSize(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	err := os.WriteFile(path, make([]byte, 1024), 0o600)
+	require.NoError(t, err)
+
+	e := &executor{server: &server{maxState: 1023}}
+	_, err = e.readStateFile(path)
+	require.ErrorIs(t, err, errStateTooLarge)
+
+	e = &executor{server: &server{maxState: 1024}}
+	state, err := e.readStateFile(path)
+	require.NoError(t, err)
+	require.Len(t, state, 1024)
+
+	_, err = e.readStateFile(filepath.Join(t.TempDir(), "missing.tfstate"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInitArgs_Lockfile(t *testing.T) {
+	t.Parallel()
+
+	e := &executor{server: &server{}}
+	require.NotContains(t, e.initArgs(), "-lockfile=readonly")
+
+	e = &executor{server: &server{roLockfile: true}}
+	require.Contains(t, e.initArgs(), "-lockfile=readonly")
+}
+
+func TestFilterEnv(t *testing.T) {
+	t.Parallel()
+
+	env := []string{"PATH=/bin", "SECRET=hunter2", "HOME=/root", "EMPTY="}
+	require.Equal(t, env, filterEnv(env, nil))
+	require.Equal(t, []string{"PATH=/bin", "EMPTY="}, filterEnv(env, []string{"PATH", "EMPTY", "MISSING"}))
+}
+
+func TestProvisionLogWriter_ChangeSummary(t *testing.T) {
+	t.Parallel()
+
+	logr := &mockLogger{}
+	changes := &proto.ResourceChanges{}
+	writer, doneLogging := provisionLogWriter(logr, changes)
+
+	// Captured from `terraform apply -json`.
+	_, err := writer.Write([]byte(`{"@level":"info","@message":"Terraform 1.5.5","@module":"terraform.ui","terraform":"1.5.5","type":"version","ui":"1.1"}
+{"@level":"info","@message":"null_resource.a: Destroying... [id=1]","@module":"terraform.ui","hook":{"resource":{"addr":"null_resource.a","resource_type":"null_resource","resource_name":"a"},"action":"delete","id_key":"id","id_value":"1"},"type":"apply_start"}
+{"@level":"info","@message":"Apply complete! Resources: 3 added, 1 changed, 0 destroyed.","@module":"terraform.ui","changes":{"add":3,"change":1,"remove":0,"operation":"apply"},"type":"change_summary"}
+{"@level":"info","@message":"Outputs: 0","@module":"terraform.ui","outputs":{},"type":"outputs"}
+`))
+	require.NoError(t, err)
+	err = writer.Close()
+	require.NoError(t, err)
+	<-doneLogging
+
+	require.Equal(t, int32(3), changes.Added)
+	require.Equal(t, int32(1), changes.Changed)
+	require.Equal(t, int32(0), changes.Destroyed)
+	require.Len(t, logr.logs, 4)
+	require.Equal(t, "Apply complete! Resources: 3 added, 1 changed, 0 destroyed.", logr.logs[2].Output)
+}
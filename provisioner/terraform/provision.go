@@ -2,6 +2,7 @@ package terraform
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/provisionersdk"
 	"github.com/coder/coder/provisionersdk/proto"
@@ -17,6 +19,8 @@ import (
 )
 
 // Provision executes `terraform apply` or `terraform plan` for dry runs.
+// A Plan request is plan-only: it never applies, and returns the planned
+// resources along with the plan so a later Apply request can execute it.
 func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 	ctx, span := s.startTrace(stream.Context(), tracing.FuncName())
 	defer span.End()
@@ -142,7 +146,7 @@ func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 		return xerrors.Errorf("initialize terraform: %w", err)
 	}
 	s.logger.Debug(ctx, "ran initialization")
-	env, err := provisionEnv(config, request.GetPlan().GetRichParameterValues(), request.GetPlan().GetGitAuthProviders())
+	env, err := provisionEnv(filterEnv(safeEnviron(), s.envAllow), config, request.GetPlan().GetRichParameterValues(), request.GetPlan().GetGitAuthProviders())
 	if err != nil {
 		return err
 	}
@@ -180,7 +184,13 @@ func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 		errorMessage := err.Error()
 		// Terraform can fail and apply and still need to store it's state.
 		// In this case, we return Complete with an explicit error message.
-		stateData, _ := os.ReadFile(statefilePath)
+		stateData, stateErr := e.readStateFile(statefilePath)
+		if stateErr != nil && !errors.Is(stateErr, os.ErrNotExist) {
+			// Apply may fail before writing any state, but any other
+			// failure means state is being lost and must be reported.
+			s.logger.Error(ctx, "read state after failed apply", slog.Error(stateErr))
+			errorMessage = fmt.Sprintf("%s; %s", errorMessage, stateErr)
+		}
 		return stream.Send(&proto.Provision_Response{
 			Type: &proto.Provision_Response_Complete{
 				Complete: &proto.Provision_Complete{
@@ -201,8 +211,7 @@ func planVars(plan *proto.Provision_Plan) ([]string, error) {
 	return vars, nil
 }
 
-func provisionEnv(config *proto.Provision_Config, richParams []*proto.RichParameterValue, gitAuth []*proto.GitAuthProvider) ([]string, error) {
-	env := safeEnviron()
+func provisionEnv(env []string, config *proto.Provision_Config, richParams []*proto.RichParameterValue, gitAuth []*proto.GitAuthProvider) ([]string, error) {
 	env = append(env,
 		"CODER_AGENT_URL="+config.Metadata.CoderUrl,
 		"CODER_WORKSPACE_TRANSITION="+strings.ToLower(config.Metadata.WorkspaceTransition.String()),
@@ -26,8 +26,9 @@ import (
 )
 
 type provisionerServeOptions struct {
-	binaryPath  string
-	exitTimeout time.Duration
+	binaryPath   string
+	exitTimeout  time.Duration
+	envAllowList []string
 }
 
 func setupProvisioner(t *testing.T, opts *provisionerServeOptions) (context.Context, proto.DRPCProvisionerClient) {
@@ -52,10 +53,11 @@ func setupProvisioner(t *testing.T, opts *provisionerServeOptions) (context.Cont
 			ServeOptions: &provisionersdk.ServeOptions{
 				Listener: server,
 			},
-			BinaryPath:  opts.binaryPath,
-			CachePath:   cachePath,
-			Logger:      slogtest.Make(t, nil).Leveled(slog.LevelDebug),
-			ExitTimeout: opts.exitTimeout,
+			BinaryPath:   opts.binaryPath,
+			CachePath:    cachePath,
+			Logger:       slogtest.Make(t, nil).Leveled(slog.LevelDebug),
+			ExitTimeout:  opts.exitTimeout,
+			EnvAllowList: opts.envAllowList,
 		})
 	}()
 	api := proto.NewDRPCProvisionerClient(client)
@@ -700,6 +702,49 @@ func TestProvision(t *testing.T) {
 	}
 }
 
+func TestProvision_PlanOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx, api := setupProvisioner(t, nil)
+
+	directory := t.TempDir()
+	created := filepath.Join(directory, "created")
+	err := os.WriteFile(filepath.Join(directory, "main.tf"), []byte(fmt.Sprintf(`resource "null_resource" "A" {
+		provisioner "local-exec" {
+			command = "touch %s"
+		}
+	}`, created)), 0o600)
+	require.NoError(t, err)
+
+	response, err := api.Provision(ctx)
+	require.NoError(t, err)
+	err = response.Send(&proto.Provision_Request{
+		Type: &proto.Provision_Request_Plan{
+			Plan: &proto.Provision_Plan{
+				Config: &proto.Provision_Config{
+					Directory: directory,
+					Metadata: &proto.Provision_Metadata{
+						WorkspaceTransition: proto.WorkspaceTransition_START,
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	_, complete := readProvisionLog(t, response)
+
+	// The planned resources are returned...
+	require.Len(t, complete.Resources, 1)
+	require.Equal(t, "A", complete.Resources[0].Name)
+	require.Equal(t, "null_resource", complete.Resources[0].Type)
+	require.NotEmpty(t, complete.Plan)
+	require.Empty(t, complete.State)
+
+	// ...but nothing is applied.
+	require.NoFileExists(t, created)
+	require.NoFileExists(t, filepath.Join(directory, "terraform.tfstate"))
+}
+
 // nolint:paralleltest
 func TestProvision_ExtraEnv(t *testing.T) {
 	// #nosec
@@ -820,3 +865,70 @@ func TestProvision_SafeEnv(t *testing.T) {
 	require.NotContains(t, log, secretValue)
 	require.Contains(t, log, "CODER_")
 }
+
+// nolint:paralleltest
+func TestProvision_EnvAllowList(t *testing.T) {
+	const (
+		allowedValue    = "superautopets"
+		disallowedValue = "oinae3uinxase"
+	)
+
+	t.Setenv("ALLOWED_USER_ENV", allowedValue)
+	t.Setenv("DISALLOWED_USER_ENV", disallowedValue)
+
+	const echoResource = `
+	resource "null_resource" "a" {
+		provisioner "local-exec" {
+		  command = "env"
+		}
+	  }
+
+	`
+
+	ctx, api := setupProvisioner(t, &provisionerServeOptions{
+		// PATH and HOME are needed for Terraform to run its providers.
+		envAllowList: []string{"PATH", "HOME", "ALLOWED_USER_ENV"},
+	})
+
+	directory := t.TempDir()
+	path := filepath.Join(directory, "main.tf")
+	err := os.WriteFile(path, []byte(echoResource), 0o600)
+	require.NoError(t, err)
+
+	config := &proto.Provision_Config{
+		Directory: directory,
+		Metadata: &proto.Provision_Metadata{
+			WorkspaceTransition: proto.WorkspaceTransition_START,
+		},
+	}
+	response, err := api.Provision(ctx)
+	require.NoError(t, err)
+	err = response.Send(&proto.Provision_Request{
+		Type: &proto.Provision_Request_Plan{
+			Plan: &proto.Provision_Plan{
+				Config: config,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, complete := readProvisionLog(t, response)
+
+	response, err = api.Provision(ctx)
+	require.NoError(t, err)
+	err = response.Send(&proto.Provision_Request{
+		Type: &proto.Provision_Request_Apply{
+			Apply: &proto.Provision_Apply{
+				Config: config,
+				Plan:   complete.GetPlan(),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	log, _ := readProvisionLog(t, response)
+	require.Contains(t, log, allowedValue)
+	require.NotContains(t, log, disallowedValue)
+	// Coder-injected variables are always passed.
+	require.Contains(t, log, "CODER_WORKSPACE_TRANSITION")
+}
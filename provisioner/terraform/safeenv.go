@@ -53,3 +53,23 @@ func safeEnviron() []string {
 	}
 	return strippedEnv
 }
+
+// filterEnv returns the variables in env whose names are in allowList. An
+// empty allowList leaves env unchanged.
+func filterEnv(env []string, allowList []string) []string {
+	if len(allowList) == 0 {
+		return env
+	}
+	allowed := make(map[string]struct{}, len(allowList))
+	for _, name := range allowList {
+		allowed[name] = struct{}{}
+	}
+	filteredEnv := make([]string, 0, len(env))
+	for _, e := range env {
+		name, _, _ := strings.Cut(e, "=")
+		if _, ok := allowed[name]; ok {
+			filteredEnv = append(filteredEnv, e)
+		}
+	}
+	return filteredEnv
+}
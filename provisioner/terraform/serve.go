@@ -38,6 +38,23 @@ type ServeOptions struct {
 	// be kept less than the value that Coder uses to mark hung jobs as failed,
 	// which is 5 minutes (see unhanger package).
 	ExitTimeout time.Duration
+
+	// MaxStateSizeBytes limits the size of the Terraform state file that is
+	// read back after an apply. Larger state is rejected with an error
+	// instead of being loaded into memory. Zero means no limit.
+	MaxStateSizeBytes int64
+
+	// EnvAllowList restricts the environment variables inherited by the
+	// Terraform process to those named. Variables injected by Coder are
+	// always passed. If empty, the whole environment (minus CODER_
+	// variables) is inherited.
+	EnvAllowList []string
+
+	// ReadOnlyLockfile runs "terraform init" with -lockfile=readonly so the
+	// dependency lock file is verified but never modified. This avoids races
+	// between concurrent provisions sharing a CachePath and makes init
+	// reproducible, at the cost of failing if the lock file is incomplete.
+	ReadOnlyLockfile bool
 }
 
 func absoluteBinaryPath(ctx context.Context) (string, error) {
@@ -103,6 +120,9 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 		logger:      options.Logger,
 		tracer:      options.Tracer,
 		exitTimeout: options.ExitTimeout,
+		maxState:    options.MaxStateSizeBytes,
+		envAllow:    options.EnvAllowList,
+		roLockfile:  options.ReadOnlyLockfile,
 	}, options.ServeOptions)
 }
 
@@ -113,6 +133,9 @@ type server struct {
 	logger      slog.Logger
 	tracer      trace.Tracer
 	exitTimeout time.Duration
+	maxState    int64
+	envAllow    []string
+	roLockfile  bool
 }
 
 func (s *server) startTrace(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
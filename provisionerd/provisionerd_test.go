@@ -509,6 +509,73 @@ func TestProvisionerd(t *testing.T) {
 		assert.True(t, didComplete.Load(), "should complete the job")
 	})
 
+	t.Run("WorkspaceBuildResourceChanges", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			didSummarize  atomic.Bool
+			didAcquireJob atomic.Bool
+			completeChan  = make(chan struct{})
+			completeOnce  sync.Once
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+					if !didAcquireJob.CAS(false, true) {
+						completeOnce.Do(func() { close(completeChan) })
+						return &proto.AcquiredJob{}, nil
+					}
+
+					return &proto.AcquiredJob{
+						JobId:       "test",
+						Provisioner: "someprovisioner",
+						TemplateSourceArchive: createTar(t, map[string]string{
+							"test.txt": "content",
+						}),
+						Type: &proto.AcquiredJob_WorkspaceBuild_{
+							WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+								Metadata: &sdkproto.Provision_Metadata{},
+							},
+						},
+					}, nil
+				},
+				updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+					for _, log := range update.Logs {
+						if log.Output == "3 added, 1 changed, 0 destroyed." {
+							didSummarize.Store(true)
+						}
+					}
+					return &proto.UpdateJobResponse{}, nil
+				},
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.Provisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+					return stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Complete{
+							Complete: &sdkproto.Provision_Complete{
+								ResourceChanges: &sdkproto.ResourceChanges{
+									Added:   3,
+									Changed: 1,
+								},
+							},
+						},
+					})
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		assert.True(t, didSummarize.Load(), "should log the resource change summary")
+	})
+
 	t.Run("WorkspaceBuildQuotaExceeded", func(t *testing.T) {
 		t.Parallel()
 		done := make(chan struct{})
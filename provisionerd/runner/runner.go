@@ -1079,6 +1079,15 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 	if failed != nil {
 		return nil, failed
 	}
+	if changes := completedApply.GetResourceChanges(); changes != nil {
+		r.queueLog(ctx, &proto.Log{
+			Source:    proto.LogSource_PROVISIONER_DAEMON,
+			Level:     sdkproto.LogLevel_INFO,
+			Stage:     applyStage,
+			CreatedAt: time.Now().UnixMilli(),
+			Output:    fmt.Sprintf("%d added, %d changed, %d destroyed.", changes.Added, changes.Changed, changes.Destroyed),
+		})
+	}
 	r.flushQueuedLogs(ctx)
 
 	return &proto.CompletedJob{
@@ -1392,6 +1392,70 @@ func (*Provision) Descriptor() ([]byte, []int) {
 	return file_provisionersdk_proto_provisioner_proto_rawDescGZIP(), []int{14}
 }
 
+// ResourceChanges counts the resources changed by an apply.
+type ResourceChanges struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Added     int32 `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	Changed   int32 `protobuf:"varint,2,opt,name=changed,proto3" json:"changed,omitempty"`
+	Destroyed int32 `protobuf:"varint,3,opt,name=destroyed,proto3" json:"destroyed,omitempty"`
+}
+
+func (x *ResourceChanges) Reset() {
+	*x = ResourceChanges{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResourceChanges) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceChanges) ProtoMessage() {}
+
+func (x *ResourceChanges) ProtoReflect() protoreflect.Message {
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceChanges.ProtoReflect.Descriptor instead.
+func (*ResourceChanges) Descriptor() ([]byte, []int) {
+	return file_provisionersdk_proto_provisioner_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResourceChanges) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *ResourceChanges) GetChanged() int32 {
+	if x != nil {
+		return x.Changed
+	}
+	return 0
+}
+
+func (x *ResourceChanges) GetDestroyed() int32 {
+	if x != nil {
+		return x.Destroyed
+	}
+	return 0
+}
+
 type Agent_Metadata struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1407,7 +1471,7 @@ type Agent_Metadata struct {
 func (x *Agent_Metadata) Reset() {
 	*x = Agent_Metadata{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[15]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1420,7 +1484,7 @@ func (x *Agent_Metadata) String() string {
 func (*Agent_Metadata) ProtoMessage() {}
 
 func (x *Agent_Metadata) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[15]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1485,7 +1549,7 @@ type Resource_Metadata struct {
 func (x *Resource_Metadata) Reset() {
 	*x = Resource_Metadata{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[17]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1498,7 +1562,7 @@ func (x *Resource_Metadata) String() string {
 func (*Resource_Metadata) ProtoMessage() {}
 
 func (x *Resource_Metadata) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[17]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1553,7 +1617,7 @@ type Parse_Request struct {
 func (x *Parse_Request) Reset() {
 	*x = Parse_Request{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[18]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1566,7 +1630,7 @@ func (x *Parse_Request) String() string {
 func (*Parse_Request) ProtoMessage() {}
 
 func (x *Parse_Request) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[18]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1600,7 +1664,7 @@ type Parse_Complete struct {
 func (x *Parse_Complete) Reset() {
 	*x = Parse_Complete{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[19]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1613,7 +1677,7 @@ func (x *Parse_Complete) String() string {
 func (*Parse_Complete) ProtoMessage() {}
 
 func (x *Parse_Complete) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[19]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1651,7 +1715,7 @@ type Parse_Response struct {
 func (x *Parse_Response) Reset() {
 	*x = Parse_Response{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[20]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1664,7 +1728,7 @@ func (x *Parse_Response) String() string {
 func (*Parse_Response) ProtoMessage() {}
 
 func (x *Parse_Response) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[20]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1738,7 +1802,7 @@ type Provision_Metadata struct {
 func (x *Provision_Metadata) Reset() {
 	*x = Provision_Metadata{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[21]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1751,7 +1815,7 @@ func (x *Provision_Metadata) String() string {
 func (*Provision_Metadata) ProtoMessage() {}
 
 func (x *Provision_Metadata) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[21]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1860,7 +1924,7 @@ type Provision_Config struct {
 func (x *Provision_Config) Reset() {
 	*x = Provision_Config{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[22]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1873,7 +1937,7 @@ func (x *Provision_Config) String() string {
 func (*Provision_Config) ProtoMessage() {}
 
 func (x *Provision_Config) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[22]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1931,7 +1995,7 @@ type Provision_Plan struct {
 func (x *Provision_Plan) Reset() {
 	*x = Provision_Plan{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[23]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1944,7 +2008,7 @@ func (x *Provision_Plan) String() string {
 func (*Provision_Plan) ProtoMessage() {}
 
 func (x *Provision_Plan) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[23]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2000,7 +2064,7 @@ type Provision_Apply struct {
 func (x *Provision_Apply) Reset() {
 	*x = Provision_Apply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[24]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2013,7 +2077,7 @@ func (x *Provision_Apply) String() string {
 func (*Provision_Apply) ProtoMessage() {}
 
 func (x *Provision_Apply) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[24]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2052,7 +2116,7 @@ type Provision_Cancel struct {
 func (x *Provision_Cancel) Reset() {
 	*x = Provision_Cancel{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[25]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[26]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2065,7 +2129,7 @@ func (x *Provision_Cancel) String() string {
 func (*Provision_Cancel) ProtoMessage() {}
 
 func (x *Provision_Cancel) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[25]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[26]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2097,7 +2161,7 @@ type Provision_Request struct {
 func (x *Provision_Request) Reset() {
 	*x = Provision_Request{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[26]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[27]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2110,7 +2174,7 @@ func (x *Provision_Request) String() string {
 func (*Provision_Request) ProtoMessage() {}
 
 func (x *Provision_Request) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[26]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[27]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2187,12 +2251,13 @@ type Provision_Complete struct {
 	Parameters       []*RichParameter `protobuf:"bytes,4,rep,name=parameters,proto3" json:"parameters,omitempty"`
 	GitAuthProviders []string         `protobuf:"bytes,5,rep,name=git_auth_providers,json=gitAuthProviders,proto3" json:"git_auth_providers,omitempty"`
 	Plan             []byte           `protobuf:"bytes,6,opt,name=plan,proto3" json:"plan,omitempty"`
+	ResourceChanges  *ResourceChanges `protobuf:"bytes,7,opt,name=resource_changes,json=resourceChanges,proto3" json:"resource_changes,omitempty"`
 }
 
 func (x *Provision_Complete) Reset() {
 	*x = Provision_Complete{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[27]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2205,7 +2270,7 @@ func (x *Provision_Complete) String() string {
 func (*Provision_Complete) ProtoMessage() {}
 
 func (x *Provision_Complete) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[27]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2263,6 +2328,13 @@ func (x *Provision_Complete) GetPlan() []byte {
 	return nil
 }
 
+func (x *Provision_Complete) GetResourceChanges() *ResourceChanges {
+	if x != nil {
+		return x.ResourceChanges
+	}
+	return nil
+}
+
 type Provision_Response struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -2278,7 +2350,7 @@ type Provision_Response struct {
 func (x *Provision_Response) Reset() {
 	*x = Provision_Response{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[28]
+		mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[29]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -2291,7 +2363,7 @@ func (x *Provision_Response) String() string {
 func (*Provision_Response) ProtoMessage() {}
 
 func (x *Provision_Response) ProtoReflect() protoreflect.Message {
-	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[28]
+	mi := &file_provisionersdk_proto_provisioner_proto_msgTypes[29]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2560,7 +2632,7 @@ var file_provisionersdk_proto_provisioner_proto_rawDesc = []byte{
 	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
 	0x65, 0x72, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
 	0x65, 0x48, 0x00, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x06, 0x0a,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x91, 0x0d, 0x0a, 0x09, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0xda, 0x0d, 0x0a, 0x09, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73,
 	0x69, 0x6f, 0x6e, 0x1a, 0xae, 0x04, 0x0a, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
 	0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x55, 0x72, 0x6c, 0x12, 0x53, 0x0a,
@@ -2643,7 +2715,7 @@ var file_provisionersdk_proto_provisioner_proto_rawDesc = []byte{
 	0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65,
 	0x72, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x61, 0x6e, 0x63,
 	0x65, 0x6c, 0x48, 0x00, 0x52, 0x06, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x42, 0x06, 0x0a, 0x04,
-	0x74, 0x79, 0x70, 0x65, 0x1a, 0xe9, 0x01, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
+	0x74, 0x79, 0x70, 0x65, 0x1a, 0xb2, 0x02, 0x0a, 0x08, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
 	0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
 	0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
 	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x33, 0x0a,
@@ -2658,39 +2730,50 @@ var file_provisionersdk_proto_provisioner_proto_rawDesc = []byte{
 	0x64, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x67, 0x69, 0x74, 0x41,
 	0x75, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x73, 0x12, 0x12, 0x0a, 0x04,
 	0x70, 0x6c, 0x61, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x70, 0x6c, 0x61, 0x6e,
-	0x1a, 0x77, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x03,
-	0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x76,
-	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e, 0x4c, 0x6f, 0x67, 0x48, 0x00, 0x52, 0x03, 0x6c,
-	0x6f, 0x67, 0x12, 0x3d, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
-	0x65, 0x72, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x6f, 0x6d,
-	0x70, 0x6c, 0x65, 0x74, 0x65, 0x48, 0x00, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
-	0x65, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x2a, 0x3f, 0x0a, 0x08, 0x4c, 0x6f, 0x67,
-	0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x09, 0x0a, 0x05, 0x54, 0x52, 0x41, 0x43, 0x45, 0x10, 0x00,
-	0x12, 0x09, 0x0a, 0x05, 0x44, 0x45, 0x42, 0x55, 0x47, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x49,
-	0x4e, 0x46, 0x4f, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x41, 0x52, 0x4e, 0x10, 0x03, 0x12,
-	0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x04, 0x2a, 0x3b, 0x0a, 0x0f, 0x41, 0x70,
-	0x70, 0x53, 0x68, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x09, 0x0a,
-	0x05, 0x4f, 0x57, 0x4e, 0x45, 0x52, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x55, 0x54, 0x48,
-	0x45, 0x4e, 0x54, 0x49, 0x43, 0x41, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x50,
-	0x55, 0x42, 0x4c, 0x49, 0x43, 0x10, 0x02, 0x2a, 0x37, 0x0a, 0x13, 0x57, 0x6f, 0x72, 0x6b, 0x73,
-	0x70, 0x61, 0x63, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x09,
-	0x0a, 0x05, 0x53, 0x54, 0x41, 0x52, 0x54, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x54, 0x4f,
-	0x50, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x53, 0x54, 0x52, 0x4f, 0x59, 0x10, 0x02,
-	0x32, 0xa3, 0x01, 0x0a, 0x0b, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72,
-	0x12, 0x42, 0x0a, 0x05, 0x50, 0x61, 0x72, 0x73, 0x65, 0x12, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x76,
-	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x2e, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f,
-	0x6e, 0x65, 0x72, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x09, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f,
-	0x6e, 0x12, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e,
-	0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e,
-	0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
-	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x64, 0x65, 0x72,
-	0x2f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x73, 0x64, 0x6b, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x12, 0x47, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x63, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x70, 0x72, 0x6f,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x1a, 0x77, 0x0a, 0x08, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x03, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72,
+	0x2e, 0x4c, 0x6f, 0x67, 0x48, 0x00, 0x52, 0x03, 0x6c, 0x6f, 0x67, 0x12, 0x3d, 0x0a, 0x08, 0x63,
+	0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e,
+	0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x72, 0x6f, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x48, 0x00,
+	0x52, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x22, 0x5f, 0x0a, 0x0f, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x73, 0x74, 0x72, 0x6f, 0x79,
+	0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x64, 0x65, 0x73, 0x74, 0x72, 0x6f,
+	0x79, 0x65, 0x64, 0x2a, 0x3f, 0x0a, 0x08, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12,
+	0x09, 0x0a, 0x05, 0x54, 0x52, 0x41, 0x43, 0x45, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x44, 0x45,
+	0x42, 0x55, 0x47, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x4e, 0x46, 0x4f, 0x10, 0x02, 0x12,
+	0x08, 0x0a, 0x04, 0x57, 0x41, 0x52, 0x4e, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x52, 0x52,
+	0x4f, 0x52, 0x10, 0x04, 0x2a, 0x3b, 0x0a, 0x0f, 0x41, 0x70, 0x70, 0x53, 0x68, 0x61, 0x72, 0x69,
+	0x6e, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x09, 0x0a, 0x05, 0x4f, 0x57, 0x4e, 0x45, 0x52,
+	0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x55, 0x54, 0x48, 0x45, 0x4e, 0x54, 0x49, 0x43, 0x41,
+	0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x50, 0x55, 0x42, 0x4c, 0x49, 0x43, 0x10,
+	0x02, 0x2a, 0x37, 0x0a, 0x13, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x54, 0x41, 0x52,
+	0x54, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x54, 0x4f, 0x50, 0x10, 0x01, 0x12, 0x0b, 0x0a,
+	0x07, 0x44, 0x45, 0x53, 0x54, 0x52, 0x4f, 0x59, 0x10, 0x02, 0x32, 0xa3, 0x01, 0x0a, 0x0b, 0x50,
+	0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x12, 0x42, 0x0a, 0x05, 0x50, 0x61,
+	0x72, 0x73, 0x65, 0x12, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65,
+	0x72, 0x2e, 0x50, 0x61, 0x72, 0x73, 0x65, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x61,
+	0x72, 0x73, 0x65, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x50,
+	0x0a, 0x09, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x2e, 0x70, 0x72,
+	0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x72,
+	0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01,
+	0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x73, 0x64, 0x6b, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -2706,7 +2789,7 @@ func file_provisionersdk_proto_provisioner_proto_rawDescGZIP() []byte {
 }
 
 var file_provisionersdk_proto_provisioner_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_provisionersdk_proto_provisioner_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_provisionersdk_proto_provisioner_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_provisionersdk_proto_provisioner_proto_goTypes = []interface{}{
 	(LogLevel)(0),                // 0: provisioner.LogLevel
 	(AppSharingLevel)(0),         // 1: provisioner.AppSharingLevel
@@ -2726,57 +2809,59 @@ var file_provisionersdk_proto_provisioner_proto_goTypes = []interface{}{
 	(*Resource)(nil),             // 15: provisioner.Resource
 	(*Parse)(nil),                // 16: provisioner.Parse
 	(*Provision)(nil),            // 17: provisioner.Provision
-	(*Agent_Metadata)(nil),       // 18: provisioner.Agent.Metadata
-	nil,                          // 19: provisioner.Agent.EnvEntry
-	(*Resource_Metadata)(nil),    // 20: provisioner.Resource.Metadata
-	(*Parse_Request)(nil),        // 21: provisioner.Parse.Request
-	(*Parse_Complete)(nil),       // 22: provisioner.Parse.Complete
-	(*Parse_Response)(nil),       // 23: provisioner.Parse.Response
-	(*Provision_Metadata)(nil),   // 24: provisioner.Provision.Metadata
-	(*Provision_Config)(nil),     // 25: provisioner.Provision.Config
-	(*Provision_Plan)(nil),       // 26: provisioner.Provision.Plan
-	(*Provision_Apply)(nil),      // 27: provisioner.Provision.Apply
-	(*Provision_Cancel)(nil),     // 28: provisioner.Provision.Cancel
-	(*Provision_Request)(nil),    // 29: provisioner.Provision.Request
-	(*Provision_Complete)(nil),   // 30: provisioner.Provision.Complete
-	(*Provision_Response)(nil),   // 31: provisioner.Provision.Response
+	(*ResourceChanges)(nil),      // 18: provisioner.ResourceChanges
+	(*Agent_Metadata)(nil),       // 19: provisioner.Agent.Metadata
+	nil,                          // 20: provisioner.Agent.EnvEntry
+	(*Resource_Metadata)(nil),    // 21: provisioner.Resource.Metadata
+	(*Parse_Request)(nil),        // 22: provisioner.Parse.Request
+	(*Parse_Complete)(nil),       // 23: provisioner.Parse.Complete
+	(*Parse_Response)(nil),       // 24: provisioner.Parse.Response
+	(*Provision_Metadata)(nil),   // 25: provisioner.Provision.Metadata
+	(*Provision_Config)(nil),     // 26: provisioner.Provision.Config
+	(*Provision_Plan)(nil),       // 27: provisioner.Provision.Plan
+	(*Provision_Apply)(nil),      // 28: provisioner.Provision.Apply
+	(*Provision_Cancel)(nil),     // 29: provisioner.Provision.Cancel
+	(*Provision_Request)(nil),    // 30: provisioner.Provision.Request
+	(*Provision_Complete)(nil),   // 31: provisioner.Provision.Complete
+	(*Provision_Response)(nil),   // 32: provisioner.Provision.Response
 }
 var file_provisionersdk_proto_provisioner_proto_depIdxs = []int32{
 	5,  // 0: provisioner.RichParameter.options:type_name -> provisioner.RichParameterOption
 	0,  // 1: provisioner.Log.level:type_name -> provisioner.LogLevel
-	19, // 2: provisioner.Agent.env:type_name -> provisioner.Agent.EnvEntry
+	20, // 2: provisioner.Agent.env:type_name -> provisioner.Agent.EnvEntry
 	13, // 3: provisioner.Agent.apps:type_name -> provisioner.App
-	18, // 4: provisioner.Agent.metadata:type_name -> provisioner.Agent.Metadata
+	19, // 4: provisioner.Agent.metadata:type_name -> provisioner.Agent.Metadata
 	14, // 5: provisioner.App.healthcheck:type_name -> provisioner.Healthcheck
 	1,  // 6: provisioner.App.sharing_level:type_name -> provisioner.AppSharingLevel
 	12, // 7: provisioner.Resource.agents:type_name -> provisioner.Agent
-	20, // 8: provisioner.Resource.metadata:type_name -> provisioner.Resource.Metadata
+	21, // 8: provisioner.Resource.metadata:type_name -> provisioner.Resource.Metadata
 	4,  // 9: provisioner.Parse.Complete.template_variables:type_name -> provisioner.TemplateVariable
 	9,  // 10: provisioner.Parse.Response.log:type_name -> provisioner.Log
-	22, // 11: provisioner.Parse.Response.complete:type_name -> provisioner.Parse.Complete
+	23, // 11: provisioner.Parse.Response.complete:type_name -> provisioner.Parse.Complete
 	2,  // 12: provisioner.Provision.Metadata.workspace_transition:type_name -> provisioner.WorkspaceTransition
-	24, // 13: provisioner.Provision.Config.metadata:type_name -> provisioner.Provision.Metadata
-	25, // 14: provisioner.Provision.Plan.config:type_name -> provisioner.Provision.Config
+	25, // 13: provisioner.Provision.Config.metadata:type_name -> provisioner.Provision.Metadata
+	26, // 14: provisioner.Provision.Plan.config:type_name -> provisioner.Provision.Config
 	7,  // 15: provisioner.Provision.Plan.rich_parameter_values:type_name -> provisioner.RichParameterValue
 	8,  // 16: provisioner.Provision.Plan.variable_values:type_name -> provisioner.VariableValue
 	11, // 17: provisioner.Provision.Plan.git_auth_providers:type_name -> provisioner.GitAuthProvider
-	25, // 18: provisioner.Provision.Apply.config:type_name -> provisioner.Provision.Config
-	26, // 19: provisioner.Provision.Request.plan:type_name -> provisioner.Provision.Plan
-	27, // 20: provisioner.Provision.Request.apply:type_name -> provisioner.Provision.Apply
-	28, // 21: provisioner.Provision.Request.cancel:type_name -> provisioner.Provision.Cancel
+	26, // 18: provisioner.Provision.Apply.config:type_name -> provisioner.Provision.Config
+	27, // 19: provisioner.Provision.Request.plan:type_name -> provisioner.Provision.Plan
+	28, // 20: provisioner.Provision.Request.apply:type_name -> provisioner.Provision.Apply
+	29, // 21: provisioner.Provision.Request.cancel:type_name -> provisioner.Provision.Cancel
 	15, // 22: provisioner.Provision.Complete.resources:type_name -> provisioner.Resource
 	6,  // 23: provisioner.Provision.Complete.parameters:type_name -> provisioner.RichParameter
-	9,  // 24: provisioner.Provision.Response.log:type_name -> provisioner.Log
-	30, // 25: provisioner.Provision.Response.complete:type_name -> provisioner.Provision.Complete
-	21, // 26: provisioner.Provisioner.Parse:input_type -> provisioner.Parse.Request
-	29, // 27: provisioner.Provisioner.Provision:input_type -> provisioner.Provision.Request
-	23, // 28: provisioner.Provisioner.Parse:output_type -> provisioner.Parse.Response
-	31, // 29: provisioner.Provisioner.Provision:output_type -> provisioner.Provision.Response
-	28, // [28:30] is the sub-list for method output_type
-	26, // [26:28] is the sub-list for method input_type
-	26, // [26:26] is the sub-list for extension type_name
-	26, // [26:26] is the sub-list for extension extendee
-	0,  // [0:26] is the sub-list for field type_name
+	18, // 24: provisioner.Provision.Complete.resource_changes:type_name -> provisioner.ResourceChanges
+	9,  // 25: provisioner.Provision.Response.log:type_name -> provisioner.Log
+	31, // 26: provisioner.Provision.Response.complete:type_name -> provisioner.Provision.Complete
+	22, // 27: provisioner.Provisioner.Parse:input_type -> provisioner.Parse.Request
+	30, // 28: provisioner.Provisioner.Provision:input_type -> provisioner.Provision.Request
+	24, // 29: provisioner.Provisioner.Parse:output_type -> provisioner.Parse.Response
+	32, // 30: provisioner.Provisioner.Provision:output_type -> provisioner.Provision.Response
+	29, // [29:31] is the sub-list for method output_type
+	27, // [27:29] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_provisionersdk_proto_provisioner_proto_init() }
@@ -2966,6 +3051,18 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 			}
 		}
 		file_provisionersdk_proto_provisioner_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResourceChanges); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_provisionersdk_proto_provisioner_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Agent_Metadata); i {
 			case 0:
 				return &v.state
@@ -2977,7 +3074,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Resource_Metadata); i {
 			case 0:
 				return &v.state
@@ -2989,7 +3086,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Parse_Request); i {
 			case 0:
 				return &v.state
@@ -3001,7 +3098,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Parse_Complete); i {
 			case 0:
 				return &v.state
@@ -3013,7 +3110,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Parse_Response); i {
 			case 0:
 				return &v.state
@@ -3025,7 +3122,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Metadata); i {
 			case 0:
 				return &v.state
@@ -3037,7 +3134,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Config); i {
 			case 0:
 				return &v.state
@@ -3049,7 +3146,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Plan); i {
 			case 0:
 				return &v.state
@@ -3061,7 +3158,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Apply); i {
 			case 0:
 				return &v.state
@@ -3073,7 +3170,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Cancel); i {
 			case 0:
 				return &v.state
@@ -3085,7 +3182,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Request); i {
 			case 0:
 				return &v.state
@@ -3097,7 +3194,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Complete); i {
 			case 0:
 				return &v.state
@@ -3109,7 +3206,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 				return nil
 			}
 		}
-		file_provisionersdk_proto_provisioner_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+		file_provisionersdk_proto_provisioner_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Provision_Response); i {
 			case 0:
 				return &v.state
@@ -3127,16 +3224,16 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 		(*Agent_Token)(nil),
 		(*Agent_InstanceId)(nil),
 	}
-	file_provisionersdk_proto_provisioner_proto_msgTypes[20].OneofWrappers = []interface{}{
+	file_provisionersdk_proto_provisioner_proto_msgTypes[21].OneofWrappers = []interface{}{
 		(*Parse_Response_Log)(nil),
 		(*Parse_Response_Complete)(nil),
 	}
-	file_provisionersdk_proto_provisioner_proto_msgTypes[26].OneofWrappers = []interface{}{
+	file_provisionersdk_proto_provisioner_proto_msgTypes[27].OneofWrappers = []interface{}{
 		(*Provision_Request_Plan)(nil),
 		(*Provision_Request_Apply)(nil),
 		(*Provision_Request_Cancel)(nil),
 	}
-	file_provisionersdk_proto_provisioner_proto_msgTypes[28].OneofWrappers = []interface{}{
+	file_provisionersdk_proto_provisioner_proto_msgTypes[29].OneofWrappers = []interface{}{
 		(*Provision_Response_Log)(nil),
 		(*Provision_Response_Complete)(nil),
 	}
@@ -3146,7 +3243,7 @@ func file_provisionersdk_proto_provisioner_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_provisionersdk_proto_provisioner_proto_rawDesc,
 			NumEnums:      3,
-			NumMessages:   29,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
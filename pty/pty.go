@@ -3,6 +3,7 @@ package pty
 import (
 	"io"
 	"log"
+	"os"
 
 	"github.com/gliderlabs/ssh"
 	"golang.org/x/xerrors"
@@ -133,3 +134,15 @@ func (rw ReadWriter) Read(p []byte) (int, error) {
 func (rw ReadWriter) Write(p []byte) (int, error) {
 	return rw.Writer.Write(p)
 }
+
+// Fd returns the file descriptor of whichever side is backed by a file so
+// the terminal can be put into raw mode. It returns 0 if neither is.
+func (rw ReadWriter) Fd() uintptr {
+	if f, ok := rw.Reader.(*os.File); ok {
+		return f.Fd()
+	}
+	if f, ok := rw.Writer.(*os.File); ok {
+		return f.Fd()
+	}
+	return 0
+}
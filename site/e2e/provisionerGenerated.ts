@@ -248,6 +248,7 @@ export interface Provision_Complete {
   parameters: RichParameter[]
   gitAuthProviders: string[]
   plan: Uint8Array
+  resourceChanges: ResourceChanges | undefined
 }
 
 export interface Provision_Response {
@@ -255,6 +256,13 @@ export interface Provision_Response {
   complete?: Provision_Complete | undefined
 }
 
+/** ResourceChanges counts the resources changed by an apply. */
+export interface ResourceChanges {
+  added: number
+  changed: number
+  destroyed: number
+}
+
 export const Empty = {
   encode(_: Empty, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     return writer
@@ -842,6 +850,12 @@ export const Provision_Complete = {
     if (message.plan.length !== 0) {
       writer.uint32(50).bytes(message.plan)
     }
+    if (message.resourceChanges !== undefined) {
+      ResourceChanges.encode(
+        message.resourceChanges,
+        writer.uint32(58).fork(),
+      ).ldelim()
+    }
     return writer
   },
 }
@@ -864,6 +878,24 @@ export const Provision_Response = {
   },
 }
 
+export const ResourceChanges = {
+  encode(
+    message: ResourceChanges,
+    writer: _m0.Writer = _m0.Writer.create(),
+  ): _m0.Writer {
+    if (message.added !== 0) {
+      writer.uint32(8).int32(message.added)
+    }
+    if (message.changed !== 0) {
+      writer.uint32(16).int32(message.changed)
+    }
+    if (message.destroyed !== 0) {
+      writer.uint32(24).int32(message.destroyed)
+    }
+    return writer
+  },
+}
+
 export interface Provisioner {
   Parse(request: Parse_Request): Observable<Parse_Response>
   Provision(
@@ -751,6 +751,13 @@ type ErrorPageData struct {
 	Description  string
 	RetryEnabled bool
 	DashboardURL string
+	// LogoURL replaces the default Coder logo if set.
+	LogoURL string
+	// SupportURL adds a support link to the page if set.
+	SupportURL string
+	// RequestID is shown as a reference ID so users can report the error and
+	// operators can find the matching log line.
+	RequestID string
 }
 
 // RenderStaticErrorPage renders the static error page. This is used by app
@@ -774,6 +781,37 @@ func RenderStaticErrorPage(rw http.ResponseWriter, r *http.Request, data ErrorPa
 	}
 }
 
+// WriteError writes an error response for the request. Clients that prefer
+// JSON over HTML in their Accept header receive a codersdk.Response, everyone
+// else receives the static error page.
+func WriteError(rw http.ResponseWriter, r *http.Request, data ErrorPageData) {
+	if !prefersJSON(r) {
+		RenderStaticErrorPage(rw, r, data)
+		return
+	}
+
+	httpapi.Write(r.Context(), rw, data.Status, codersdk.Response{
+		Message: data.Title,
+		Detail:  data.Description,
+	})
+}
+
+// prefersJSON returns true if a JSON media type is listed before any HTML
+// media type in the Accept header. Quality values are ignored, and a missing
+// header is treated as a browser request.
+func prefersJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "application/json":
+			return true
+		case "text/html", "application/xhtml+xml":
+			return false
+		}
+	}
+	return false
+}
+
 type binHashCache struct {
 	binFS http.FileSystem
 
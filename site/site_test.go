@@ -658,3 +658,93 @@ func TestRenderStaticErrorPageNoStatus(t *testing.T) {
 	require.Contains(t, bodyStr, "Retry")
 	require.Contains(t, bodyStr, d.DashboardURL)
 }
+
+func TestRenderStaticErrorPageBranding(t *testing.T) {
+	t.Parallel()
+
+	d := site.ErrorPageData{
+		Status:       http.StatusNotFound,
+		Title:        "Application Not Found",
+		Description:  "The application does not exist.",
+		DashboardURL: "https://example.com",
+		LogoURL:      "https://example.com/logo.png",
+		SupportURL:   "https://example.com/support",
+	}
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	site.RenderStaticErrorPage(rw, r, d)
+
+	resp := rw.Result()
+	defer resp.Body.Close()
+	require.Equal(t, d.Status, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	bodyStr := string(body)
+	require.Contains(t, bodyStr, d.LogoURL)
+	require.Contains(t, bodyStr, d.SupportURL)
+	require.NotContains(t, bodyStr, "<svg")
+}
+
+func TestWriteError(t *testing.T) {
+	t.Parallel()
+
+	d := site.ErrorPageData{
+		Status:       http.StatusNotFound,
+		Title:        "Application Not Found",
+		Description:  "The application does not exist.",
+		DashboardURL: "https://example.com",
+	}
+
+	t.Run("HTML", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		site.WriteError(rw, r, d)
+
+		resp := rw.Result()
+		defer resp.Body.Close()
+		require.Equal(t, d.Status, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), d.Title)
+		require.Contains(t, string(body), d.DashboardURL)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "application/json")
+		site.WriteError(rw, r, d)
+
+		resp := rw.Result()
+		defer resp.Body.Close()
+		require.Equal(t, d.Status, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+
+		var sdkResp codersdk.Response
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sdkResp))
+		require.Equal(t, d.Title, sdkResp.Message)
+		require.Equal(t, d.Description, sdkResp.Detail)
+	})
+
+	t.Run("NoAccept", func(t *testing.T) {
+		t.Parallel()
+
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		site.WriteError(rw, r, d)
+
+		resp := rw.Result()
+		defer resp.Body.Close()
+		require.Equal(t, d.Status, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	})
+}
@@ -488,6 +488,14 @@ export interface GitAuthDeviceExchange {
   readonly device_code: string
 }
 
+// From codersdk/gitauth.go
+export interface GitAuthHealth {
+  readonly id: string
+  readonly type: string
+  readonly reachable: boolean
+  readonly error?: string
+}
+
 // From codersdk/gitauth.go
 export interface GitAuthUser {
   readonly login: string
@@ -715,6 +723,7 @@ export interface ProvisionerConfig {
   readonly daemon_poll_jitter: number
   readonly force_cancel_interval: number
   readonly daemon_psk: string
+  readonly terraform_readonly_lockfile: boolean
 }
 
 // From codersdk/provisionerdaemons.go
@@ -1471,6 +1480,11 @@ export interface WorkspaceOptions {
   readonly include_deleted?: boolean
 }
 
+// From codersdk/workspaceproxy.go
+export interface WorkspaceProxiesRequest {
+  readonly q?: string
+}
+
 // From codersdk/workspaceproxy.go
 export interface WorkspaceProxy extends Region {
   readonly derp_enabled: boolean
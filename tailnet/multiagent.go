@@ -12,6 +12,8 @@ import (
 
 type MultiAgentConn interface {
 	UpdateSelf(node *Node) error
+	// SubscribeAgent subscribes to updates for the agent. Subscribing to an
+	// agent that is already subscribed is a no-op.
 	SubscribeAgent(agentID uuid.UUID) error
 	UnsubscribeAgent(agentID uuid.UUID) error
 	NextUpdate(ctx context.Context) ([]*Node, bool)
@@ -30,6 +32,24 @@ type MultiAgent struct {
 	OnUnsubscribe     func(enq Queue, agent uuid.UUID) error
 	OnNodeUpdate      func(id uuid.UUID, node *Node) error
 	OnRemove          func(id uuid.UUID)
+	// UpdateSelfCoalesceWindow, if set, delays self node updates by the
+	// window so that only the latest node of a burst is sent to
+	// OnNodeUpdate. A pending node is sent when the MultiAgent is closed.
+	UpdateSelfCoalesceWindow time.Duration
+
+	// selfMu guards the coalesced self node state. m.mu must be held while
+	// holding selfMu.
+	selfMu sync.Mutex
+	// pendingSelf is the latest self node waiting for the coalesce window
+	// to elapse. selfTimer is non-nil while a send is scheduled.
+	pendingSelf *Node
+	selfTimer   *time.Timer
+	// selfErr is the error from the last coalesced send, returned by the
+	// next call to UpdateSelf.
+	selfErr error
+
+	subscriptionsMu sync.Mutex
+	subscriptions   map[uuid.UUID]struct{}
 
 	closed    bool
 	updates   chan []*Node
@@ -43,6 +63,7 @@ type MultiAgent struct {
 
 func (m *MultiAgent) Init() *MultiAgent {
 	m.updates = make(chan []*Node, 128)
+	m.subscriptions = make(map[uuid.UUID]struct{})
 	m.start = time.Now().Unix()
 	return m
 }
@@ -58,12 +79,70 @@ func (m *MultiAgent) AgentIsLegacy(agentID uuid.UUID) bool {
 var ErrMultiAgentClosed = xerrors.New("multiagent is closed")
 
 func (m *MultiAgent) UpdateSelf(node *Node) error {
+	if m.UpdateSelfCoalesceWindow > 0 {
+		return m.coalesceSelf(node)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return ErrMultiAgentClosed
+	}
+
+	return m.OnNodeUpdate(m.ID, node)
+}
+
+// coalesceSelf records node as the latest self node and schedules it to be
+// sent once the coalesce window elapses.
+func (m *MultiAgent) coalesceSelf(node *Node) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.closed {
 		return ErrMultiAgentClosed
 	}
 
+	m.selfMu.Lock()
+	defer m.selfMu.Unlock()
+	m.pendingSelf = node
+	if m.selfTimer == nil {
+		m.selfTimer = time.AfterFunc(m.UpdateSelfCoalesceWindow, m.sendPendingSelf)
+	}
+	err := m.selfErr
+	m.selfErr = nil
+	return err
+}
+
+// sendPendingSelf is called when the coalesce window elapses. There is no
+// caller to return a send error to, so it's kept for the next UpdateSelf.
+func (m *MultiAgent) sendPendingSelf() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return
+	}
+
+	err := m.flushSelfLocked()
+	if err != nil {
+		m.selfMu.Lock()
+		m.selfErr = err
+		m.selfMu.Unlock()
+	}
+}
+
+// flushSelfLocked sends the pending self node, if any. m.mu must be held so
+// the MultiAgent can't be closed while the node is being sent.
+func (m *MultiAgent) flushSelfLocked() error {
+	m.selfMu.Lock()
+	node := m.pendingSelf
+	m.pendingSelf = nil
+	if m.selfTimer != nil {
+		m.selfTimer.Stop()
+		m.selfTimer = nil
+	}
+	m.selfMu.Unlock()
+	if node == nil {
+		return nil
+	}
 	return m.OnNodeUpdate(m.ID, node)
 }
 
@@ -74,10 +153,17 @@ func (m *MultiAgent) SubscribeAgent(agentID uuid.UUID) error {
 		return ErrMultiAgentClosed
 	}
 
+	m.subscriptionsMu.Lock()
+	defer m.subscriptionsMu.Unlock()
+	if _, ok := m.subscriptions[agentID]; ok {
+		return nil
+	}
+
 	node, err := m.OnSubscribe(m, agentID)
 	if err != nil {
 		return err
 	}
+	m.subscriptions[agentID] = struct{}{}
 
 	if node != nil {
 		return m.enqueueLocked([]*Node{node})
@@ -93,7 +179,14 @@ func (m *MultiAgent) UnsubscribeAgent(agentID uuid.UUID) error {
 		return ErrMultiAgentClosed
 	}
 
-	return m.OnUnsubscribe(m, agentID)
+	m.subscriptionsMu.Lock()
+	defer m.subscriptionsMu.Unlock()
+	err := m.OnUnsubscribe(m, agentID)
+	if err != nil {
+		return err
+	}
+	delete(m.subscriptions, agentID)
+	return nil
 }
 
 func (m *MultiAgent) NextUpdate(ctx context.Context) ([]*Node, bool) {
@@ -148,16 +241,37 @@ func (m *MultiAgent) IsClosed() bool {
 
 func (m *MultiAgent) CoordinatorClose() error {
 	m.mu.Lock()
-	if !m.closed {
-		m.closed = true
-		close(m.updates)
-	}
-	m.mu.Unlock()
+	defer m.mu.Unlock()
+	m.closeLocked()
 	return nil
 }
 
+func (m *MultiAgent) closeLocked() {
+	if m.closed {
+		return
+	}
+	m.closed = true
+	close(m.updates)
+
+	m.selfMu.Lock()
+	defer m.selfMu.Unlock()
+	m.pendingSelf = nil
+	if m.selfTimer != nil {
+		m.selfTimer.Stop()
+		m.selfTimer = nil
+	}
+}
+
+// Close sends any pending self node before closing, and returns the error
+// from sending it.
 func (m *MultiAgent) Close() error {
-	_ = m.CoordinatorClose()
+	m.mu.Lock()
+	var err error
+	if !m.closed {
+		err = m.flushSelfLocked()
+	}
+	m.closeLocked()
+	m.mu.Unlock()
 	m.closeOnce.Do(func() { m.OnRemove(m.ID) })
-	return nil
+	return err
 }
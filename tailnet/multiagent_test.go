@@ -0,0 +1,139 @@
+package tailnet_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/tailnet"
+	"github.com/coder/coder/testutil"
+)
+
+func newCoalescingMultiAgent(window time.Duration, onNodeUpdate func(*tailnet.Node) error) *tailnet.MultiAgent {
+	return (&tailnet.MultiAgent{
+		ID:                uuid.New(),
+		AgentIsLegacyFunc: func(uuid.UUID) bool { return false },
+		OnSubscribe:       func(tailnet.Queue, uuid.UUID) (*tailnet.Node, error) { return nil, nil },
+		OnUnsubscribe:     func(tailnet.Queue, uuid.UUID) error { return nil },
+		OnNodeUpdate: func(_ uuid.UUID, node *tailnet.Node) error {
+			return onNodeUpdate(node)
+		},
+		OnRemove:                 func(uuid.UUID) {},
+		UpdateSelfCoalesceWindow: window,
+	}).Init()
+}
+
+func TestMultiAgent_UpdateSelfCoalesce(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Window", func(t *testing.T) {
+		t.Parallel()
+
+		updates := make(chan *tailnet.Node, 5)
+		ma := newCoalescingMultiAgent(time.Millisecond, func(node *tailnet.Node) error {
+			updates <- node
+			return nil
+		})
+		defer ma.Close()
+
+		require.NoError(t, ma.UpdateSelf(&tailnet.Node{PreferredDERP: 1}))
+		select {
+		case node := <-updates:
+			require.Equal(t, 1, node.PreferredDERP)
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for self node update")
+		}
+	})
+
+	t.Run("FlushOnClose", func(t *testing.T) {
+		t.Parallel()
+
+		updates := make(chan *tailnet.Node, 5)
+		// The window never elapses during the test, so the only send is
+		// the flush from Close.
+		ma := newCoalescingMultiAgent(time.Hour, func(node *tailnet.Node) error {
+			updates <- node
+			return nil
+		})
+
+		for i := 1; i <= 5; i++ {
+			require.NoError(t, ma.UpdateSelf(&tailnet.Node{PreferredDERP: i}))
+		}
+		require.Empty(t, updates)
+
+		require.NoError(t, ma.Close())
+		require.Len(t, updates, 1)
+		require.Equal(t, 5, (<-updates).PreferredDERP)
+		require.ErrorIs(t, ma.UpdateSelf(&tailnet.Node{}), tailnet.ErrMultiAgentClosed)
+	})
+
+	t.Run("SendError", func(t *testing.T) {
+		t.Parallel()
+
+		sendErr := xerrors.New("send failed")
+		sent := make(chan struct{}, 1)
+		ma := newCoalescingMultiAgent(time.Millisecond, func(*tailnet.Node) error {
+			select {
+			case sent <- struct{}{}:
+			default:
+			}
+			return sendErr
+		})
+		defer ma.Close()
+
+		require.NoError(t, ma.UpdateSelf(&tailnet.Node{PreferredDERP: 1}))
+		select {
+		case <-sent:
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for self node update")
+		}
+		// The error from the coalesced send is returned by the next update.
+		require.Eventually(t, func() bool {
+			return xerrors.Is(ma.UpdateSelf(&tailnet.Node{PreferredDERP: 2}), sendErr)
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+}
+
+func TestMultiAgent_SubscribeAgentIdempotent(t *testing.T) {
+	t.Parallel()
+
+	var subscribes int
+	agentID := uuid.New()
+	ma := (&tailnet.MultiAgent{
+		ID:                uuid.New(),
+		AgentIsLegacyFunc: func(uuid.UUID) bool { return false },
+		OnSubscribe: func(tailnet.Queue, uuid.UUID) (*tailnet.Node, error) {
+			subscribes++
+			return &tailnet.Node{PreferredDERP: subscribes}, nil
+		},
+		OnUnsubscribe: func(tailnet.Queue, uuid.UUID) error { return nil },
+		OnNodeUpdate:  func(uuid.UUID, *tailnet.Node) error { return nil },
+		OnRemove:      func(uuid.UUID) {},
+	}).Init()
+	defer ma.Close()
+
+	require.NoError(t, ma.SubscribeAgent(agentID))
+	require.NoError(t, ma.SubscribeAgent(agentID))
+	require.Equal(t, 1, subscribes)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	nodes, ok := ma.NextUpdate(ctx)
+	require.True(t, ok)
+	require.Len(t, nodes, 1)
+	require.Equal(t, 1, nodes[0].PreferredDERP)
+
+	// The second subscribe must not have enqueued anything.
+	emptyCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, ok = ma.NextUpdate(emptyCtx)
+	require.False(t, ok)
+
+	// Subscribing again after unsubscribing is effective.
+	require.NoError(t, ma.UnsubscribeAgent(agentID))
+	require.NoError(t, ma.SubscribeAgent(agentID))
+	require.Equal(t, 2, subscribes)
+}
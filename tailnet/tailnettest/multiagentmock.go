@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/coder/coder/tailnet/tailnettest (interfaces: MultiAgentConn)
+// Source: github.com/coder/coder/tailnet (interfaces: MultiAgentConn)
 
 // Package tailnettest is a generated GoMock package.
 package tailnettest
@@ -64,20 +64,6 @@ func (mr *MockMultiAgentConnMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockMultiAgentConn)(nil).Close))
 }
 
-// Enqueue mocks base method.
-func (m *MockMultiAgentConn) Enqueue(arg0 []*tailnet.Node) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Enqueue", arg0)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Enqueue indicates an expected call of Enqueue.
-func (mr *MockMultiAgentConnMockRecorder) Enqueue(arg0 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockMultiAgentConn)(nil).Enqueue), arg0)
-}
-
 // IsClosed mocks base method.
 func (m *MockMultiAgentConn) IsClosed() bool {
 	m.ctrl.T.Helper()